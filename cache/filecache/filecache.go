@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -291,6 +292,43 @@ func (c *Cache) getOrRemove(id string) hugio.ReadSeekCloser {
 	return f
 }
 
+// Peek gets the file content with the given id from the cache, nil if none
+// found or expired. Unlike GetBytes, the id is not marked as used and
+// expired items are left for the pruner.
+func (c *Cache) Peek(id string) ([]byte, error) {
+	id = cleanID(id)
+
+	if c.maxAge == 0 {
+		// No caching.
+		return nil, nil
+	}
+
+	fi, err := c.Fs.Stat(id)
+	if err != nil {
+		return nil, nil
+	}
+	if c.isExpired(fi.ModTime()) {
+		return nil, nil
+	}
+
+	return afero.ReadFile(c.Fs, id)
+}
+
+// UsedIDs returns the sorted ids of the items used in this cache since it
+// was created.
+func (c *Cache) UsedIDs() []string {
+	c.nlocker.seenMu.RLock()
+	defer c.nlocker.seenMu.RUnlock()
+
+	ids := make([]string, 0, len(c.nlocker.seen))
+	for id := range c.nlocker.seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
 func (c *Cache) isExpired(modTime time.Time) bool {
 	if c.maxAge < 0 {
 		return false
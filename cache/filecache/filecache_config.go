@@ -43,12 +43,14 @@ var defaultCacheConfig = Config{
 }
 
 const (
-	cacheKeyGetJSON     = "getjson"
-	cacheKeyGetCSV      = "getcsv"
-	cacheKeyImages      = "images"
-	cacheKeyAssets      = "assets"
-	cacheKeyModules     = "modules"
-	cacheKeyGetResource = "getresource"
+	cacheKeyGetJSON       = "getjson"
+	cacheKeyGetCSV        = "getcsv"
+	cacheKeyImages        = "images"
+	cacheKeyAssets        = "assets"
+	cacheKeyModules       = "modules"
+	cacheKeyGetResource   = "getresource"
+	cacheKeyMisc          = "misc"
+	cacheKeyBuildManifest = "buildmanifest"
 )
 
 type Configs map[string]Config
@@ -76,6 +78,14 @@ var defaultCacheConfigs = Configs{
 		MaxAge: -1, // Never expire
 		Dir:    cacheDirProject,
 	},
+	cacheKeyMisc: Config{
+		MaxAge: -1,
+		Dir:    cacheDirProject,
+	},
+	cacheKeyBuildManifest: Config{
+		MaxAge: -1,
+		Dir:    cacheDirProject,
+	},
 }
 
 type Config struct {
@@ -122,6 +132,18 @@ func (f Caches) GetResourceCache() *Cache {
 	return f[cacheKeyGetResource]
 }
 
+// MiscCache gets the file cache for miscellaneous build artifacts, e.g. the
+// output of transform.ToMath.
+func (f Caches) MiscCache() *Cache {
+	return f[cacheKeyMisc]
+}
+
+// BuildManifestCache gets the file cache for the build manifests used in
+// incremental builds.
+func (f Caches) BuildManifestCache() *Cache {
+	return f[cacheKeyBuildManifest]
+}
+
 func DecodeConfig(fs afero.Fs, cfg config.Provider) (Configs, error) {
 	c := make(Configs)
 	valid := make(map[string]bool)
@@ -293,6 +293,39 @@ func TestFileCacheReadOrCreateErrorInRead(t *testing.T) {
 	c.Assert(err, qt.Equals, ErrFatal)
 }
 
+func TestFileCachePeek(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	cache := NewCache(fs, 100*time.Hour, "")
+
+	_, _, err := cache.GetOrCreateBytes("b", func() ([]byte, error) { return []byte("vb"), nil })
+	c.Assert(err, qt.IsNil)
+	_, _, err = cache.GetOrCreateBytes("a", func() ([]byte, error) { return []byte("va"), nil })
+	c.Assert(err, qt.IsNil)
+	c.Assert(cache.UsedIDs(), qt.DeepEquals, []string{"a", "b"})
+
+	// A new cache on the same filesystem, e.g. in the next build.
+	cache = NewCache(fs, 100*time.Hour, "")
+	b, err := cache.Peek("a")
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "va")
+	b, err = cache.Peek("c")
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.IsNil)
+	c.Assert(cache.UsedIDs(), qt.HasLen, 0)
+
+	c.Assert(fs.Chtimes("a", time.Now().Add(-101*time.Hour), time.Now().Add(-101*time.Hour)), qt.IsNil)
+	b, err = cache.Peek("a")
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.IsNil)
+
+	b, err = NewCache(fs, 0, "").Peek("b")
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.IsNil)
+}
+
 func TestCleanID(t *testing.T) {
 	c := qt.New(t)
 	c.Assert(cleanID(filepath.FromSlash("/a/b//c.txt")), qt.Equals, filepath.FromSlash("a/b/c.txt"))
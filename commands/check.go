@@ -0,0 +1,133 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/gohugoio/hugo/hugolib"
+	"github.com/gohugoio/hugo/linkcheck"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var _ cmder = (*checkCmd)(nil)
+
+type checkCmd struct {
+	*baseBuilderCmd
+
+	external bool
+	noBuild  bool
+}
+
+func (cc *checkCmd) buildSites() (*hugolib.HugoSites, error) {
+	cfgInit := func(c *commandeer) error {
+		if !cc.noBuild {
+			// Check the site as built now, not what's in publishDir.
+			c.Set("renderToMemory", true)
+		}
+		return nil
+	}
+
+	c, err := initializeConfig(true, true, false, &cc.hugoBuilderCommon, cc, cfgInit)
+	if err != nil {
+		return nil, err
+	}
+
+	sites, err := hugolib.NewHugoSites(*c.DepsCfg)
+	if err != nil {
+		return nil, newSystemError("Error creating sites", err)
+	}
+
+	if cc.noBuild {
+		return sites, nil
+	}
+
+	if err := sites.Build(hugolib.BuildCfg{}); err != nil {
+		return nil, newSystemError("Error building sites", err)
+	}
+
+	return sites, nil
+}
+
+func (cc *checkCmd) checkLinks() error {
+	sites, err := cc.buildSites()
+	if err != nil {
+		return err
+	}
+
+	opts := linkcheck.Options{
+		External:      cc.external,
+		AllowExternal: sites.ExecHelper.Sec().CheckAllowedHTTPURL,
+	}
+	for _, s := range sites.Sites {
+		opts.BaseURLs = append(opts.BaseURLs, string(s.Info.BaseURL()))
+	}
+
+	report, err := linkcheck.Check(sites.BaseFs.PublishFs, opts)
+	if err != nil {
+		return newSystemError("Error checking links", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return newSystemError("Error writing report to stdout", err)
+	}
+
+	if len(report.Broken) > 0 {
+		return errors.Errorf("found %d broken link(s)", len(report.Broken))
+	}
+
+	return nil
+}
+
+func (b *commandsBuilder) newCheckCmd() *checkCmd {
+	cc := &checkCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the generated site",
+		Long: `Check the generated site.
+
+Check requires a subcommand, e.g. ` + "`hugo check links`.",
+		RunE: nil,
+	}
+
+	linksCmd := &cobra.Command{
+		Use:   "links",
+		Short: "Check the links in the generated site",
+		Long: `Check the links in the generated site.
+
+The site is built in memory and every link in the generated HTML files is checked.
+Links to files that do not exist and links to anchors that are not found in the
+target page are reported as broken. Use --external to also request the external
+links allowed by security.http.urls.
+
+The report is written to stdout as JSON, and the command fails if there are
+broken links.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cc.checkLinks()
+		},
+	}
+	linksCmd.Flags().BoolVar(&cc.external, "external", false, "also check external links")
+	linksCmd.Flags().BoolVar(&cc.noBuild, "noBuild", false, "check the files in publishDir instead of building the site")
+
+	cmd.AddCommand(linksCmd)
+
+	cc.baseBuilderCmd = b.newBuilderBasicCmd(cmd)
+
+	return cc
+}
@@ -93,6 +93,7 @@ type commandeer struct {
 	languages           langs.Languages
 	doLiveReload        bool
 	fastRenderMode      bool
+	renderOnDemand      bool
 	showErrorInBrowser  bool
 	wasError            bool
 
@@ -345,6 +346,7 @@ func (c *commandeer) loadConfig() error {
 	// Set some commonly used flags
 	c.doLiveReload = c.running && !c.Cfg.GetBool("disableLiveReload")
 	c.fastRenderMode = c.doLiveReload && !c.Cfg.GetBool("disableFastRender")
+	c.renderOnDemand = c.running && c.Cfg.GetBool("renderOnDemand")
 	c.showErrorInBrowser = c.doLiveReload && !c.Cfg.GetBool("disableBrowserError")
 
 	// This is potentially double work, but we need to do this one more time now
@@ -52,6 +52,7 @@ func (b *commandsBuilder) addAll() *commandsBuilder {
 		b.newConvertCmd(),
 		b.newNewCmd(),
 		b.newListCmd(),
+		b.newCheckCmd(),
 		newImportCmd(),
 		newGenCmd(),
 		createReleaser(),
@@ -289,6 +289,7 @@ func TestCommandsExecute(t *testing.T) {
 		{[]string{"list", "drafts"}, []string{sourceFlag}, ""},
 		{[]string{"list", "expired"}, []string{sourceFlag}, ""},
 		{[]string{"list", "future"}, []string{sourceFlag}, ""},
+		{[]string{"check", "links"}, []string{sourceFlag}, ""},
 		{[]string{"new", "new-page.md"}, []string{sourceFlag}, ""},
 		{[]string{"new", "site", filepath.Join(dirOut, "new-site")}, nil, ""},
 		{[]string{"unknowncommand"}, nil, "unknown command"},
@@ -728,7 +728,7 @@ func (c *commandeer) getDirList() ([]string, error) {
 }
 
 func (c *commandeer) buildSites(noBuildLock bool) (err error) {
-	return c.hugo().Build(hugolib.BuildCfg{NoBuildLock: noBuildLock})
+	return c.hugo().Build(hugolib.BuildCfg{NoBuildLock: noBuildLock, RenderOnDemand: c.renderOnDemand})
 }
 
 func (c *commandeer) handleBuildErr(err error, msg string) {
@@ -743,6 +743,10 @@ func (c *commandeer) handleBuildErr(err error, msg string) {
 
 func (c *commandeer) rebuildSites(events []fsnotify.Event) error {
 	c.buildErr = nil
+	if c.renderOnDemand {
+		// The changed pages are rendered again when requested.
+		return c.hugo().Build(hugolib.BuildCfg{NoBuildLock: true, RenderOnDemand: true, ErrRecovery: c.wasError}, events...)
+	}
 	visited := c.visitedURLs.PeekAllSet()
 	if c.fastRenderMode {
 		// Make sure we always render the home pages
@@ -769,7 +773,17 @@ func (c *commandeer) partialReRender(urls ...string) error {
 	}
 
 	// Note: We do not set NoBuildLock as the file lock is not acquired at this stage.
-	return c.hugo().Build(hugolib.BuildCfg{NoBuildLock: false, RecentlyVisited: visited, PartialReRender: true, ErrRecovery: c.wasError})
+	return c.hugo().Build(hugolib.BuildCfg{NoBuildLock: false, RecentlyVisited: visited, PartialReRender: true, RenderOnDemand: c.renderOnDemand, ErrRecovery: c.wasError})
+}
+
+// renderURLOnDemand renders the page published to requestURI in render on
+// demand mode, unless already rendered and up to date.
+func (c *commandeer) renderURLOnDemand(requestURI string) error {
+	u := strings.TrimSuffix(requestURI, "index.html")
+	if c.hugo().IsRenderedOnDemand(u) {
+		return nil
+	}
+	return c.partialReRender(u)
 }
 
 func (c *commandeer) fullRebuild(changeType string) {
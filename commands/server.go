@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
@@ -58,7 +59,12 @@ type serverCmd struct {
 	serverWatch       bool
 	noHTTPCache       bool
 
+	tls         bool
+	tlsCertFile string
+	tlsKeyFile  string
+
 	disableFastRender   bool
+	renderOnDemand      bool
 	disableBrowserError bool
 
 	*baseBuilderCmd
@@ -86,7 +92,18 @@ preferring to store it in memory.
 By default hugo will also watch your files for any changes you make and
 automatically rebuild the site. It will then live reload any open browser pages
 and push the latest content to them. As most Hugo sites are built in a fraction
-of a second, you will be able to save and see your changes nearly instantly.`,
+of a second, you will be able to save and see your changes nearly instantly.
+
+Use --renderOnDemand on big sites to only render the pages you request.
+The content is still read and processed on every build, but a page is not
+rendered until requested, and then again only if something changed.
+A content change marks the changed pages and the list pages as stale, any
+other change marks all pages as stale.
+
+Use --tls to serve over HTTPS and HTTP/2. Hugo creates a self-signed
+certificate for localhost in the cache dir, which browsers will warn about
+once. Use --tlsCertFile and --tlsKeyFile to serve with a certificate trusted
+by your browser, e.g. one created with mkcert.`,
 		RunE: cc.server,
 	})
 
@@ -95,11 +112,15 @@ of a second, you will be able to save and see your changes nearly instantly.`,
 	cc.cmd.Flags().StringVarP(&cc.serverInterface, "bind", "", "127.0.0.1", "interface to which the server will bind")
 	cc.cmd.Flags().BoolVarP(&cc.serverWatch, "watch", "w", true, "watch filesystem for changes and recreate as needed")
 	cc.cmd.Flags().BoolVar(&cc.noHTTPCache, "noHTTPCache", false, "prevent HTTP caching")
+	cc.cmd.Flags().BoolVar(&cc.tls, "tls", false, "serve over HTTPS and HTTP/2 with a self-signed certificate for localhost, see --tlsCertFile")
+	cc.cmd.Flags().StringVar(&cc.tlsCertFile, "tlsCertFile", "", "path to a TLS certificate file to serve over HTTPS with, implies --tls")
+	cc.cmd.Flags().StringVar(&cc.tlsKeyFile, "tlsKeyFile", "", "path to the TLS key file for --tlsCertFile")
 	cc.cmd.Flags().BoolVarP(&cc.serverAppend, "appendPort", "", true, "append port to baseURL")
 	cc.cmd.Flags().BoolVar(&cc.disableLiveReload, "disableLiveReload", false, "watch without enabling live browser reload on rebuild")
 	cc.cmd.Flags().BoolVar(&cc.navigateToChanged, "navigateToChanged", false, "navigate to changed content file on live browser reload")
 	cc.cmd.Flags().BoolVar(&cc.renderToDisk, "renderToDisk", false, "render to Destination path (default is render to memory & serve from there)")
 	cc.cmd.Flags().BoolVar(&cc.disableFastRender, "disableFastRender", false, "enables full re-renders on changes")
+	cc.cmd.Flags().BoolVar(&cc.renderOnDemand, "renderOnDemand", false, "render pages when requested instead of on every build, for faster startup of big sites")
 	cc.cmd.Flags().BoolVar(&cc.disableBrowserError, "disableBrowserError", false, "do not show build errors in the browser")
 
 	cc.cmd.Flags().String("memstats", "", "log memory usage to this file")
@@ -137,6 +158,10 @@ func (sc *serverCmd) server(cmd *cobra.Command, args []string) error {
 		sc.renderToDisk = true
 	}
 
+	if (sc.tlsCertFile == "") != (sc.tlsKeyFile == "") {
+		return newSystemError("--tlsCertFile and --tlsKeyFile must be set together")
+	}
+
 	var serverCfgInit sync.Once
 
 	cfgInit := func(c *commandeer) error {
@@ -150,6 +175,9 @@ func (sc *serverCmd) server(cmd *cobra.Command, args []string) error {
 		if cmd.Flags().Changed("disableFastRender") {
 			c.Set("disableFastRender", sc.disableFastRender)
 		}
+		if cmd.Flags().Changed("renderOnDemand") {
+			c.Set("renderOnDemand", sc.renderOnDemand)
+		}
 		if cmd.Flags().Changed("disableBrowserError") {
 			c.Set("disableBrowserError", sc.disableBrowserError)
 		}
@@ -314,6 +342,25 @@ func (f *fileServer) rewriteRequest(r *http.Request, toPath string) *http.Reques
 	return r2
 }
 
+// serveNotFound serves the file name in fs, e.g. the 404 page of a
+// language, with the status code 404.
+func (f *fileServer) serveNotFound(w http.ResponseWriter, r *http.Request, fs http.FileSystem, name string) {
+	file, err := fs.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	if ct := mime.TypeByExtension(filepath.Ext(name)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.WriteHeader(http.StatusNotFound)
+	if _, err := io.Copy(w, file); err != nil {
+		f.c.logger.Errorln(err)
+	}
+}
+
 func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, error) {
 	baseURL := f.baseURLs[i]
 	root := f.roots[i]
@@ -340,8 +387,12 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 	httpFs := afero.NewHttpFs(f.c.destinationFs)
 	fs := filesOnlyFs{httpFs.Dir(absPublishDir)}
 
-	if i == 0 && f.c.fastRenderMode {
-		jww.FEEDBACK.Println("Running in Fast Render Mode. For full rebuilds on change: hugo server --disableFastRender")
+	if i == 0 {
+		if f.c.renderOnDemand {
+			jww.FEEDBACK.Println("Rendering pages on demand, when requested.")
+		} else if f.c.fastRenderMode {
+			jww.FEEDBACK.Println("Running in Fast Render Mode. For full rebuilds on change: hugo server --disableFastRender")
+		}
 	}
 
 	// We're only interested in the path
@@ -387,12 +438,35 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 				w.Header().Set(header.Key, header.Value)
 			}
 
-			if redirect := f.c.serverConfig.MatchRedirect(requestURI); !redirect.IsZero() {
+			isPageURL := strings.HasSuffix(requestURI, "/") || strings.HasSuffix(requestURI, "html") || strings.HasSuffix(requestURI, "htm")
+
+			if f.c.renderOnDemand && f.c.buildErr == nil && (isPageURL || f.c.hugo().HasOutputFormatSuffix(requestURI)) {
+				// This needs to be done before the redirects below, which
+				// depend on what is published.
+				// Any output format may be requested, e.g. /posts/index.xml.
+				if err := f.c.renderURLOnDemand(requestURI); err != nil {
+					f.c.handleBuildErr(err, fmt.Sprintf("Failed to render %q", requestURI))
+					if f.c.showErrorInBrowser {
+						http.Redirect(w, r, requestURI, http.StatusMovedPermanently)
+						return
+					}
+				}
+			}
+
+			redirect := f.c.serverConfig.MatchRedirect(requestURI)
+			if redirect.IsZero() {
+				// Fall back to the redirects published in the redirect maps.
+				if sr := f.c.hugo().RedirectMap(root).Match(requestURI); !sr.IsZero() {
+					redirect = config.Redirect{From: sr.From, To: strings.TrimSuffix(sr.To, "index.html"), Status: sr.Status}
+				}
+			}
+
+			if !redirect.IsZero() {
 				doRedirect := true
 				// This matches Netlify's behaviour and is needed for SPA behaviour.
 				// See https://docs.netlify.com/routing/redirects/rewrites-proxies/
 				if !redirect.Force {
-					path := filepath.Clean(strings.TrimPrefix(requestURI, u.Path))
+					path := filepath.Join(root, filepath.Clean(strings.TrimPrefix(requestURI, u.Path)))
 					fi, err := f.c.hugo().BaseFs.PublishFs.Stat(path)
 					if err == nil {
 						if fi.IsDir() {
@@ -407,12 +481,16 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 				}
 
 				if doRedirect {
-					if redirect.Status == 200 {
+					switch redirect.Status {
+					case http.StatusOK:
 						if r2 := f.rewriteRequest(r, strings.TrimPrefix(redirect.To, u.Path)); r2 != nil {
 							requestURI = redirect.To
 							r = r2
 						}
-					} else {
+					case http.StatusNotFound:
+						f.serveNotFound(w, r, fs, "/"+strings.TrimPrefix(strings.TrimPrefix(redirect.To, u.Path), "/"))
+						return
+					default:
 						w.Header().Set("Content-Type", "")
 						http.Redirect(w, r, redirect.To, redirect.Status)
 						return
@@ -421,8 +499,8 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 
 			}
 
-			if f.c.fastRenderMode && f.c.buildErr == nil {
-				if strings.HasSuffix(requestURI, "/") || strings.HasSuffix(requestURI, "html") || strings.HasSuffix(requestURI, "htm") {
+			if f.c.fastRenderMode && !f.c.renderOnDemand && f.c.buildErr == nil {
+				if isPageURL {
 					if !f.c.visitedURLs.Contains(requestURI) {
 						// If not already on stack, re-render that single page.
 						if err := f.c.partialReRender(requestURI); err != nil {
@@ -504,6 +582,14 @@ func (c *commandeer) serve(s *serverCmd) error {
 		livereload.Initialize()
 	}
 
+	var certFile, keyFile string
+	if s.useTLS() {
+		certFile, keyFile, err = s.tlsFiles(c.Cfg.GetString("cacheDir"))
+		if err != nil {
+			return err
+		}
+	}
+
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
@@ -521,7 +607,12 @@ func (c *commandeer) serve(s *serverCmd) error {
 		}
 		jww.FEEDBACK.Printf("Web Server is available at %s (bind address %s)\n", serverURL, s.serverInterface)
 		go func() {
-			err = http.ListenAndServe(endpoint, mu)
+			if certFile != "" {
+				// HTTP/2 is enabled by default over TLS.
+				err = http.ListenAndServeTLS(endpoint, certFile, keyFile, mu)
+			} else {
+				err = http.ListenAndServe(endpoint, mu)
+			}
 			if err != nil {
 				c.logger.Errorf("Error: %s\n", err.Error())
 				os.Exit(1)
@@ -582,6 +673,10 @@ func (sc *serverCmd) fixURL(cfg config.Provider, s string, port int) (string, er
 		u.Host = "localhost"
 	}
 
+	if sc.useTLS() && (u.Scheme == "http" || useLocalhost) {
+		u.Scheme = "https"
+	}
+
 	if sc.serverAppend {
 		if strings.Contains(u.Host, ":") {
 			u.Host, _, err = net.SplitHostPort(u.Host)
@@ -54,7 +54,52 @@ linenos='table'
 	c.Assert(err.Error(), qt.Contains, "cannot parse 'Highlight.LineNos' as bool:")
 }
 
+func TestServerHeadersAndRedirects(t *testing.T) {
+	c := qt.New(t)
+
+	config := `
+baseURL = "https://example.org"
+title = "Hugo Commands"
+
+[[server.headers]]
+for = "/**"
+[server.headers.values]
+Content-Security-Policy = "default-src 'self'"
+
+[[server.redirects]]
+from = "/app/**"
+to = "/p1/"
+status = 200
+
+[[server.redirects]]
+from = "/**"
+to = "/p1/index.html"
+status = 404
+`
+
+	resp, content, err := runServerTest(c, config, "/app/some/route", "/missing/")
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(resp[0].StatusCode, qt.Equals, http.StatusOK)
+	c.Assert(resp[0].Header.Get("Content-Security-Policy"), qt.Equals, "default-src 'self'")
+	c.Assert(content[0], qt.Contains, "Single: P1")
+
+	c.Assert(resp[1].StatusCode, qt.Equals, http.StatusNotFound)
+	c.Assert(resp[1].Header.Get("Content-Type"), qt.Contains, "text/html")
+	c.Assert(content[1], qt.Contains, "Single: P1")
+}
+
 func runServerTestAndGetHome(c *qt.C, config string) (string, error) {
+	_, content, err := runServerTest(c, config, "/")
+	if err != nil {
+		return "", err
+	}
+	return content[0], nil
+}
+
+// runServerTest starts the server with the given config and returns the
+// responses and their content for the given paths.
+func runServerTest(c *qt.C, config string, paths ...string) ([]*http.Response, []string, error) {
 	dir, clean, err := createSimpleTestSite(c, testSiteConfig{configTOML: config})
 	defer clean()
 	c.Assert(err, qt.IsNil)
@@ -88,18 +133,26 @@ func runServerTestAndGetHome(c *qt.C, config string) (string, error) {
 	// But for now, let us sleep and pray!
 	case <-time.After(2 * time.Second):
 	case err := <-errors:
-		return "", err
+		return nil, nil, err
 	}
 
-	resp, err := http.Get("http://localhost:1331/")
-	c.Assert(err, qt.IsNil)
-	defer resp.Body.Close()
-	homeContent := helpers.ReaderToString(resp.Body)
+	var (
+		responses []*http.Response
+		contents  []string
+	)
+
+	for _, p := range paths {
+		resp, err := http.Get("http://localhost:1331" + p)
+		c.Assert(err, qt.IsNil)
+		responses = append(responses, resp)
+		contents = append(contents, helpers.ReaderToString(resp.Body))
+		resp.Body.Close()
+	}
 
 	// Stop the server.
 	stop <- true
 
-	return homeContent, nil
+	return responses, contents, nil
 }
 
 func TestFixURL(t *testing.T) {
@@ -0,0 +1,125 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	localhostCertFilename = "localhost.pem"
+	localhostKeyFilename  = "localhost-key.pem"
+
+	// How long a generated certificate is valid.
+	localhostCertValidity = 365 * 24 * time.Hour
+)
+
+func (sc *serverCmd) useTLS() bool {
+	return sc.tls || sc.tlsCertFile != ""
+}
+
+// tlsFiles returns the certificate and key files to serve HTTPS with. If
+// not set with --tlsCertFile and --tlsKeyFile, a self-signed certificate
+// for localhost is created in the Hugo cache dir.
+func (sc *serverCmd) tlsFiles(cacheDir string) (string, string, error) {
+	if sc.tlsCertFile != "" {
+		return sc.tlsCertFile, sc.tlsKeyFile, nil
+	}
+	return createLocalhostCertificate(hugofs.Os, filepath.Join(cacheDir, "hugo_tls"), time.Now())
+}
+
+// createLocalhostCertificate creates a self-signed certificate for
+// localhost in dir, unless a certificate that is valid for at least
+// another day exists, and returns the paths to the certificate and key files.
+func createLocalhostCertificate(fs afero.Fs, dir string, now time.Time) (string, string, error) {
+	certFile := filepath.Join(dir, localhostCertFilename)
+	keyFile := filepath.Join(dir, localhostKeyFilename)
+
+	if cert, err := readCertificate(fs, certFile); err == nil && cert.NotAfter.After(now.Add(24*time.Hour)) {
+		if exists, _ := afero.Exists(fs, keyFile); exists {
+			return certFile, keyFile, nil
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", "", err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{"Hugo development server"}, CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(localhostCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to create certificate")
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := fs.MkdirAll(dir, 0777); err != nil {
+		return "", "", err
+	}
+
+	if err := afero.WriteFile(fs, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644); err != nil {
+		return "", "", err
+	}
+
+	if err := afero.WriteFile(fs, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return "", "", err
+	}
+
+	return certFile, keyFile, nil
+}
+
+func readCertificate(fs afero.Fs, filename string) (*x509.Certificate, error) {
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.Errorf("no certificate found in %q", filename)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/config"
+	"github.com/spf13/afero"
+)
+
+func TestCreateLocalhostCertificate(t *testing.T) {
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	now := time.Now()
+
+	certFile, keyFile, err := createLocalhostCertificate(fs, "cache/hugo_tls", now)
+	c.Assert(err, qt.IsNil)
+
+	certPEM, err := afero.ReadFile(fs, certFile)
+	c.Assert(err, qt.IsNil)
+	keyPEM, err := afero.ReadFile(fs, keyFile)
+	c.Assert(err, qt.IsNil)
+	_, err = tls.X509KeyPair(certPEM, keyPEM)
+	c.Assert(err, qt.IsNil)
+
+	cert, err := readCertificate(fs, certFile)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cert.VerifyHostname("localhost"), qt.IsNil)
+	c.Assert(cert.VerifyHostname("127.0.0.1"), qt.IsNil)
+
+	// The certificate is cached.
+	_, _, err = createLocalhostCertificate(fs, "cache/hugo_tls", now.Add(time.Hour))
+	c.Assert(err, qt.IsNil)
+	certPEM2, _ := afero.ReadFile(fs, certFile)
+	c.Assert(string(certPEM2), qt.Equals, string(certPEM))
+
+	// Unless it is about to expire.
+	_, _, err = createLocalhostCertificate(fs, "cache/hugo_tls", now.Add(localhostCertValidity))
+	c.Assert(err, qt.IsNil)
+	certPEM3, _ := afero.ReadFile(fs, certFile)
+	c.Assert(string(certPEM3), qt.Not(qt.Equals), string(certPEM))
+}
+
+func TestFixURLTLS(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		cliBaseURL string
+		cfgBaseURL string
+		expect     string
+	}{
+		{"", "https://foo.com", "https://localhost:1313/"},
+		{"", "http://foo.com/bar", "https://localhost:1313/bar/"},
+		{"", "foo.com", "https://localhost:1313/"},
+		{"http://foo.com", "http://foo.com", "https://foo.com:1313/"},
+	} {
+		b := newCommandsBuilder()
+		s := b.newServerCmd()
+		s.tls = true
+		s.serverAppend = true
+		v := config.New()
+		v.Set("baseURL", test.cfgBaseURL)
+		result, err := s.fixURL(v, test.cliBaseURL, 1313)
+		c.Assert(err, qt.IsNil)
+		c.Assert(result, qt.Equals, test.expect)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer splits text into search terms. It is used by the search index
+// and by the related content text indices.
+// Only English is stemmed, other languages are tokenized as written.
+// Note that the query runtime in searchindex/search.js must tokenize queries
+// the same way.
+type Tokenizer struct {
+	stopWords map[string]bool
+	stemmer   string
+	stem      func(string) string
+}
+
+// NewTokenizer creates a tokenizer for the given language code, e.g. "en" or
+// "en-us". If stopWords is nil, the built-in stop words for the language are used.
+func NewTokenizer(lang string, stopWords []string) *Tokenizer {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i != -1 {
+		lang = lang[:i]
+	}
+
+	if stopWords == nil {
+		stopWords = strings.Fields(defaultStopWords[lang])
+	}
+
+	t := &Tokenizer{
+		stopWords: make(map[string]bool),
+		stem:      func(s string) string { return s },
+	}
+	for _, w := range stopWords {
+		t.stopWords[strings.ToLower(w)] = true
+	}
+
+	if lang == "en" {
+		t.stemmer = "en"
+		t.stem = stemEnglish
+	}
+
+	return t
+}
+
+// Tokens returns the search terms in s.
+func (t *Tokenizer) Tokens(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	tokens := words[:0]
+	for _, w := range words {
+		if utf8.RuneCountInString(w) < 2 || t.stopWords[w] {
+			continue
+		}
+		tokens = append(tokens, t.stem(w))
+	}
+
+	return tokens
+}
+
+// Stemmer returns the name of the stemmer used, empty if none.
+func (t *Tokenizer) Stemmer() string {
+	return t.stemmer
+}
+
+// StopWords returns the sorted stop words.
+func (t *Tokenizer) StopWords() []string {
+	words := make([]string, 0, len(t.stopWords))
+	for w := range t.stopWords {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// stemEnglish is a light stemmer removing the most common English suffixes,
+// e.g. "hosting", "hosted" and "hosts" all become "host".
+func stemEnglish(w string) string {
+	n := len(w)
+	if n <= 3 {
+		return w
+	}
+
+	switch {
+	case strings.HasSuffix(w, "ies") && n > 4:
+		return w[:n-3] + "y"
+	case strings.HasSuffix(w, "sses"):
+		return w[:n-2]
+	case strings.HasSuffix(w, "ing") && n > 5:
+		return undouble(w[:n-3])
+	case strings.HasSuffix(w, "ed") && n > 4:
+		return undouble(w[:n-2])
+	case strings.HasSuffix(w, "ly") && n > 4:
+		return w[:n-2]
+	case strings.HasSuffix(w, "es") && hasAnySuffix(w[:n-2], "s", "x", "z", "ch", "sh"):
+		return w[:n-2]
+	case strings.HasSuffix(w, "s") && !hasAnySuffix(w, "ss", "us", "is"):
+		return w[:n-1]
+	}
+
+	return w
+}
+
+// undouble removes the last letter of e.g. "runn" and "stopp".
+func undouble(w string) string {
+	n := len(w)
+	if n >= 2 && w[n-1] == w[n-2] && strings.IndexByte("bdfgmnprt", w[n-1]) != -1 {
+		return w[:n-1]
+	}
+	return w
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultStopWords holds the built-in stop words per language code.
+var defaultStopWords = map[string]string{
+	"en": `about above after again against all am an and any are as at be because
+been before being below between both but by can could did do does doing down
+during each few for from further had has have having he her here hers him his
+how if in into is it its itself just me more most my no nor not now of off on
+once only or other our ours out over own same she should so some such than that
+the their theirs them then there these they this those through to too under
+until up very was we were what when where which while who whom why will with
+would you your yours`,
+	"de": `aber alle als also am an auch auf aus bei bin bis bist da damit dann das
+dass dem den der des die dies diese dieser doch du durch ein eine einem einen
+einer eines er es für hat hatte ich ihr im in ist ja kann man mit nach nicht
+noch nur ob oder ohne sich sie sind so über um und uns unter vom von vor war
+was weil wenn wie wir wird zu zum zur`,
+	"es": `al algo como con de del el ella ellos en entre es esta este esto fue ha
+hay la las le les lo los más me mi muy no nos para pero por que se si sin sobre
+su sus también te tu un una uno ya yo`,
+	"fr": `au aux avec ce ces dans de des du elle en est et eux été être il ils je
+la le les leur lui ma mais me même mes moi mon ne nos notre nous on ou par pas
+pour qu que qui sa se ses son sont sur ta te tes toi ton tu un une vos votre
+vous`,
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestTokenizer(t *testing.T) {
+	c := qt.New(t)
+
+	en := NewTokenizer("en-US", nil)
+	c.Assert(en.Stemmer(), qt.Equals, "en")
+	c.Assert(en.Tokens("The Hosting of sites: hosted, hosts & a host!"), qt.DeepEquals, []string{"host", "site", "host", "host", "host"})
+	c.Assert(en.Tokens("Running, stopped, studies, boxes, glasses, quickly, bus, this, need"), qt.DeepEquals,
+		[]string{"run", "stop", "study", "box", "glass", "quick", "bus", "need"})
+
+	fr := NewTokenizer("fr", nil)
+	c.Assert(fr.Stemmer(), qt.Equals, "")
+	c.Assert(fr.Tokens("Le développement des sites"), qt.DeepEquals, []string{"développement", "sites"})
+
+	custom := NewTokenizer("en", []string{"Hugo"})
+	c.Assert(custom.StopWords(), qt.DeepEquals, []string{"hugo"})
+	c.Assert(custom.Tokens("Hugo is fast"), qt.DeepEquals, []string{"is", "fast"})
+
+	none := NewTokenizer("nn", nil)
+	c.Assert(none.StopWords(), qt.HasLen, 0)
+	c.Assert(none.Tokens("Ein 日本語 x2"), qt.DeepEquals, []string{"ein", "日本語", "x2"})
+}
@@ -14,6 +14,7 @@
 package config
 
 import (
+	"net/http"
 	"sort"
 	"strings"
 	"sync"
@@ -45,6 +46,11 @@ type Build struct {
 	// Can be used to toggle off writing of the intellinsense /assets/jsconfig.js
 	// file.
 	NoJSConfigInAssets bool
+
+	// When enabled, a build manifest is written to the file cache after each
+	// build, and regular pages whose inputs have not changed since the last
+	// build are not rendered again.
+	Incremental bool
 }
 
 func (b Build) UseResourceCache(err error) bool {
@@ -85,6 +91,12 @@ type Sitemap struct {
 	ChangeFreq string
 	Priority   float64
 	Filename   string
+
+	// The maximum number of URLs in one sitemap file.
+	// Larger sitemaps are split into multiple files, e.g. sitemap-1.xml and
+	// sitemap-2.xml, listed in a sitemap index written to Filename.
+	// A value <= 0 disables the splitting.
+	MaxURLs int
 }
 
 func DecodeSitemap(prototype Sitemap, input map[string]interface{}) Sitemap {
@@ -96,6 +108,8 @@ func DecodeSitemap(prototype Sitemap, input map[string]interface{}) Sitemap {
 			prototype.Priority = cast.ToFloat64(value)
 		case "filename":
 			prototype.Filename = cast.ToString(value)
+		case "maxurls":
+			prototype.MaxURLs = cast.ToInt(value)
 		default:
 			jww.WARN.Printf("Unknown Sitemap field: %s\n", key)
 		}
@@ -201,6 +215,13 @@ func DecodeServer(cfg Provider) (*Server, error) {
 	_ = mapstructure.WeakDecode(m, s)
 
 	for i, redir := range s.Redirects {
+		if redir.Status == http.StatusNotFound {
+			// The To value is the not found page to serve, e.g. "/fr/404.html".
+			if !strings.HasPrefix(redir.To, "/") {
+				return nil, errors.Errorf("unsupported redirect to value %q in server config; a redirect with status 404 must be to a local file, e.g. \"/404.html\"", redir.To)
+			}
+			continue
+		}
 		// Get it in line with the Hugo server.
 		redir.To = strings.TrimSuffix(redir.To, "index.html")
 		if !strings.HasPrefix(redir.To, "https") && !strings.HasSuffix(redir.To, "/") {
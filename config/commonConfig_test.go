@@ -73,6 +73,11 @@ from = "/foo/**"
 to = "/foo/index.html"
 status = 200
 
+[[server.redirects]]
+from = "/fr/**"
+to = "/fr/404.html"
+status = 404
+
 [[server.redirects]]
 from = "/google/**"
 to = "https://google.com/"
@@ -110,6 +115,12 @@ status = 301
 		Status: 301,
 	})
 
+	c.Assert(s.MatchRedirect("/fr/missing/"), qt.DeepEquals, Redirect{
+		From:   "/fr/**",
+		To:     "/fr/404.html",
+		Status: 404,
+	})
+
 	c.Assert(s.MatchRedirect("/google/foo"), qt.DeepEquals, Redirect{
 		From:   "/google/**",
 		To:     "https://google.com/",
@@ -129,6 +140,10 @@ status = 301`,
 from = "/**"
 to = "/foo/file.html"
 status = 301`,
+		`[[server.redirects]]
+from = "/**"
+to = "https://example.org/404.html"
+status = 404`,
 	} {
 
 		cfg, err := FromConfigString(errorCase, "toml")
@@ -16,6 +16,7 @@ package hugolib
 import (
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/gohugoio/hugo/common/loggers"
@@ -157,3 +158,152 @@ func TestTargetPathHTMLRedirectAlias(t *testing.T) {
 		}
 	}
 }
+
+func TestAliasRedirectMaps(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.org/docs/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+[redirectMap]
+formats = ["netlify", "json"]
+[[redirects]]
+from = "/app/"
+to = "/docs/app/index.html"
+status = 200
+[[redirects]]
+from = "/docs/taken/"
+to = "https://example.com/"
+status = 302
+-- content/posts/p1.md --
+---
+title: "P1"
+aliases: ["/old/", "rel", "/taken"]
+---
+-- layouts/_default/single.html --
+Single.
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/_redirects", `
+/app/ /docs/app/index.html 200
+/docs/old/ /docs/posts/p1/ 301
+/docs/posts/rel/ /docs/posts/p1/ 301
+/docs/taken/ https://example.com/ 302
+`)
+	b.AssertFileContent("public/redirects.json", `"from": "/docs/old/"`, `"to": "/docs/posts/p1/"`)
+	b.AssertFileContent("public/old/index.html", "https://example.org/docs/posts/p1/")
+	b.AssertDestinationExists("public/redirects.nginx.conf", false)
+
+	m := b.H.RedirectMap("")
+	b.Assert(m.Match("/docs/old").To, qt.Equals, "/docs/posts/p1/")
+	b.Assert(m.Match("/docs/posts/p1/").IsZero(), qt.IsTrue)
+}
+
+func TestAliasRedirectMapsDisabled(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+-- content/p1.md --
+---
+title: "P1"
+aliases: ["/old/"]
+---
+-- layouts/_default/single.html --
+Single.
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/old/index.html", "https://example.org/p1/")
+	b.AssertDestinationExists("public/_redirects", false)
+	b.Assert(b.H.RedirectMap("").Match("/old/").To, qt.Equals, "/p1/")
+}
+
+func TestAliasRedirectMapsMultilingual(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+disableAliases = true
+[redirectMap]
+formats = ["netlify"]
+[languages.en]
+weight = 1
+[[languages.en.redirects]]
+from = "/en-only/"
+to = "/"
+[languages.fr]
+weight = 2
+[[languages.fr.redirects]]
+from = "/fr-only/"
+to = "/fr/"
+-- content/p1.md --
+---
+title: "P1"
+aliases: ["/old/"]
+---
+-- content/p1.fr.md --
+---
+title: "P1 fr"
+aliases: ["/vieux/"]
+---
+-- layouts/_default/single.html --
+Single.
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/_redirects", `
+/en-only/ / 301
+/fr-only/ /fr/ 301
+/old/ /p1/ 301
+/vieux/ /fr/p1/ 301
+`)
+	b.AssertDestinationExists("public/old/index.html", false)
+	b.AssertDestinationExists("public/vieux/index.html", false)
+
+	files = strings.Replace(files, `[languages.fr]
+weight = 2`, `[languages.fr]
+weight = 2
+[languages.fr.redirectMap]
+status = 302`, 1)
+
+	_, err := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).BuildE()
+
+	b.Assert(err, qt.Not(qt.IsNil))
+	b.Assert(err.Error(), qt.Contains, `the redirectMap config for language "fr" differs from language "en"`)
+}
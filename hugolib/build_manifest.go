@@ -0,0 +1,646 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/common/hugo"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/hugolib/filesystems"
+	"github.com/gohugoio/hugo/identity"
+	"github.com/gohugoio/hugo/resources/page"
+	"github.com/gohugoio/hugo/tpl"
+	"github.com/pkg/errors"
+)
+
+// Bump this when the build manifest format or the rules for when a page
+// can be skipped change.
+const buildManifestVersion = 3
+
+// buildManifest is stored in the file cache after every successful build
+// with build.incremental enabled.
+type buildManifest struct {
+	Version int `json:"version"`
+
+	// A hash of the Hugo version, the site configuration, all files in
+	// data, i18n and assets, and the names of the files in layouts.
+	GlobalHash string `json:"globalHash"`
+
+	// A hash of the page keys and kinds, and the titles, dates, weights and
+	// front matter of all pages. Any of these may change which pages are
+	// listed where.
+	MetadataHash string `json:"metadataHash"`
+
+	// Hashes of the remote data used in the build, e.g. from getJSON and
+	// resources.GetRemote, keyed by file cache name and id. An empty hash
+	// means that the data was not cached and may change between builds.
+	RemoteHashes map[string]string `json:"remoteHashes,omitempty"`
+
+	// Keyed by language and content tree key.
+	Pages map[string]*buildManifestPage `json:"pages"`
+}
+
+type buildManifestPage struct {
+	// The files this page was rendered to, relative to publishDir.
+	Targets []string `json:"targets,omitempty"`
+
+	// A hash of the content file, the files in the page bundle, and the Git
+	// info and last modification date of the page.
+	SourceHash string `json:"sourceHash,omitempty"`
+
+	// The templates used to render the page, including base templates,
+	// partials, shortcodes and render hooks, keyed by their path in layouts.
+	// The value is the hash of the template file, empty for built-in
+	// templates.
+	Templates map[string]string `json:"templates,omitempty"`
+
+	// The keys of the other pages used to render the page, e.g. pages
+	// listed, fetched with GetPage or included with a shortcode.
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// incrementalBuild holds the state of an incremental build.
+//
+// While rendering, we record the templates and the other pages each
+// regular page uses. In the next build, a regular page is rendered again
+// only if its sources, any of these templates, or any of these pages
+// (recursively) have changed. List pages are always rendered.
+type incrementalBuild struct {
+	h     *HugoSites
+	cache *filecache.Cache
+	id    string
+
+	// The manifest from the previous build, nil if a full build is needed.
+	prev *buildManifest
+
+	globalHash   string
+	metadataHash string
+
+	// Keyed by page key.
+	sourceHashes map[string]string
+
+	// Keyed by the lower case path in layouts.
+	layoutHashes map[string]string
+
+	// The pages in the previous build that need to be rendered again.
+	changed map[string]bool
+
+	mu      sync.Mutex
+	targets map[string][]string
+	skipped map[string]bool
+}
+
+func (h *HugoSites) newIncrementalBuild() (*incrementalBuild, error) {
+	b := &incrementalBuild{
+		h:            h,
+		cache:        h.FileCaches.BuildManifestCache(),
+		id:           helpers.MD5String(h.Cfg.GetString("environment")+h.Cfg.GetString("publishDir")) + ".json",
+		sourceHashes: make(map[string]string),
+		layoutHashes: make(map[string]string),
+		targets:      make(map[string][]string),
+		skipped:      make(map[string]bool),
+	}
+
+	var err error
+	if b.globalHash, err = b.hashGlobal(); err != nil {
+		return nil, errors.Wrap(err, "failed to hash build inputs")
+	}
+	if b.metadataHash, err = b.hashPages(); err != nil {
+		return nil, errors.Wrap(err, "failed to hash content")
+	}
+
+	_, data, err := b.cache.GetBytes(b.id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read build manifest")
+	}
+	if data == nil {
+		return b, nil
+	}
+
+	var m buildManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		h.Log.Warnf("Failed to read build manifest, doing a full build: %s", err)
+		return b, nil
+	}
+
+	if m.Version != buildManifestVersion || m.GlobalHash != b.globalHash || m.MetadataHash != b.metadataHash {
+		h.Log.Infoln("Build inputs changed since the last build, doing a full build")
+		return b, nil
+	}
+
+	changed, err := b.remoteChanged(m.RemoteHashes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash remote data")
+	}
+	if changed {
+		h.Log.Infoln("Remote data changed or expired since the last build, doing a full build")
+		return b, nil
+	}
+
+	b.prev = &m
+	b.changed = b.changedPages()
+
+	return b, nil
+}
+
+// remoteCaches returns the file caches holding remote data, keyed by name.
+func (b *incrementalBuild) remoteCaches() map[string]*filecache.Cache {
+	return map[string]*filecache.Cache{
+		"getjson":     b.h.FileCaches.GetJSONCache(),
+		"getcsv":      b.h.FileCaches.GetCSVCache(),
+		"getresource": b.h.FileCaches.GetResourceCache(),
+	}
+}
+
+// remoteChanged reports whether any of the remote data used in the previous
+// build is no longer in the file cache as it was.
+func (b *incrementalBuild) remoteChanged(prev map[string]string) (bool, error) {
+	caches := b.remoteCaches()
+	for key, prevHash := range prev {
+		if prevHash == "" {
+			return true, nil
+		}
+		name, id := splitRemoteKey(key)
+		cache := caches[name]
+		if cache == nil {
+			return true, nil
+		}
+		data, err := cache.Peek(id)
+		if err != nil {
+			return false, err
+		}
+		if data == nil || hashBytes(data) != prevHash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// remoteHashes hashes the remote data used in this build. Remote data used
+// by skipped pages is carried over from the previous build.
+func (b *incrementalBuild) remoteHashes() (map[string]string, error) {
+	hashes := make(map[string]string)
+	if b.prev != nil {
+		for k, v := range b.prev.RemoteHashes {
+			hashes[k] = v
+		}
+	}
+
+	for name, cache := range b.remoteCaches() {
+		if cache == nil {
+			continue
+		}
+		for _, id := range cache.UsedIDs() {
+			data, err := cache.Peek(id)
+			if err != nil {
+				return nil, err
+			}
+			var hash string
+			if data != nil {
+				hash = hashBytes(data)
+			}
+			hashes[name+":"+id] = hash
+		}
+	}
+
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	return hashes, nil
+}
+
+func splitRemoteKey(key string) (name, id string) {
+	i := strings.Index(key, ":")
+	if i == -1 {
+		return "", key
+	}
+	return key[:i], key[i+1:]
+}
+
+// hashGlobal hashes the inputs that may affect every page.
+func (b *incrementalBuild) hashGlobal() (string, error) {
+	h := md5.New()
+	fmt.Fprintln(h, hugo.CurrentVersion.String())
+
+	// The configuration also contains some runtime values that we cannot
+	// serialize; these are skipped.
+	root := b.h.Cfg.Get("").(maps.Params)
+	keys := make([]string, 0, len(root))
+	for k := range root {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, err := json.Marshal(root[k])
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(h, "%s=%s\n", k, v)
+	}
+
+	if err := b.hashLayouts(h); err != nil {
+		return "", err
+	}
+
+	for _, fs := range []*filesystems.SourceFilesystem{
+		b.h.BaseFs.Data,
+		b.h.BaseFs.I18n,
+		b.h.BaseFs.Assets,
+	} {
+		if err := hashFilesystem(h, fs); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashLayouts writes the names of all files in layouts to h, as adding or
+// removing a template may change the layout used for any page. The hash of
+// each template file is stored for the checks per page.
+func (b *incrementalBuild) hashLayouts(h hash.Hash) error {
+	fs := b.h.BaseFs.Layouts
+	if fs == nil {
+		return nil
+	}
+	return helpers.SymbolicWalk(fs.Fs, "", func(path string, fi hugofs.FileMetaInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		fmt.Fprintln(h, path)
+		fh := md5.New()
+		if err := hashFile(fh, fi); err != nil {
+			return err
+		}
+		// Templates are identified by their lower case path.
+		name := identity.NewPathIdentity(files.ComponentFolderLayouts, path).Path
+		b.layoutHashes[name] = hex.EncodeToString(fh.Sum(nil))
+		return nil
+	})
+}
+
+// hashPages hashes the structure and the metadata of all sites, and stores
+// the hash of the sources of every page. It also prepares the regular
+// pages for recording their dependencies.
+func (b *incrementalBuild) hashPages() (string, error) {
+	h := md5.New()
+	for _, s := range b.h.Sites {
+		var err error
+		s.pageMap.pageTrees.Walk(func(key string, n *contentNode) bool {
+			p := n.p
+			if p == nil {
+				return false
+			}
+			fmt.Fprintf(h, "%s:%s:%s\n", s.Lang(), key, p.Kind())
+			hashPageMetadata(h, p)
+
+			sh := md5.New()
+			if err = b.hashPageSource(sh, s, key, p); err != nil {
+				return true
+			}
+			key = incrementalPageKey(s, key)
+			b.sourceHashes[key] = hex.EncodeToString(sh.Sum(nil))
+
+			if p.Kind() == page.KindPage {
+				p.dependencies = identity.NewManager(identity.KeyValueIdentity{Key: "page", Value: key})
+			}
+			return false
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashPageMetadata writes what other pages may use to select, sort and
+// group p to h.
+//
+// The last modification date is left out, as it usually changes with the
+// content, e.g. when taken from Git. It is part of the page sources.
+func hashPageMetadata(h hash.Hash, p *pageState) {
+	fmt.Fprintf(h, "%s|%s|%s|%s|%d|%t\n", p.Title(), p.Date(), p.PublishDate(), p.ExpiryDate(), p.Weight(), p.Draft())
+	params := p.Params()
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k == "lastmod" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%v\n", k, params[k])
+	}
+}
+
+func (b *incrementalBuild) hashPageSource(h hash.Hash, s *Site, key string, p *pageState) error {
+	if gi := p.GitInfo(); gi != nil {
+		fmt.Fprintln(h, gi.Hash)
+	}
+	fmt.Fprintln(h, p.Lastmod())
+
+	if p.File().IsZero() {
+		return nil
+	}
+
+	if err := hashFile(h, p.File().FileInfo()); err != nil {
+		return err
+	}
+
+	prefix := key
+	if p.IsNode() {
+		prefix += cmLeafSeparator
+	}
+
+	var err error
+	s.pageMap.resources.WalkPrefix(prefix, func(s string, v interface{}) bool {
+		n := v.(*contentNode)
+		fmt.Fprintln(h, n.path)
+		err = hashFile(h, n.fi)
+		return err != nil
+	})
+
+	return err
+}
+
+// changedPages returns the pages in the previous build that need to be
+// rendered again, because their sources or any of the templates they used
+// have changed, or because they used a page that needs to be rendered again.
+func (b *incrementalBuild) changedPages() map[string]bool {
+	changed := make(map[string]bool)
+	dependents := make(map[string][]string)
+	var queue []string
+
+	for key, p := range b.prev.Pages {
+		for _, dep := range p.Dependencies {
+			dependents[dep] = append(dependents[dep], key)
+		}
+		if p.SourceHash != b.sourceHashes[key] || b.templatesChanged(p.Templates) {
+			changed[key] = true
+			queue = append(queue, key)
+		}
+	}
+
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[key] {
+			if !changed[dependent] {
+				changed[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	return changed
+}
+
+func (b *incrementalBuild) templatesChanged(templates map[string]string) bool {
+	for name, hash := range templates {
+		if b.layoutHashes[name] != hash {
+			return true
+		}
+	}
+	return false
+}
+
+// skip reports whether rendering p to targetPath can be skipped, and
+// records what is needed to write the next build manifest.
+func (b *incrementalBuild) skip(p *pageState, targetPath string) bool {
+	key := p.incrementalKey()
+	if key == "" {
+		return false
+	}
+
+	b.mu.Lock()
+	b.targets[key] = append(b.targets[key], targetPath)
+	b.mu.Unlock()
+
+	// List pages may list pages that were added or removed since the last
+	// build, so we render them every time.
+	if b.prev == nil || p.Kind() != page.KindPage || b.changed[key] {
+		return false
+	}
+
+	prev, found := b.prev.Pages[key]
+	if !found {
+		return false
+	}
+
+	var hasTarget bool
+	for _, t := range prev.Targets {
+		if t == targetPath {
+			hasTarget = true
+			break
+		}
+	}
+	if !hasTarget {
+		return false
+	}
+	if exists, _ := helpers.Exists(filepath.FromSlash(targetPath), p.s.BaseFs.PublishFs); !exists {
+		return false
+	}
+
+	b.mu.Lock()
+	b.skipped[key] = true
+	b.mu.Unlock()
+
+	return true
+}
+
+// save writes the build manifest for the current build to the file cache.
+func (b *incrementalBuild) save() error {
+	remoteHashes, err := b.remoteHashes()
+	if err != nil {
+		return errors.Wrap(err, "failed to hash remote data")
+	}
+
+	m := buildManifest{
+		Version:      buildManifestVersion,
+		GlobalHash:   b.globalHash,
+		MetadataHash: b.metadataHash,
+		RemoteHashes: remoteHashes,
+		Pages:        make(map[string]*buildManifestPage),
+	}
+
+	for _, s := range b.h.Sites {
+		s.pageMap.pageTrees.Walk(func(key string, n *contentNode) bool {
+			p := n.p
+			if p == nil {
+				return false
+			}
+			key = incrementalPageKey(s, key)
+			mp := &buildManifestPage{
+				Targets:    b.targets[key],
+				SourceHash: b.sourceHashes[key],
+			}
+			if p.dependencies != nil {
+				var prev *buildManifestPage
+				if b.skipped[key] {
+					// Keep what was recorded when the page was last rendered.
+					prev = b.prev.Pages[key]
+				}
+				mp.Templates, mp.Dependencies = b.collectDependencies(key, p.dependencies, prev)
+			}
+			m.Pages[key] = mp
+			return false
+		})
+	}
+
+	_, w, err := b.cache.WriteCloser(b.id)
+	if err != nil {
+		return errors.Wrap(err, "failed to write build manifest")
+	}
+	defer w.Close()
+
+	if err := json.NewEncoder(w).Encode(m); err != nil {
+		return errors.Wrap(err, "failed to write build manifest")
+	}
+
+	if len(b.skipped) > 0 {
+		b.h.Log.Infof("Incremental build: skipped rendering of %d unchanged pages", len(b.skipped))
+	}
+
+	return nil
+}
+
+// collectDependencies returns the templates and the other pages recorded
+// in m when rendering the page with the given key, merged with prev if set.
+func (b *incrementalBuild) collectDependencies(key string, m identity.Manager, prev *buildManifestPage) (map[string]string, []string) {
+	templates := make(map[string]string)
+	pages := make(map[string]bool)
+
+	if prev != nil {
+		for name, hash := range prev.Templates {
+			templates[name] = hash
+		}
+		for _, dep := range prev.Dependencies {
+			pages[dep] = true
+		}
+	}
+
+	visited := make(map[identity.Identity]bool)
+	var collect func(ids identity.Identities)
+	collect = func(ids identity.Identities) {
+		for id, v := range ids {
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+
+			if pid, ok := id.(identity.PathIdentity); ok && pid.Type == files.ComponentFolderLayouts {
+				templates[pid.Path] = b.layoutHashes[pid.Path]
+			}
+			if pp, err := unwrapPage(v); err == nil {
+				if ps, ok := pp.(*pageState); ok {
+					if k := ps.incrementalKey(); k != "" && k != key {
+						pages[k] = true
+					}
+				}
+			}
+			if ip, ok := v.(identity.IdentitiesProvider); ok {
+				collect(ip.GetIdentities())
+			}
+		}
+	}
+	collect(m.GetIdentities())
+
+	if len(templates) == 0 {
+		templates = nil
+	}
+
+	var dependencies []string
+	for k := range pages {
+		dependencies = append(dependencies, k)
+	}
+	sort.Strings(dependencies)
+
+	return templates, dependencies
+}
+
+// dependencyContext returns the context to use when executing templates
+// on behalf of the page in, which records the templates and the pages used
+// when build.incremental is enabled.
+func dependencyContext(in interface{}) context.Context {
+	ctx := context.Background()
+	if p, err := unwrapPage(in); err == nil {
+		if ps, ok := p.(*pageState); ok && ps.dependencies != nil {
+			ctx = tpl.SetDependencyManagerInContext(ctx, ps.dependencies)
+		}
+	}
+	return ctx
+}
+
+func (p *pageState) incrementalKey() string {
+	if p.treeRef == nil {
+		return ""
+	}
+	return incrementalPageKey(p.s, p.treeRef.key)
+}
+
+func incrementalPageKey(s *Site, key string) string {
+	return s.Lang() + ":" + key
+}
+
+func hashFilesystem(h hash.Hash, fs *filesystems.SourceFilesystem) error {
+	if fs == nil {
+		return nil
+	}
+	return helpers.SymbolicWalk(fs.Fs, "", func(path string, fi hugofs.FileMetaInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		fmt.Fprintln(h, path)
+		return hashFile(h, fi)
+	})
+}
+
+func hashFile(h hash.Hash, fi hugofs.FileMetaInfo) error {
+	f, err := fi.Meta().Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
+
+func hashBytes(b []byte) string {
+	h := md5.Sum(b)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/cache/filecache"
+	"github.com/gohugoio/hugo/deps"
+)
+
+func TestIncrementalBuild(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+[build]
+incremental = true
+-- content/p1.md --
+---
+title: "p1"
+---
+P1 content.
+-- content/p2.md --
+---
+title: "p2"
+layout: "plain"
+---
+P2 [link](https://example.org).
+-- content/p3.md --
+---
+title: "p3"
+---
+P3: {{< content "/p1" >}}
+-- content/p4.md --
+---
+title: "p4"
+layout: "others"
+---
+-- layouts/shortcodes/content.html --
+{{ with .Page.GetPage (.Get 0) }}{{ .Content }}{{ end }}
+-- layouts/partials/footer.html --
+Footer.
+-- layouts/_default/_markup/render-link.html --
+<a href="{{ .Destination }}">{{ .Text }}</a>
+-- layouts/_default/single.html --
+Single: {{ .Title }}|{{ .Content }}|{{ partialCached "footer.html" . "footer" }}
+-- layouts/_default/plain.html --
+Plain: {{ .Title }}|{{ .Content }}
+-- layouts/_default/others.html --
+Others: {{ with .Site.GetPage "/p2" }}{{ .Title }}: {{ .Content }}{{ end }}
+-- layouts/index.html --
+Home: {{ range .Site.RegularPages }}{{ .Title }}: {{ .Summary }}|{{ end }}
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertRenderCountPage(5)
+	b.AssertFileContent("public/p3/index.html", "Single: p3|", "P1 content.", "Footer.")
+
+	// Simulates a new hugo process building the same project.
+	build := func() *testCounters {
+		h, err := NewHugoSites(deps.DepsCfg{Cfg: b.H.Cfg, Fs: b.fs, Logger: b.H.Log})
+		b.Assert(err, qt.IsNil)
+		counters := &testCounters{}
+		b.Assert(h.Build(BuildCfg{testCounters: counters}), qt.IsNil)
+		return counters
+	}
+
+	// Nothing changed, only the home page is rendered.
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(1))
+
+	// A changed page is rendered again with the pages using it.
+	b.EditFiles("content/p2.md", "---\ntitle: \"p2\"\nlayout: \"plain\"\n---\nP2 [edited](https://example.org).")
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(3))
+	b.AssertFileContent("public/p2/index.html", "Plain: p2|<p>P2 <a href=\"https://example.org\">edited</a>.</p>")
+	b.AssertFileContent("public/p4/index.html", "Others: p2: <p>P2 <a href=\"https://example.org\">edited</a>.</p>")
+
+	b.EditFiles("content/p1.md", "---\ntitle: \"p1\"\n---\nP1 edited.")
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(3))
+	b.AssertFileContent("public/index.html", "p1: P1 edited.|")
+	b.AssertFileContent("public/p1/index.html", "Single: p1|<p>P1 edited.</p>")
+	b.AssertFileContent("public/p3/index.html", "Single: p3|", "P1 edited.")
+
+	// Missing output files are rendered again.
+	b.Assert(b.fs.Destination.Remove(filepath.FromSlash("public/p2/index.html")), qt.IsNil)
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(2))
+	b.AssertFileContent("public/p2/index.html", "Plain: p2|")
+
+	// Changed templates are rendered again with the pages using them,
+	// also when used through partialCached, a shortcode or a render hook.
+	b.EditFiles("layouts/partials/footer.html", "Footer edited.")
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(3))
+	b.AssertFileContent("public/p1/index.html", "Footer edited.")
+	b.AssertFileContent("public/p3/index.html", "Footer edited.")
+
+	b.EditFiles("layouts/shortcodes/content.html", "Included: {{ with .Page.GetPage (.Get 0) }}{{ .Content }}{{ end }}")
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(2))
+	b.AssertFileContent("public/p3/index.html", "Included: <p>P1 edited.</p>")
+
+	b.EditFiles("layouts/_default/_markup/render-link.html", "<a class=\"edited\" href=\"{{ .Destination }}\">{{ .Text }}</a>")
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(3))
+	b.AssertFileContent("public/p2/index.html", "class=\"edited\"")
+	b.AssertFileContent("public/p4/index.html", "class=\"edited\"")
+
+	// Changes to front matter and added or removed templates may change
+	// what is listed or which layout is used anywhere, so all pages are
+	// rendered again.
+	b.EditFiles("content/p2.md", "---\ntitle: \"p2 edited\"\nlayout: \"plain\"\n---\nP2.")
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(5))
+	b.AddFiles("layouts/partials/header.html", "Header.")
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(5))
+
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(1))
+}
+
+func TestIncrementalBuildRemoteData(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "remote"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+[build]
+incremental = true
+-- content/p1.md --
+---
+title: "p1"
+---
+-- layouts/_default/single.html --
+Single: {{ .Title }}|{{ (getJSON "URL/data.json").name }}
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: strings.ReplaceAll(files, "URL", ts.URL),
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html", "Single: p1|remote")
+
+	var cache *filecache.Cache
+	build := func() *testCounters {
+		h, err := NewHugoSites(deps.DepsCfg{Cfg: b.H.Cfg, Fs: b.fs, Logger: b.H.Log})
+		b.Assert(err, qt.IsNil)
+		counters := &testCounters{}
+		b.Assert(h.Build(BuildCfg{testCounters: counters}), qt.IsNil)
+		cache = h.FileCaches.GetJSONCache()
+		return counters
+	}
+
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(1))
+
+	// Expired or removed remote data may have changed.
+	ids := b.H.FileCaches.GetJSONCache().UsedIDs()
+	b.Assert(ids, qt.HasLen, 1)
+	b.Assert(cache.Fs.Remove(ids[0]), qt.IsNil)
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(2))
+	b.Assert(build().pageRenderCounter, qt.Equals, uint64(1))
+}
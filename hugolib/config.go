@@ -261,7 +261,7 @@ func (l configLoader) applyConfigDefaults() error {
 		"titleCaseStyle":                       "AP",
 		"taxonomies":                           maps.Params{"tag": "tags", "category": "categories"},
 		"permalinks":                           maps.Params{},
-		"sitemap":                              maps.Params{"priority": -1, "filename": "sitemap.xml"},
+		"sitemap":                              maps.Params{"priority": -1, "filename": "sitemap.xml", "maxURLs": 50000},
 		"disableLiveReload":                    false,
 		"pluralizeListTitles":                  true,
 		"forceSyncStatic":                      false,
@@ -0,0 +1,439 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/hugofs"
+	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/gohugoio/hugo/resources/resource"
+	"github.com/gohugoio/hugo/resources/resource_factories/create"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+)
+
+// contentAdapterBaseName is the base name of the content adapter
+// configuration file, e.g. content/books/_content.toml.
+const contentAdapterBaseName = "_content"
+
+// contentAdapterConfig configures a content adapter, which creates one page
+// for every record in a data source.
+type contentAdapterConfig struct {
+	Source contentAdapterSource
+	Page   contentAdapterPage
+}
+
+type contentAdapterSource struct {
+	// The dot separated path to a file in the data directory, e.g. "books"
+	// for data/books.json.
+	Data string
+
+	// A file in the assets directory, e.g. "books.csv".
+	File string
+
+	// A remote URL, fetched and cached as with resources.GetRemote.
+	URL string
+
+	// Options passed to resources.GetRemote, e.g. headers.
+	Options map[string]interface{}
+
+	// The dot separated path to the list of records in the decoded source.
+	// If not set, the source itself must be a list.
+	Records string
+}
+
+type contentAdapterPage struct {
+	// The page path relative to the content adapter's directory, without
+	// any extension. A Go template executed with the record as context.
+	Path string
+
+	// The page content, a Go template executed with the record as context.
+	// The markup is Markdown unless set in the front matter.
+	Content string
+
+	// Maps front matter keys to dot separated paths in the record.
+	// If not set, all record fields are used as front matter.
+	Fields map[string]string
+
+	// Front matter added to every page.
+	FrontMatter map[string]interface{}
+}
+
+func isContentAdapter(fi hugofs.FileMetaInfo) bool {
+	meta := fi.Meta()
+	if fi.IsDir() || meta.TranslationBaseName != contentAdapterBaseName {
+		return false
+	}
+	switch metadecoders.FormatFromString(filepath.Ext(meta.Filename)) {
+	case metadecoders.TOML, metadecoders.YAML, metadecoders.JSON:
+		return true
+	}
+	return false
+}
+
+func decodeContentAdapterConfig(fi hugofs.FileMetaInfo) (contentAdapterConfig, error) {
+	var conf contentAdapterConfig
+
+	f, err := fi.Meta().Open()
+	if err != nil {
+		return conf, err
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return conf, err
+	}
+
+	m, err := metadecoders.Default.UnmarshalToMap(b, metadecoders.FormatFromString(filepath.Ext(fi.Meta().Filename)))
+	if err != nil {
+		return conf, err
+	}
+	if err := mapstructure.WeakDecode(m, &conf); err != nil {
+		return conf, err
+	}
+
+	var numSources int
+	for _, s := range []string{conf.Source.Data, conf.Source.File, conf.Source.URL} {
+		if s != "" {
+			numSources++
+		}
+	}
+	if numSources != 1 {
+		return conf, errors.New("exactly one of source.data, source.file and source.url must be set")
+	}
+	if conf.Page.Path == "" {
+		return conf, errors.New("page.path must be set")
+	}
+
+	return conf, nil
+}
+
+// handleContentAdapter creates the pages for the records in the content
+// adapter in fi and passes them on as if they were content files.
+func (c *pagesCollector) handleContentAdapter(fi hugofs.FileMetaInfo) error {
+	meta := fi.Meta()
+	wrapErr := func(err error) error {
+		return errors.Wrapf(err, "content adapter %q", meta.Filename)
+	}
+
+	conf, err := decodeContentAdapterConfig(fi)
+	if err != nil {
+		return wrapErr(err)
+	}
+
+	records, err := c.h.contentAdapterRecords(conf.Source)
+	if err != nil {
+		return wrapErr(err)
+	}
+
+	// The templates are executed with the site's template funcs.
+	pathTempl, err := c.h.TextTmpl().Parse(meta.Path+":path", conf.Page.Path)
+	if err != nil {
+		return wrapErr(err)
+	}
+	contentTempl, err := c.h.TextTmpl().Parse(meta.Path+":content", conf.Page.Content)
+	if err != nil {
+		return wrapErr(err)
+	}
+
+	var (
+		fs    = afero.NewMemMapFs()
+		seen  = make(map[string]bool)
+		pages []hugofs.FileMetaInfo
+		names = make(map[string]bool)
+	)
+
+	for i, record := range records {
+		p, err := executeToString(c.h.Tmpl(), pathTempl, record)
+		if err != nil {
+			return wrapErr(errors.Wrapf(err, "record %d", i))
+		}
+		p = strings.Trim(path.Clean("/"+strings.TrimSpace(p)), "/")
+		if p == "" {
+			return wrapErr(errors.Errorf("record %d: empty page path", i))
+		}
+		if seen[p] {
+			return wrapErr(errors.Errorf("record %d: duplicate page path %q", i, p))
+		}
+		seen[p] = true
+
+		content, err := executeToString(c.h.Tmpl(), contentTempl, record)
+		if err != nil {
+			return wrapErr(errors.Wrapf(err, "record %d", i))
+		}
+
+		frontMatter := make(map[string]interface{})
+		if len(conf.Page.Fields) == 0 {
+			for k, v := range record {
+				frontMatter[k] = v
+			}
+		} else {
+			for k, field := range conf.Page.Fields {
+				if v := lookupContentAdapterValue(record, field); v != nil {
+					frontMatter[k] = v
+				}
+			}
+		}
+		for k, v := range conf.Page.FrontMatter {
+			frontMatter[k] = v
+		}
+
+		fm, err := json.Marshal(frontMatter)
+		if err != nil {
+			return wrapErr(errors.Wrapf(err, "record %d", i))
+		}
+
+		filename := filepath.FromSlash(p + ".md")
+		if err := afero.WriteFile(fs, filename, append(append(fm, '\n'), content...), 0666); err != nil {
+			return err
+		}
+		pfi, err := fs.Stat(filename)
+		if err != nil {
+			return err
+		}
+
+		pmeta := meta.Copy()
+		pmeta.Name = filepath.Base(filename)
+		pmeta.Filename = filepath.Join(filepath.Dir(meta.Filename), filename)
+		pmeta.Path = filepath.Join(filepath.Dir(meta.Path), filename)
+		pmeta.PathWalk = filepath.Join(filepath.Dir(meta.PathWalk), filename)
+		pmeta.OriginalFilename = ""
+		pmeta.Classifier = files.ContentClassContent
+		pmeta.TranslationBaseName = strings.TrimSuffix(pmeta.Name, ".md")
+		pmeta.TranslationBaseNameWithExt = pmeta.Name
+		pmeta.Translations = nil
+		pmeta.Fs = fs
+		pmeta.OpenFunc = func() (afero.File, error) {
+			return fs.Open(filename)
+		}
+		pmeta.JoinStatFunc = nil
+
+		pages = append(pages, hugofs.NewFileMetaInfo(pfi, pmeta))
+		names[pmeta.Filename] = true
+	}
+
+	c.h.contentAdapters.set(meta.Filename, contentAdapter{source: conf.Source, pages: names})
+
+	return c.handleFiles(pages...)
+}
+
+// contentAdapters keeps track of the content adapters and the pages they
+// created, so they can be created again when their source changes.
+type contentAdapters struct {
+	mu sync.Mutex
+
+	// Keyed by the adapter's filename.
+	m map[string]contentAdapter
+}
+
+type contentAdapter struct {
+	source contentAdapterSource
+
+	// The filenames of the created pages.
+	pages map[string]bool
+}
+
+func (a *contentAdapters) set(filename string, adapter contentAdapter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.m == nil {
+		a.m = make(map[string]contentAdapter)
+	}
+	a.m[filename] = adapter
+}
+
+// remove removes the adapter in filename and returns the filenames of the
+// pages it created, nil if filename is not a content adapter.
+func (a *contentAdapters) remove(filename string) map[string]bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	adapter, found := a.m[filename]
+	if !found {
+		return nil
+	}
+	delete(a.m, filename)
+	return adapter.pages
+}
+
+// withChangedSource returns the filenames of the adapters reading from the
+// data directory, if dataChanged, or from any of the files in assets,
+// relative to the assets directory.
+func (a *contentAdapters) withChangedSource(dataChanged bool, assets map[string]bool) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var filenames []string
+	for filename, adapter := range a.m {
+		source := adapter.source
+		if (dataChanged && source.Data != "") || (source.File != "" && assets[strings.TrimPrefix(path.Clean(source.File), "/")]) {
+			filenames = append(filenames, filename)
+		}
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+// removeContentAdapterPages removes the pages created by the content adapters
+// in filenames, before they are created again.
+func (h *HugoSites) removeContentAdapterPages(filenames []string) {
+	pages := make(map[string]bool)
+	for _, filename := range filenames {
+		for p := range h.contentAdapters.remove(filename) {
+			pages[p] = true
+		}
+	}
+	if len(pages) == 0 {
+		return
+	}
+
+	h.getContentMaps().withMaps(func(m *pageMap) error {
+		m.deleteBundleMatching(func(b *contentNode) bool {
+			return b.p != nil && b.fi != nil && pages[b.fi.Meta().Filename]
+		})
+		return nil
+	})
+}
+
+// contentAdapterRecords reads the records from the given source.
+func (h *HugoSites) contentAdapterRecords(source contentAdapterSource) ([]map[string]interface{}, error) {
+	var v interface{}
+
+	if source.Data != "" {
+		v = lookupContentAdapterValue(h.Data(), source.Data)
+		if v == nil {
+			return nil, errors.Errorf("data %q not found", source.Data)
+		}
+	} else {
+		client := create.New(h.ResourceSpec)
+
+		var (
+			r   resource.Resource
+			err error
+		)
+		if source.File != "" {
+			r, err = client.Get(source.File)
+		} else {
+			r, err = client.FromRemote(source.URL, source.Options)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			return nil, errors.Errorf("%s not found", source.File+source.URL)
+		}
+
+		cp, ok := r.(resource.ContentProvider)
+		if !ok {
+			return nil, errors.Errorf("%s has no content", source.File+source.URL)
+		}
+		content, err := cp.Content()
+		if err != nil {
+			return nil, err
+		}
+
+		format := metadecoders.FormatFromMediaType(r.MediaType())
+		if format == "" {
+			return nil, errors.Errorf("unsupported media type %q", r.MediaType().Type())
+		}
+		v, err = metadecoders.Default.Unmarshal([]byte(cast.ToString(content)), format)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if source.Records != "" {
+		v = lookupContentAdapterValue(v, source.Records)
+		if v == nil {
+			return nil, errors.Errorf("records %q not found", source.Records)
+		}
+	}
+
+	return toContentAdapterRecords(v)
+}
+
+// toContentAdapterRecords converts a list of maps, or CSV rows with a header
+// row, to records.
+func toContentAdapterRecords(v interface{}) ([]map[string]interface{}, error) {
+	switch vv := v.(type) {
+	case [][]string:
+		if len(vv) == 0 {
+			return nil, nil
+		}
+		header := vv[0]
+		records := make([]map[string]interface{}, len(vv)-1)
+		for i, row := range vv[1:] {
+			record := make(map[string]interface{})
+			for j, name := range header {
+				if j < len(row) {
+					record[name] = row[j]
+				}
+			}
+			records[i] = record
+		}
+		return records, nil
+	case []map[string]interface{}:
+		return vv, nil
+	case []interface{}:
+		records := make([]map[string]interface{}, len(vv))
+		for i, r := range vv {
+			m, err := maps.ToStringMapE(r)
+			if err != nil {
+				return nil, errors.Errorf("record %d is a %T, not a map", i, r)
+			}
+			records[i] = m
+		}
+		return records, nil
+	}
+	return nil, errors.Errorf("records must be a list, got %T", v)
+}
+
+// lookupContentAdapterValue looks up the value at the dot separated path
+// in v, falling back to a case insensitive match of the keys.
+func lookupContentAdapterValue(v interface{}, p string) interface{} {
+	for _, key := range strings.Split(p, ".") {
+		m, err := maps.ToStringMapE(v)
+		if err != nil {
+			return nil
+		}
+		vv, found := m[key]
+		if !found {
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if strings.EqualFold(k, key) {
+					vv, found = m[k], true
+					break
+				}
+			}
+		}
+		if !found {
+			return nil
+		}
+		v = vv
+	}
+	return v
+}
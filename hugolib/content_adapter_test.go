@@ -0,0 +1,187 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestContentAdapter(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["RSS", "sitemap", "robotsTXT", "404"]
+[permalinks]
+books = "/library/:slug/"
+-- data/books.json --
+{
+	"items": [
+		{ "isbn": "111", "title": "Book One", "summary": "The *first* book.", "genres": ["fiction"], "published": "2021-01-02" },
+		{ "isbn": "222", "title": "Book Two", "summary": "The second book.", "note": " Keep ` + "`<no value>`" + ` as is.", "genres": ["fiction", "history"], "published": "2022-03-04" }
+	]
+}
+-- content/books/_index.md --
+---
+title: "Books"
+---
+-- content/books/_content.toml --
+[source]
+data = "books"
+records = "items"
+[page]
+path = "{{ .isbn }}"
+content = "{{ .summary }}{{ .note | default \"\" }}"
+[page.fields]
+title = "title"
+date = "published"
+tags = "genres"
+slug = "isbn"
+[page.frontMatter]
+layout = "book"
+menus = "main"
+-- assets/authors.csv --
+name,born
+Ada Lovelace,1815
+Alan Turing,1912
+-- content/authors/_content.yaml --
+source:
+  file: authors.csv
+page:
+  path: "{{ .name | urlize }}"
+  content: "Born {{ .born }}{{ with .died }}, died {{ . }}{{ end }}."
+-- layouts/_default/book.html --
+Book: {{ .Title }}|{{ .Date.Format "2006-01-02" }}|{{ .Content }}|Tags: {{ range .GetTerms "tags" }}{{ .Title }}|{{ end }}
+-- layouts/_default/single.html --
+Single: {{ .Title }}|{{ .Params.born }}|{{ .Content }}
+-- layouts/_default/list.html --
+List: {{ .Title }}|{{ range .Pages }}{{ .Title }}:{{ .RelPermalink }}|{{ end }}
+-- layouts/index.html --
+Menu: {{ range .Site.Menus.main }}{{ .Name }}|{{ end }}
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/library/111/index.html", "Book: Book One|2021-01-02|<p>The <em>first</em> book.</p>\n|Tags: fiction|")
+	b.AssertFileContent("public/library/222/index.html", "Book: Book Two|2022-03-04|<p>The second book. Keep <code>&lt;no value&gt;</code> as is.</p>\n|", "Tags: fiction|history|")
+	b.AssertFileContent("public/books/index.html", "List: Books|Book Two:/library/222/|Book One:/library/111/|")
+	b.AssertFileContent("public/tags/fiction/index.html", "Book Two:/library/222/|Book One:/library/111/|")
+	b.AssertFileContent("public/index.html", "Menu: Book One|Book Two|")
+
+	b.AssertFileContent("public/authors/ada-lovelace/index.html", "Single: |1815|<p>Born 1815.</p>")
+	b.AssertFileContent("public/authors/alan-turing/index.html", "Single: |1912|<p>Born 1912.</p>")
+	b.AssertDestinationExists("public/books/_content.toml", false)
+	b.AssertDestinationExists("public/authors/_content.yaml", false)
+}
+
+func TestContentAdapterErrors(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+-- data/books.json --
+[{ "title": "A" }, { "title": "A" }]
+-- content/books/_content.toml --
+[source]
+data = "books"
+[page]
+path = "{{ .title }}"
+-- layouts/_default/single.html --
+{{ .Title }}
+`
+
+	b, err := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).BuildE()
+
+	b.Assert(err, qt.IsNotNil)
+	b.Assert(err.Error(), qt.Contains, `record 1: duplicate page path "A"`)
+}
+
+func TestContentAdapterRebuild(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+-- data/books.json --
+[{ "id": "a", "title": "Book A" }, { "id": "b", "title": "Book B" }]
+-- assets/authors.csv --
+name
+Ada
+-- content/books/_index.md --
+---
+title: "Books"
+---
+-- content/books/_content.toml --
+[source]
+data = "books"
+[page]
+path = "{{ .id }}"
+-- content/authors/_content.toml --
+[source]
+file = "authors.csv"
+[page]
+path = "{{ .name | lower }}"
+[page.fields]
+title = "name"
+-- layouts/_default/single.html --
+Single: {{ .Title }}|
+-- layouts/_default/list.html --
+List: {{ range .Pages }}{{ .Title }}|{{ end }}END
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+			Running:     true,
+		},
+	).Build()
+
+	b.AssertFileContent("public/books/index.html", "List: Book A|Book B|END")
+	b.AssertFileContent("public/authors/ada/index.html", "Single: Ada|")
+
+	b.EditFiles("data/books.json", `[{ "id": "a", "title": "Book A Edited" }, { "id": "c", "title": "Book C" }]`).Build()
+
+	b.AssertFileContent("public/books/index.html", "List: Book A Edited|Book C|END")
+	b.AssertFileContent("public/books/a/index.html", "Single: Book A Edited|")
+	b.AssertFileContent("public/books/c/index.html", "Single: Book C|")
+
+	b.EditFiles("assets/authors.csv", "name\nAlan").Build()
+
+	b.AssertFileContent("public/authors/alan/index.html", "Single: Alan|")
+	b.AssertFileContent("public/authors/index.html", "List: Alan|END")
+
+	b.EditFiles("content/books/_content.toml", `[source]
+data = "books"
+[page]
+path = "book-{{ .id }}"`).Build()
+
+	b.AssertFileContent("public/books/index.html", "List: Book A Edited|Book C|END")
+	b.AssertFileContent("public/books/book-c/index.html", "Single: Book C|")
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 	"io"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -52,6 +53,7 @@ import (
 	"github.com/gohugoio/hugo/lazy"
 
 	"github.com/gohugoio/hugo/langs/i18n"
+	"github.com/gohugoio/hugo/redirects"
 	"github.com/gohugoio/hugo/resources/page"
 	"github.com/gohugoio/hugo/resources/page/pagemeta"
 	"github.com/gohugoio/hugo/tpl"
@@ -73,6 +75,19 @@ type HugoSites struct {
 	// Render output formats for all sites.
 	renderFormats output.Formats
 
+	// Set when build.incremental is enabled and this is not the dev server.
+	incremental *incrementalBuild
+
+	// The redirect maps by publish root, see RedirectMap.
+	redirectMapsMu sync.RWMutex
+	redirectMaps   map[string]*redirects.Map
+
+	// The URLs handled in the server's render on demand mode.
+	renderedOnDemand renderedOnDemand
+
+	// The content adapters and the pages they created, for rebuilds.
+	contentAdapters contentAdapters
+
 	*deps.Deps
 
 	gitInfo       *gitInfo
@@ -707,6 +722,10 @@ type BuildCfg struct {
 	// Recently visited URLs. This is used for partial re-rendering.
 	RecentlyVisited map[string]bool
 
+	// Set in the server's render on demand mode, where only the pages in
+	// RecentlyVisited, or owning a pager in it, are rendered, none if empty.
+	RenderOnDemand bool
+
 	// Can be set to build only with a sub set of the content source.
 	ContentInclusionFilter *glob.FilenameFilter
 
@@ -718,10 +737,15 @@ type BuildCfg struct {
 
 // shouldRender is used in the Fast Render Mode to determine if we need to re-render
 // a Page: If it is recently visited (the home pages will always be in this set) or changed.
+// In render on demand mode, only the recently visited pages and pagers are rendered.
 // Note that a page does not have to have a content page / file.
 // For regular builds, this will allways return true.
 // TODO(bep) rename/work this.
 func (cfg *BuildCfg) shouldRender(p *pageState) bool {
+	if cfg.RenderOnDemand {
+		return cfg.RecentlyVisited[p.RelPermalink()] || p.isPagerIn(cfg.RecentlyVisited)
+	}
+
 	if p.forceRender {
 		return true
 	}
@@ -762,8 +786,85 @@ func (h *HugoSites) renderCrossSitesSitemap() error {
 
 	templ := s.lookupLayouts("sitemapindex.xml", "_default/sitemapindex.xml", "_internal/_default/sitemapindex.xml")
 
+	var d interface{} = h.toSiteInfos()
+
+	// A sitemap index cannot list other sitemap indexes,
+	// so list the files of any split sitemap directly.
+	var split bool
+	for _, site := range h.Sites {
+		if site.sitemapShards != nil {
+			split = true
+			break
+		}
+	}
+	if split {
+		var entries []sitemapIndexEntry
+		for _, site := range h.Sites {
+			if !site.isEnabled(kindSitemap) {
+				continue
+			}
+			if site.sitemapShards != nil {
+				entries = append(entries, site.sitemapShards...)
+				continue
+			}
+			entries = append(entries, sitemapIndexEntry{absURL: site.Info.SitemapAbsURL(), lastChange: site.Info.LastChange()})
+		}
+		d = entries
+	}
+
 	return s.renderAndWriteXML(&s.PathSpec.ProcessingStats.Sitemaps, "sitemapindex",
-		s.siteCfg.sitemap.Filename, h.toSiteInfos(), templ)
+		s.siteCfg.sitemap.Filename, d, templ)
+}
+
+// RedirectMap returns the server side redirects for the given publish root,
+// the language code in multihost mode, else "". It returns nil before the
+// first build.
+func (h *HugoSites) RedirectMap(root string) *redirects.Map {
+	h.redirectMapsMu.RLock()
+	defer h.redirectMapsMu.RUnlock()
+	return h.redirectMaps[root]
+}
+
+// renderRedirectMaps builds the redirect maps from the redirects config and
+// the page aliases, and publishes them in the configured formats. There is
+// one redirect map per language in multihost mode, else one for all
+// languages with the redirects config of all languages merged.
+func (h *HugoSites) renderRedirectMaps() error {
+	redirectMaps := make(map[string]*redirects.Map)
+
+	publish := func(s *Site, root string, configRedirects, aliasRedirects []redirects.Redirect) error {
+		// Redirects in config win over the page aliases.
+		m := redirects.NewMap(append(append([]redirects.Redirect(nil), configRedirects...), aliasRedirects...))
+		redirectMaps[root] = m
+		return s.publishRedirectMap(root, m)
+	}
+
+	if h.multihost {
+		for _, s := range h.Sites {
+			if err := publish(s, s.language.Lang, s.siteCfg.redirects, s.aliasRedirects); err != nil {
+				return err
+			}
+		}
+	} else {
+		first := h.Sites[0]
+		var configRedirects, aliasRedirects []redirects.Redirect
+		for _, s := range h.Sites {
+			if !reflect.DeepEqual(s.siteCfg.redirectMap, first.siteCfg.redirectMap) {
+				return errors.Errorf("the redirectMap config for language %q differs from language %q: it can only be set per language in multihost mode", s.language.Lang, first.language.Lang)
+			}
+			configRedirects = append(configRedirects, s.siteCfg.redirects...)
+			aliasRedirects = append(aliasRedirects, s.aliasRedirects...)
+		}
+		if err := publish(first, "", configRedirects, aliasRedirects); err != nil {
+			return err
+		}
+	}
+
+	h.redirectMapsMu.Lock()
+	h.redirectMaps = redirectMaps
+	h.redirectMapsMu.Unlock()
+
+	return nil
 }
 
 func (h *HugoSites) renderCrossSitesRobotsTXT() error {
@@ -90,6 +90,11 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 	var prepareErr error
 
 	if !config.PartialReRender {
+		if len(events) == 0 {
+			// Rebuilds invalidate what they change, see processPartial.
+			h.resetRenderedOnDemand()
+		}
+
 		prepare := func() error {
 			init := func(conf *BuildCfg) error {
 				for _, s := range h.Sites {
@@ -185,6 +190,12 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 		return fmt.Errorf("logged %d error(s)", errorCount)
 	}
 
+	if h.incremental != nil && !config.PartialReRender {
+		if err := h.incremental.save(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -273,7 +284,7 @@ func (h *HugoSites) render(config *BuildCfg) error {
 		return err
 	}
 
-	siteRenderContext := &siteRenderContext{cfg: config, multihost: h.multihost}
+	siteRenderContext := &siteRenderContext{cfg: config, multihost: h.multihost, pagerPaths: &pagerPaths{}}
 
 	if !config.PartialReRender {
 		h.renderFormats = output.Formats{}
@@ -285,6 +296,15 @@ func (h *HugoSites) render(config *BuildCfg) error {
 		for _, s := range h.Sites {
 			h.renderFormats = append(h.renderFormats, s.renderFormats...)
 		}
+
+		h.incremental = nil
+		if !h.running && h.ResourceSpec.BuildConfig.Incremental {
+			ib, err := h.newIncrementalBuild()
+			if err != nil {
+				return err
+			}
+			h.incremental = ib
+		}
 	}
 
 	i := 0
@@ -332,6 +352,13 @@ func (h *HugoSites) render(config *BuildCfg) error {
 		if err := h.renderCrossSitesRobotsTXT(); err != nil {
 			return err
 		}
+		if err := h.renderRedirectMaps(); err != nil {
+			return err
+		}
+	}
+
+	if config.RenderOnDemand && config.PartialReRender {
+		h.addNotFoundOnDemand(config.RecentlyVisited)
 	}
 
 	return nil
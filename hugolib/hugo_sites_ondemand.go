@@ -0,0 +1,184 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/hugofs/files"
+	"github.com/gohugoio/hugo/identity"
+	"github.com/gohugoio/hugo/resources/page"
+)
+
+var pagerNumberRe = regexp.MustCompile(`\d+`)
+
+// renderedOnDemand keeps track of the URLs handled in the server's render on
+// demand mode.
+type renderedOnDemand struct {
+	mu sync.Mutex
+
+	// Maps a URL to the page rendered to it, nil if no page was found.
+	urls map[string]*pageState
+}
+
+// IsRenderedOnDemand reports whether url has been handled in render on demand
+// mode and is still up to date, also when no page is published to url.
+func (h *HugoSites) IsRenderedOnDemand(url string) bool {
+	h.renderedOnDemand.mu.Lock()
+	defer h.renderedOnDemand.mu.Unlock()
+	_, found := h.renderedOnDemand.urls[url]
+	return found
+}
+
+// HasOutputFormatSuffix reports whether url has the suffix of any of the
+// output formats rendered, e.g. /posts/index.xml for RSS, and may be
+// published by a page.
+func (h *HugoSites) HasOutputFormatSuffix(url string) bool {
+	suffix := strings.TrimPrefix(path.Ext(url), ".")
+	if suffix == "" {
+		return false
+	}
+	for _, f := range h.renderFormats {
+		for _, s := range f.MediaType.Suffixes() {
+			if strings.EqualFold(s, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addRenderedOnDemand marks the URLs of p, including its pagers, in the
+// current output format as rendered.
+func (h *HugoSites) addRenderedOnDemand(p *pageState) {
+	urls := []string{p.RelPermalink()}
+	if p.paginator != nil && p.paginator.current != nil {
+		for i := 1; i <= p.paginator.current.TotalPages(); i++ {
+			urls = append(urls, p.pagerRelPermalink(i))
+		}
+	}
+
+	h.renderedOnDemand.mu.Lock()
+	defer h.renderedOnDemand.mu.Unlock()
+	if h.renderedOnDemand.urls == nil {
+		h.renderedOnDemand.urls = make(map[string]*pageState)
+	}
+	for _, u := range urls {
+		h.renderedOnDemand.urls[u] = p
+	}
+}
+
+// addNotFoundOnDemand marks the URLs not rendered by any page as handled.
+func (h *HugoSites) addNotFoundOnDemand(urls map[string]bool) {
+	h.renderedOnDemand.mu.Lock()
+	defer h.renderedOnDemand.mu.Unlock()
+	if h.renderedOnDemand.urls == nil {
+		h.renderedOnDemand.urls = make(map[string]*pageState)
+	}
+	for u := range urls {
+		if _, found := h.renderedOnDemand.urls[u]; !found {
+			h.renderedOnDemand.urls[u] = nil
+		}
+	}
+}
+
+func (h *HugoSites) resetRenderedOnDemand() {
+	h.renderedOnDemand.mu.Lock()
+	h.renderedOnDemand.urls = nil
+	h.renderedOnDemand.mu.Unlock()
+}
+
+// resetRenderedOnDemandFromEvents marks the URLs of the pages affected by a
+// content change as stale: the changed pages, the pages depending on the
+// changed identities, and the list pages. URLs without a page are also marked
+// as stale, as the change may have added one.
+func (h *HugoSites) resetRenderedOnDemandFromEvents(idset identity.Identities, filenames map[string]bool) {
+	h.renderedOnDemand.mu.Lock()
+	defer h.renderedOnDemand.mu.Unlock()
+	for u, p := range h.renderedOnDemand.urls {
+		if p == nil || p.IsNode() || p.isChangedBy(idset, filenames) {
+			delete(h.renderedOnDemand.urls, u)
+		}
+	}
+}
+
+// isChangedBy reports whether the source of p, or any of its bundled files,
+// is in filenames, or its content depends on any identity in idset.
+func (p *pageState) isChangedBy(idset identity.Identities, filenames map[string]bool) bool {
+	if !p.File().IsZero() {
+		filename := p.File().Filename()
+		if filenames[filename] {
+			return true
+		}
+		if p.m.bundleType == files.ContentClassLeaf {
+			dir := filepath.Dir(filename) + helpers.FilePathSeparator
+			for f := range filenames {
+				if strings.HasPrefix(f, dir) {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, po := range p.pageOutputs {
+		if po.cp == nil {
+			continue
+		}
+		for id := range idset {
+			if po.cp.dependencyTracker.Search(id) != nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isPagerIn reports whether any of urls is a pager of p in the current output
+// format. The pager paths may be configured per page, so we match against
+// the pager URLs of p for the numbers found in the URL.
+func (p *pageState) isPagerIn(urls map[string]bool) bool {
+	if !p.IsNode() {
+		return false
+	}
+
+	for u := range urls {
+		for _, s := range pagerNumberRe.FindAllString(u, -1) {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				continue
+			}
+			if p.pagerRelPermalink(n) == u {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// pagerRelPermalink returns the relative permalink of pager number n of p in
+// the current output format. For the first pager this is the path of its
+// alias, unless it has its own URL.
+func (p *pageState) pagerRelPermalink(n int) string {
+	d := p.targetPathDescriptor
+	d.Type = p.outputFormat()
+	return page.CreateTargetPaths(d.ForPager(n)).RelPermalink(d.PathSpec)
+}
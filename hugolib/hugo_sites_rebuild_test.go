@@ -14,6 +14,7 @@
 package hugolib
 
 import (
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -314,3 +315,158 @@ P5 changed content
 P6 changed content
 `)
 }
+
+func TestSitesRenderOnDemand(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+disableKinds = ["taxonomy", "term", "sitemap", "robotsTXT", "404"]
+[outputs]
+home = ["HTML", "RSS", "JSON"]
+-- content/p1.md --
+---
+title: "P1"
+---
+-- content/p2.md --
+---
+title: "P2"
+---
+-- layouts/_default/single.html --
+Single: {{ .Title }}
+-- layouts/index.html --
+Home.
+-- layouts/index.rss.xml --
+RSS.
+-- layouts/index.json --
+{"home": true}
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:              t,
+			TxtarString:    files,
+			Running:        true,
+			RenderOnDemand: true,
+		},
+	).Build()
+
+	b.AssertRenderCountPage(0)
+	b.AssertDestinationExists("public/index.html", false)
+	b.AssertDestinationExists("public/p1/index.html", false)
+
+	render := func(urls ...string) {
+		visited := make(map[string]bool)
+		for _, u := range urls {
+			visited[u] = true
+		}
+		b.Assert(b.H.Build(BuildCfg{PartialReRender: true, RenderOnDemand: true, RecentlyVisited: visited}), qt.IsNil)
+	}
+
+	render("/p1/")
+	b.AssertFileContent("public/p1/index.html", "Single: P1")
+	b.AssertDestinationExists("public/p2/index.html", false)
+	b.AssertDestinationExists("public/index.html", false)
+
+	render("/index.xml")
+	b.AssertFileContent("public/index.xml", "RSS.")
+	b.AssertDestinationExists("public/index.html", false)
+
+	render("/index.json")
+	b.AssertFileContent("public/index.json", `{"home": true}`)
+	b.AssertDestinationExists("public/index.html", false)
+
+	// Requests for other output formats than HTML must also trigger a render.
+	b.Assert(b.H.HasOutputFormatSuffix("/posts/index.xml"), qt.Equals, true)
+	b.Assert(b.H.HasOutputFormatSuffix("/index.json"), qt.Equals, true)
+	b.Assert(b.H.HasOutputFormatSuffix("/css/styles.css"), qt.Equals, false)
+	b.Assert(b.H.HasOutputFormatSuffix("/posts/"), qt.Equals, false)
+}
+
+func TestSitesRenderOnDemandPagersAndChanges(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+disableKinds = ["taxonomy", "term", "sitemap", "robotsTXT", "404", "RSS"]
+-- content/news/_index.md --
+---
+title: "News"
+pagination:
+  pagerSize: 1
+  path: "p:number"
+---
+-- content/news/n1.md --
+---
+title: "N1"
+---
+-- content/news/n2.md --
+---
+title: "N2"
+---
+-- layouts/_default/single.html --
+Single: {{ .Title }}
+-- layouts/_default/list.html --
+List: {{ range .Paginator.Pages }}{{ .Title }}|{{ end }}
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:              t,
+			TxtarString:    files,
+			Running:        true,
+			RenderOnDemand: true,
+		},
+	).Build()
+
+	render := func(urls ...string) {
+		visited := make(map[string]bool)
+		for _, u := range urls {
+			visited[u] = true
+		}
+		b.Assert(b.H.Build(BuildCfg{PartialReRender: true, RenderOnDemand: true, RecentlyVisited: visited}), qt.IsNil)
+	}
+
+	rendered := func(url string, want bool) {
+		b.Helper()
+		b.Assert(b.H.IsRenderedOnDemand(url), qt.Equals, want, qt.Commentf("%s", url))
+	}
+
+	// A pager with a path set in the section's pagination config.
+	render("/news/p2/")
+	b.AssertFileContent("public/news/p2/index.html", "List: N2|")
+	b.AssertFileContent("public/news/index.html", "List: N1|")
+	b.AssertDestinationExists("public/news/n1/index.html", false)
+	rendered("/news/", true)
+	rendered("/news/p2/", true)
+	rendered("/news/n1/", false)
+
+	render("/news/n1/", "/news/n2/", "/nope/")
+	b.AssertFileContent("public/news/n1/index.html", "Single: N1")
+	rendered("/news/n1/", true)
+	rendered("/nope/", true)
+
+	// A content change only marks the changed page, the list pages and the
+	// URLs without a page as stale.
+	b.EditFileReplace("content/news/n2.md", func(s string) string {
+		return strings.Replace(s, "N2", "N2 Edited", 1)
+	}).Build()
+	b.AssertRenderCountPage(0)
+	rendered("/news/n1/", true)
+	rendered("/news/n2/", false)
+	rendered("/news/", false)
+	rendered("/news/p2/", false)
+	rendered("/nope/", false)
+
+	render("/news/n2/")
+	b.AssertFileContent("public/news/n2/index.html", "Single: N2 Edited")
+
+	// Any other change marks all pages as stale.
+	b.EditFiles("layouts/_default/single.html", "Single Edited: {{ .Title }}").Build()
+	rendered("/news/n1/", false)
+	rendered("/news/n2/", false)
+}
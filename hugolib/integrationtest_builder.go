@@ -177,7 +177,7 @@ func (s *IntegrationTestBuilder) Build() *IntegrationTestBuilder {
 func (s *IntegrationTestBuilder) BuildE() (*IntegrationTestBuilder, error) {
 	s.Helper()
 	s.initBuilder()
-	err := s.build(BuildCfg{})
+	err := s.build(BuildCfg{RenderOnDemand: s.Cfg.RenderOnDemand})
 	return s, err
 }
 
@@ -436,6 +436,9 @@ type IntegrationTestConfig struct {
 	// Whether to simulate server mode.
 	Running bool
 
+	// Whether to build with BuildCfg.RenderOnDemand set.
+	RenderOnDemand bool
+
 	// Will print the log buffer after the build
 	Verbose bool
 
@@ -16,6 +16,7 @@ package hugolib
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"os"
 	"path"
 	"path/filepath"
@@ -27,6 +28,7 @@ import (
 	"github.com/gohugoio/hugo/identity"
 
 	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/gohugoio/hugo/markup/tableofcontents"
 
 	"github.com/gohugoio/hugo/tpl"
 
@@ -40,6 +42,7 @@ import (
 	"github.com/gohugoio/hugo/parser/metadecoders"
 
 	"github.com/gohugoio/hugo/parser/pageparser"
+	"github.com/gohugoio/hugo/related"
 	"github.com/pkg/errors"
 
 	"github.com/gohugoio/hugo/output"
@@ -282,6 +285,49 @@ func (p *pageState) Pages() page.Pages {
 	return p.pages
 }
 
+// RelatedKeywords implements the related.Document interface needed for fast page searches.
+// Indices of type text with source set to "content" or "headings" index the
+// plain page content or the headings in the table of contents, other text
+// indices the front matter value with the index name. Indices of type
+// fragments index the headings in the table of contents.
+func (p *pageState) RelatedKeywords(cfg related.IndexConfig) ([]related.Keyword, error) {
+	if cfg.Type == related.TypeFragments {
+		return p.relatedFragments(), nil
+	}
+
+	if cfg.Type == related.TypeText {
+		switch cfg.Source {
+		case related.SourceContent:
+			return cfg.ToKeywords(p.Plain())
+		case related.SourceHeadings:
+			return cfg.ToKeywords(helpers.StripHTML(string(p.TableOfContents())))
+		}
+	}
+
+	return p.m.RelatedKeywords(cfg)
+}
+
+func (p *pageState) relatedFragments() []related.Keyword {
+	if p.pageOutput == nil || p.pageOutput.cp == nil {
+		return nil
+	}
+
+	var keywords []related.Keyword
+	var walk func(headings tableofcontents.Headings)
+	walk = func(headings tableofcontents.Headings) {
+		for _, h := range headings {
+			if h.ID != "" {
+				title := html.UnescapeString(helpers.StripHTML(h.Text))
+				keywords = append(keywords, related.Fragment{ID: h.ID, Title: title})
+			}
+			walk(h.Headings)
+		}
+	}
+	walk(p.pageOutput.cp.Headings())
+
+	return keywords
+}
+
 // RawContent returns the un-rendered source content without
 // any leading front matter.
 func (p *pageState) RawContent() string {
@@ -443,6 +489,10 @@ func (p *pageState) resolveTemplate(layouts ...string) (tpl.Template, bool, erro
 
 	d := p.getLayoutDescriptor()
 
+	// The search index is built by Hugo unless there is a template for
+	// the output format, e.g. index.searchindex.json.
+	d.OutputFormatOnly = strings.EqualFold(f.Name, output.SearchIndexFormat.Name)
+
 	if len(layouts) > 0 {
 		d.Layout = layouts[0]
 		d.LayoutOverride = true
@@ -19,6 +19,7 @@ import (
 	"github.com/bep/gitmap"
 	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/compare"
+	"github.com/gohugoio/hugo/identity"
 	"github.com/gohugoio/hugo/lazy"
 	"github.com/gohugoio/hugo/navigation"
 	"github.com/gohugoio/hugo/output"
@@ -136,6 +137,10 @@ type pageCommon struct {
 
 	// Set in fast render mode to force render a given page.
 	forceRender bool
+
+	// Records the templates and pages used to render this page.
+	// Set for regular pages when build.incremental is enabled.
+	dependencies identity.Manager
 }
 
 func (p *pageCommon) Store() *maps.Scratch {
@@ -119,6 +119,9 @@ type pageMeta struct {
 	// Sitemap overrides from front matter.
 	sitemap config.Sitemap
 
+	// The site pagination config with any overrides from front matter.
+	pagination page.PaginationConfig
+
 	s *Site
 
 	renderingConfigOverrides map[string]interface{}
@@ -539,6 +542,15 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 			p.m.sitemap = config.DecodeSitemap(p.s.siteCfg.sitemap, maps.ToStringMap(v))
 			pm.params[loki] = p.m.sitemap
 			sitemapSet = true
+		case "pagination":
+			if m, ok := page.ToPaginationConfigMap(v); ok {
+				pagination, err := page.DecodePaginationConfig(p.s.siteCfg.pagination, m)
+				if err != nil {
+					return errors.Wrap(err, "failed to decode pagination config in front matter")
+				}
+				pm.pagination = pagination
+			}
+			pm.params[loki] = v
 		case "iscjklanguage":
 			isCJKLanguage = new(bool)
 			*isCJKLanguage = cast.ToBool(v)
@@ -690,6 +702,10 @@ func (p *pageMeta) applyDefaultValues(n *contentNode) error {
 		(&p.buildConfig).Disable()
 	}
 
+	if p.pagination == (page.PaginationConfig{}) {
+		p.pagination = p.s.siteCfg.pagination
+	}
+
 	if p.markup == "" {
 		if !p.File().IsZero() {
 			// Fall back to file extension
@@ -44,7 +44,7 @@ func (p *pagePaginator) reset() {
 func (p *pagePaginator) Paginate(seq interface{}, options ...interface{}) (*page.Pager, error) {
 	var initErr error
 	p.init.Do(func() {
-		pagerSize, err := page.ResolvePagerSize(p.source.s.Cfg, options...)
+		pagerSize, err := page.ResolvePagerSize(p.source.m.pagination, options...)
 		if err != nil {
 			initErr = err
 			return
@@ -71,7 +71,7 @@ func (p *pagePaginator) Paginate(seq interface{}, options ...interface{}) (*page
 func (p *pagePaginator) Paginator(options ...interface{}) (*page.Pager, error) {
 	var initErr error
 	p.init.Do(func() {
-		pagerSize, err := page.ResolvePagerSize(p.source.s.Cfg, options...)
+		pagerSize, err := page.ResolvePagerSize(p.source.m.pagination, options...)
 		if err != nil {
 			initErr = err
 			return
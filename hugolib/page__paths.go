@@ -132,6 +132,7 @@ func createTargetPathDescriptor(s *Site, p page.Page, pm *pageMeta) (page.Target
 		ForcePrefix: s.h.IsMultihost() || alwaysInSubDir,
 		Dir:         dir,
 		URL:         pm.urlPaths.URL,
+		Pagination:  pm.pagination,
 	}
 
 	if pm.Slug() != "" {
@@ -143,12 +144,13 @@ func createTargetPathDescriptor(s *Site, p page.Page, pm *pageMeta) (page.Target
 	desc.PrefixFilePath = s.getLanguageTargetPathLang(alwaysInSubDir)
 	desc.PrefixLink = s.getLanguagePermalinkLang(alwaysInSubDir)
 
-	// Expand only page.KindPage and page.KindTaxonomy; don't expand other Kinds of Pages
-	// like page.KindSection or page.KindTaxonomyTerm because they are "shallower" and
-	// the permalink configuration values are likely to be redundant, e.g.
-	// naively expanding /category/:slug/ would give /category/categories/ for
-	// the "categories" page.KindTaxonomyTerm.
-	if p.Kind() == page.KindPage || p.Kind() == page.KindTerm {
+	// Expand only page.KindPage, page.KindTerm and page.KindTaxonomy; don't expand other Kinds of Pages
+	// like page.KindSection because they are "shallower" and
+	// the permalink configuration values are likely to be redundant.
+	// page.KindTaxonomy is only expanded with the patterns in permalinks.taxonomy,
+	// as naively expanding /category/:slug/ would give /category/categories/ for
+	// the "categories" page.KindTaxonomy.
+	if p.Kind() == page.KindPage || p.Kind() == page.KindTerm || p.Kind() == page.KindTaxonomy {
 		opath, err := d.ResourceSpec.Permalinks.Expand(p.Section(), p)
 		if err != nil {
 			return desc, err
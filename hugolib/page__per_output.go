@@ -32,6 +32,7 @@ import (
 	"github.com/gohugoio/hugo/markup/converter/hooks"
 
 	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/gohugoio/hugo/markup/tableofcontents"
 
 	"github.com/alecthomas/chroma/lexers"
 	"github.com/gohugoio/hugo/lazy"
@@ -129,6 +130,7 @@ func newPageContentOutput(p *pageState, po *pageOutput) (*pageContentOutput, err
 
 			if tocProvider, ok := r.(converter.TableOfContentsProvider); ok {
 				cfg := p.s.ContentSpec.Converters.GetMarkupConfig()
+				cp.headings = tocProvider.TableOfContents().Headings
 				cp.tableOfContents = template.HTML(
 					tocProvider.TableOfContents().ToHTML(
 						cfg.TableOfContents.StartLevel,
@@ -247,6 +249,9 @@ type pageContentOutput struct {
 	summary         template.HTML
 	tableOfContents template.HTML
 
+	// The headings in the ToC, if provided by the content converter.
+	headings tableofcontents.Headings
+
 	truncated bool
 
 	plainWords     []string
@@ -316,6 +321,12 @@ func (p *pageContentOutput) TableOfContents() template.HTML {
 	return p.tableOfContents
 }
 
+// Headings returns the headings in the table of contents.
+func (p *pageContentOutput) Headings() tableofcontents.Headings {
+	p.p.s.initInit(p.initMain, p.p)
+	return p.headings
+}
+
 func (p *pageContentOutput) Truncated() bool {
 	if p.p.truncated {
 		return true
@@ -471,6 +482,12 @@ func (p *pageContentOutput) initRenderHooks() error {
 				layoutDescriptor.Kind = "render-image"
 			case hooks.HeadingRendererType:
 				layoutDescriptor.Kind = "render-heading"
+			case hooks.TableRendererType:
+				layoutDescriptor.Kind = "render-table"
+			case hooks.BlockquoteRendererType:
+				layoutDescriptor.Kind = "render-blockquote"
+			case hooks.PassthroughRendererType:
+				layoutDescriptor.Kind = "render-passthrough"
 			case hooks.CodeBlockRendererType:
 				layoutDescriptor.Kind = "render-codeblock"
 				if id != nil {
@@ -645,7 +662,7 @@ func (t targetPathsHolder) targetPaths() page.TargetPaths {
 func executeToString(h tpl.TemplateHandler, templ tpl.Template, data interface{}) (string, error) {
 	b := bp.GetBuffer()
 	defer bp.PutBuffer(b)
-	if err := h.Execute(templ, b, data); err != nil {
+	if err := h.ExecuteWithContext(dependencyContext(data), templ, b, data); err != nil {
 		return "", err
 	}
 	return b.String(), nil
@@ -147,3 +147,51 @@ Some content.
 	b.AssertFileContent("public/myblog/p2/index.html", "Single: A page|Hello|en|RelPermalink: /myblog/p2/|Permalink: https://example.com/myblog/p2/|")
 	b.AssertFileContent("public/myblog/p3/index.html", "Single: A page|Hello|en|RelPermalink: /myblog/p3/|Permalink: https://example.com/myblog/p3/|")
 }
+
+func TestPermalinkTokensParamsTermsAndContentPath(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["RSS", "sitemap", "robotsTXT", "404"]
+[permalinks]
+docs = "/:params.product/:tags[0]/:slug/"
+guides = "/g/:sections[1:]/:contentbasename/"
+blog = "/:contentdir/:contentbasename/"
+-- content/docs/tls.md --
+---
+title: "Configuring TLS"
+slug: "tls"
+product: "Hugo Server"
+tags: ["Security", "Hosting"]
+---
+-- content/guides/deploy/_index.md --
+---
+title: "Deploy"
+---
+-- content/guides/deploy/cdn/index.md --
+---
+title: "CDN"
+---
+-- content/blog/2021/post.en.md --
+---
+title: "Post"
+---
+-- layouts/_default/single.html --
+RelPermalink: {{ .RelPermalink }}|
+-- layouts/_default/list.html --
+List.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/hugo-server/security/tls/index.html", "RelPermalink: /hugo-server/security/tls/|")
+	b.AssertFileContent("public/g/deploy/cdn/index.html", "RelPermalink: /g/deploy/cdn/|")
+	b.AssertFileContent("public/blog/2021/post/index.html", "RelPermalink: /blog/2021/post/|")
+}
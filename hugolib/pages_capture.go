@@ -39,12 +39,14 @@ const (
 )
 
 func newPagesCollector(
+	h *HugoSites,
 	sp *source.SourceSpec,
 	contentMap *pageMaps,
 	logger loggers.Logger,
 	contentTracker *contentChangeMap,
 	proc pagesCollectorProcessorProvider, filenames ...string) *pagesCollector {
 	return &pagesCollector{
+		h:          h,
 		fs:         sp.SourceFs,
 		contentMap: contentMap,
 		proc:       proc,
@@ -79,6 +81,7 @@ func (b *fileinfoBundle) containsResource(name string) bool {
 type pageBundles map[string]*fileinfoBundle
 
 type pagesCollector struct {
+	h      *HugoSites
 	sp     *source.SourceSpec
 	fs     afero.Fs
 	logger loggers.Logger
@@ -441,11 +444,33 @@ func (c *pagesCollector) collectDir(dirname string, partial bool, inFilter func(
 			}
 		}
 
+		// Content adapters create pages, and are never bundled resources
+		// except in leaf bundles.
+		var adapters []hugofs.FileMetaInfo
+		if btype != bundleLeaf {
+			n := 0
+			for _, fi := range readdir {
+				if isContentAdapter(fi) {
+					adapters = append(adapters, fi)
+				} else {
+					readdir[n] = fi
+					n++
+				}
+			}
+			readdir = readdir[:n]
+		}
+
 		err := handleDir(btype, dir, path, readdir)
 		if err != nil {
 			return nil, err
 		}
 
+		for _, fi := range adapters {
+			if err := c.handleContentAdapter(fi); err != nil {
+				return nil, err
+			}
+		}
+
 		if btype == bundleLeaf || partial {
 			return nil, filepath.SkipDir
 		}
@@ -16,6 +16,7 @@ package hugolib
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -136,3 +137,153 @@ weight: %d
 	b.Assert(b.CheckExists("public/page/1/index.json"), qt.Equals, false)
 	b.AssertFileContent("public/page/2/index.json", `JSON: 22: |/p11/index.json|/p12/index.json`)
 }
+
+func TestPaginatorConfigCascade(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["RSS", "sitemap", "robotsTXT", "404"]
+paginate = 3
+-- content/news/_index.md --
+---
+title: "News"
+cascade:
+  pagination:
+    pagerSize: 2
+    path: "/:section/p:number/"
+    firstPageOwnURL: true
+---
+-- content/tags/_index.md --
+---
+title: "Tags"
+cascade:
+  pagination:
+    pagerSize: 1
+    path: "page-:number"
+---
+-- content/news/n1.md --
+---
+title: "N1"
+tags: ["a"]
+---
+-- content/news/n2.md --
+---
+title: "N2"
+tags: ["a"]
+---
+-- content/news/n3.md --
+---
+title: "N3"
+---
+-- content/blog/_index.md --
+---
+title: "Blog"
+pagination:
+  style: "dots"
+---
+-- content/blog/b1.md --
+---
+title: "B1"
+---
+-- content/blog/b2.md --
+---
+title: "B2"
+---
+-- content/blog/b3.md --
+---
+title: "B3"
+---
+-- content/blog/b4.md --
+---
+title: "B4"
+---
+-- layouts/_default/single.html --
+{{ .Title }}
+-- layouts/_default/list.html --
+{{ $pag := .Paginator }}
+Pages: {{ range $pag.Pages }}{{ .Title }}|{{ end }}END
+Size: {{ $pag.PageSize }}
+URL: {{ $pag.URL }}
+First: {{ $pag.First.URL }}
+Next: {{ with $pag.Next }}{{ .URL }}{{ end }}END
+Style: {{ .Params.pagination.style }}|
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/news/index.html", "Size: 2", "URL: /news/p1/", "Next: /news/p2/END")
+	b.AssertFileContent("public/news/p1/index.html", "Size: 2", "URL: /news/p1/")
+	b.AssertFileContent("public/news/p2/index.html", "Size: 2", "URL: /news/p2/", "First: /news/p1/", "Next: END")
+
+	// Not a pagination config, kept as a param.
+	b.AssertFileContent("public/blog/index.html", "Size: 3", "URL: /blog/", "Next: /blog/page/2/END", "Style: dots|")
+	b.AssertFileContent("public/blog/page/1/index.html", `<meta http-equiv="refresh" content="0; url=https://example.com/blog/"`)
+	b.AssertFileContent("public/blog/page/2/index.html", "Pages: B4|END")
+
+	b.AssertFileContent("public/tags/a/index.html", "Size: 1", "Next: /tags/a/page-2/END")
+	b.AssertFileContent("public/tags/a/page-2/index.html", "Size: 1", "URL: /tags/a/page-2/")
+}
+
+func TestPaginatorConfigRootPathTerms(t *testing.T) {
+	t.Parallel()
+
+	filesTemplate := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["RSS", "sitemap", "robotsTXT", "404"]
+-- content/tags/_index.md --
+---
+title: "Tags"
+cascade:
+  pagination:
+    pagerSize: 1
+    path: "PATH"
+---
+-- content/p1.md --
+---
+title: "P1"
+tags: ["a", "b"]
+---
+-- content/p2.md --
+---
+title: "P2"
+tags: ["a", "b"]
+---
+-- layouts/_default/single.html --
+{{ .Title }}
+-- layouts/_default/list.html --
+{{ $pag := .Paginator }}
+Pages: {{ range $pag.Pages }}{{ .Title }}|{{ end }}END
+Next: {{ with $pag.Next }}{{ .URL }}{{ end }}END
+`
+
+	// All terms would write their pagers to /tags/p2/.
+	b, err := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: strings.ReplaceAll(filesTemplate, "PATH", "/:section/p:number/"),
+		},
+	).BuildE()
+
+	b.Assert(err, qt.Not(qt.IsNil))
+	b.Assert(err.Error(), qt.Contains, `pagination path "/:section/p:number/" gives the same pager path "/tags/p2/"`)
+
+	b = NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: strings.ReplaceAll(filesTemplate, "PATH", "/:sections/p:number/"),
+		},
+	).Build()
+
+	b.AssertFileContent("public/tags/a/index.html", "Next: /tags/a/p2/END")
+	b.AssertFileContent("public/tags/a/p2/index.html", "Pages: P2|END")
+	b.AssertFileContent("public/tags/b/index.html", "Next: /tags/b/p2/END")
+	b.AssertFileContent("public/tags/b/p2/index.html", "Pages: P2|END")
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestRelatedTextIndex(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+[related]
+threshold = 20
+includeNewer = true
+[[related.indices]]
+name = "content"
+type = "text"
+source = "content"
+weight = 100
+[[related.indices]]
+name = "headings"
+type = "text"
+source = "headings"
+weight = 50
+-- content/p1.md --
+---
+title: "P1"
+date: 2021-01-01
+---
+## Deploying
+
+Deploying a Hugo site to a CDN is fast. The CDN caches the site.
+-- content/p2.md --
+---
+title: "P2"
+date: 2021-01-02
+---
+## Deploying to a CDN
+
+A CDN caches your site close to your readers. Deploy it often.
+-- content/p3.md --
+---
+title: "P3"
+date: 2021-01-03
+---
+## Recipes
+
+Cooking pasta in salted water takes ten minutes.
+-- content/p4.md --
+---
+title: "P4"
+date: 2021-01-04
+---
+## Recipes
+
+Pasta with tomato sauce.
+-- layouts/_default/single.html --
+{{ .Content }}
+Related: {{ range .Site.RegularPages.Related . }}{{ .Title }}|{{ end }}END
+Headings: {{ range .Site.RegularPages.RelatedIndices . "headings" }}{{ .Title }}|{{ end }}END
+RelatedTo: {{ range .Site.RegularPages.RelatedTo (keyVals "content" "pasta sauce") }}{{ .Title }}|{{ end }}END
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html", "Related: P2|END", "Headings: P2|END", "RelatedTo: P4|P3|END")
+	b.AssertFileContent("public/p3/index.html", "Related: P4|END", "Headings: P4|END")
+}
+
+func TestRelatedFragments(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+[related]
+threshold = 20
+includeNewer = true
+[[related.indices]]
+name = "tags"
+weight = 100
+[[related.indices]]
+name = "fragments"
+type = "fragments"
+weight = 80
+-- content/hosting.md --
+---
+title: "Hosting"
+date: 2021-01-01
+tags: ["Configuring TLS"]
+---
+## Hosting
+-- content/server.md --
+---
+title: "Server"
+date: 2021-01-02
+---
+## Flags
+
+## Configuring TLS
+
+### Certificates and Keys
+-- content/keys.md --
+---
+title: "Keys"
+date: 2021-01-03
+tags: ["Certificates and keys"]
+---
+-- layouts/_default/single.html --
+Related: {{ range .Site.RegularPages.Related . }}{{ .Title }}|{{ end }}END
+Fragments: {{ range .Site.RegularPages.RelatedFragments . }}{{ .RelPermalink }}#{{ .Fragment.ID }}:{{ .Fragment.Title }}|{{ end }}END
+Tags: {{ range .Site.RegularPages.RelatedFragments . "tags" }}{{ .Title }}:{{ len .Fragments }}|{{ end }}END
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/hosting/index.html",
+		"Related: Server|END",
+		"Fragments: /server/#configuring-tls:Configuring TLS|END",
+		"Tags: END",
+	)
+	b.AssertFileContent("public/keys/index.html", "Fragments: /server/#certificates-and-keys:Certificates and Keys|END")
+}
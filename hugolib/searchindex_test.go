@@ -0,0 +1,140 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugolib
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestSearchIndex(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+defaultContentLanguage = "en"
+[outputs]
+home = ["HTML", "JSON", "SearchIndex"]
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+[languages.fr.searchIndex]
+stopWords = ["le"]
+-- content/hosting.md --
+---
+title: "Hosting"
+tags: ["servers"]
+---
+Hosting your site is easy.
+-- content/hosting.fr.md --
+---
+title: "Hébergement"
+---
+Le hébergement du site.
+-- layouts/index.html --
+Home.
+-- layouts/index.json.json --
+{"title": {{ .Title | jsonify }}}
+-- layouts/_default/single.html --
+{{ .Content }}
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/index.json", `{"title": ""}`)
+	b.AssertFileContent("public/searchindex.json",
+		`"lang":"en","stemmer":"en"`,
+		`"shards":1,"shardPath":"searchindex/","docs":[["Hosting","/hosting/","Hosting your site is easy."]]`,
+	)
+	b.AssertFileContent("public/searchindex/0.json", `"host":[[0,`, `"server":[[0,`, `"easy":[[0,`)
+	b.AssertFileContent("public/searchindex/search.js", "HugoSearch")
+
+	b.AssertFileContent("public/fr/searchindex.json",
+		`"lang":"fr","stemmer":"","stopWords":["le"]`,
+		`"docs":[["Hébergement","/fr/hosting/","Le hébergement du site."]]`,
+	)
+	b.AssertFileContent("public/fr/searchindex/0.json", `"hébergement":[[0,`, `"du":[[0,`)
+}
+
+func TestSearchIndexTemplate(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+[outputs]
+home = ["HTML", "SearchIndex"]
+-- content/p1.md --
+---
+title: "P1"
+---
+-- layouts/index.html --
+Home.
+-- layouts/index.searchindex.json --
+[{{ range .Site.RegularPages }}{{ .Title | jsonify }}{{ end }}]
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/searchindex.json", `["P1"]`)
+	b.AssertDestinationExists("public/searchindex/search.js", false)
+}
+
+func TestSearchIndexOptIn(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS", "sitemap", "robotsTXT", "404"]
+[outputs]
+home = ["HTML", "JSON"]
+-- content/p1.md --
+---
+title: "P1"
+---
+-- layouts/index.html --
+Home.
+-- layouts/index.json --
+{"title": {{ .Title | jsonify }}}
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/index.json", `{"title": ""}`)
+	b.AssertDestinationExists("public/searchindex.json", false)
+	_, found := b.H.Sites[0].outputFormatsConfig.GetByName("SearchIndex")
+	b.Assert(found, qt.Equals, false)
+}
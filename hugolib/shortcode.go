@@ -637,7 +637,7 @@ func renderShortcodeWithPage(h tpl.TemplateHandler, tmpl tpl.Template, data *Sho
 	buffer := bp.GetBuffer()
 	defer bp.PutBuffer(buffer)
 
-	err := h.Execute(tmpl, buffer, data)
+	err := h.ExecuteWithContext(dependencyContext(data.Page), tmpl, buffer, data)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to process shortcode")
 	}
@@ -15,8 +15,14 @@ package hugolib
 
 import (
 	"html/template"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
 
+	"github.com/gohugoio/hugo/markup/converter/hooks"
 	"github.com/gohugoio/hugo/resources/page"
+	"github.com/gohugoio/hugo/resources/resource"
 )
 
 var tocShortcodePlaceholder = createShortcodePlaceholder("TOC", 0)
@@ -73,3 +79,48 @@ func newPageForRenderHook(p *pageState) page.Page {
 func (p *pageForRenderHooks) page() page.Page {
 	return p.PageWithoutContent.(page.Page)
 }
+
+// This is what is sent into the image render hook. It adds access to
+// the page resource the image destination points to.
+type imageContextForRenderHook struct {
+	hooks.ImageContext
+
+	resourceInit sync.Once
+	resource     resource.Image
+}
+
+func newImageContextForRenderHook(ctx hooks.ImageContext) *imageContextForRenderHook {
+	return &imageContextForRenderHook{ImageContext: ctx}
+}
+
+// Resource returns the image in the page bundle matching Destination,
+// or nil if none found.
+func (c *imageContextForRenderHook) Resource() resource.Image {
+	c.resourceInit.Do(func() {
+		p, ok := c.Page().(page.Page)
+		if !ok {
+			return
+		}
+		name := bundleResourceName(c.Destination())
+		if name == "" {
+			return
+		}
+		c.resource, _ = p.Resources().GetMatch(name).(resource.Image)
+	})
+	return c.resource
+}
+
+// bundleResourceName returns the name of the bundle resource that destination
+// may point to, or an empty string if destination is absolute or points outside
+// of the bundle.
+func bundleResourceName(destination string) string {
+	u, err := url.Parse(destination)
+	if err != nil || u.IsAbs() || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return ""
+	}
+	name := path.Clean(u.Path)
+	if name == "." || strings.HasPrefix(name, "../") {
+		return ""
+	}
+	return name
+}
@@ -77,8 +77,10 @@ import (
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/navigation"
 	"github.com/gohugoio/hugo/output"
+	"github.com/gohugoio/hugo/redirects"
 	"github.com/gohugoio/hugo/related"
 	"github.com/gohugoio/hugo/resources/page/pagemeta"
+	"github.com/gohugoio/hugo/searchindex"
 	"github.com/gohugoio/hugo/source"
 	"github.com/gohugoio/hugo/tpl"
 
@@ -169,6 +171,13 @@ type Site struct {
 	// The last modification date of this site.
 	lastmod time.Time
 
+	// The sitemap files written when the sitemap was split
+	// because of its size, nil if not split.
+	sitemapShards []sitemapIndexEntry
+
+	// The server side redirects for the page aliases in this site.
+	aliasRedirects []redirects.Redirect
+
 	// Lazily loaded site dependencies
 	init *siteInit
 }
@@ -194,6 +203,10 @@ func (t taxonomiesConfig) Values() []viewName {
 
 type siteConfigHolder struct {
 	sitemap          config.Sitemap
+	pagination       page.PaginationConfig
+	searchIndex      searchindex.Config
+	redirectMap      redirects.Config
+	redirects        []redirects.Redirect
 	taxonomiesConfig taxonomiesConfig
 	timeout          time.Duration
 	hasCJKLanguage   bool
@@ -496,6 +509,15 @@ But this also means that your site configuration may not do what you expect. If
 		}
 	}
 
+	if outputsHasFormat(siteOutputs, output.SearchIndexFormat.Name) {
+		if _, found := siteOutputFormatsConfig.GetByName(output.SearchIndexFormat.Name); !found {
+			// The search index is opt-in, it's only known to sites that add
+			// it to their outputs.
+			siteOutputFormatsConfig = append(siteOutputFormatsConfig, output.SearchIndexFormat)
+			sort.Sort(siteOutputFormatsConfig)
+		}
+	}
+
 	outputFormats, err := createSiteOutputFormats(siteOutputFormatsConfig, siteOutputs, rssDisabled)
 	if err != nil {
 		return nil, err
@@ -517,6 +539,29 @@ But this also means that your site configuration may not do what you expect. If
 		}
 	}
 
+	searchIndexConfig, err := searchindex.DecodeConfig(cfg.Language.GetParams("searchIndex"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode searchIndex config")
+	}
+
+	// Related indices of type text are tokenized as the search index.
+	relatedContentConfig.Tokenize = text.NewTokenizer(cfg.Language.Lang, searchIndexConfig.StopWords).Tokens
+
+	redirectMapConfig, err := redirects.DecodeConfig(cfg.Language.GetParams("redirectMap"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode redirectMap config")
+	}
+
+	redirectsConfig, err := redirects.DecodeRedirects(cfg.Language.Get("redirects"), redirectMapConfig.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	paginationConfig := page.PaginationConfig{
+		PagerSize: cfg.Language.GetInt("paginate"),
+		Path:      strings.Trim(cfg.Language.GetString("paginatePath"), "/"),
+	}
+
 	titleFunc := helpers.GetTitleFunc(cfg.Language.GetString("titleCaseStyle"))
 
 	frontMatterHandler, err := pagemeta.NewFrontmatterHandler(cfg.Logger, cfg.Cfg)
@@ -534,7 +579,11 @@ But this also means that your site configuration may not do what you expect. If
 	}
 
 	siteConfig := siteConfigHolder{
-		sitemap:          config.DecodeSitemap(config.Sitemap{Priority: -1, Filename: "sitemap.xml"}, cfg.Language.GetStringMap("sitemap")),
+		sitemap:          config.DecodeSitemap(config.Sitemap{Priority: -1, Filename: "sitemap.xml", MaxURLs: 50000}, cfg.Language.GetStringMap("sitemap")),
+		pagination:       paginationConfig,
+		searchIndex:      searchIndexConfig,
+		redirectMap:      redirectMapConfig,
+		redirects:        redirectsConfig,
 		taxonomiesConfig: taxonomies,
 		timeout:          timeout,
 		hasCJKLanguage:   cfg.Language.GetBool("hasCJKLanguage"),
@@ -1031,13 +1080,17 @@ func (s *Site) processPartial(config *BuildCfg, init func(config *BuildCfg) erro
 		sourceReallyChanged = []fsnotify.Event{}
 		contentFilesChanged []string
 
-		tmplChanged bool
-		tmplAdded   bool
-		dataChanged bool
-		i18nChanged bool
+		tmplChanged  bool
+		tmplAdded    bool
+		dataChanged  bool
+		i18nChanged  bool
+		otherChanged bool
 
 		sourceFilesChanged = make(map[string]bool)
 
+		// Relative to the assets directory.
+		assetsChanged = make(map[string]bool)
+
 		// prevent spamming the log on changes
 		logger = helpers.NewDistinctErrorLogger()
 	)
@@ -1052,6 +1105,7 @@ func (s *Site) processPartial(config *BuildCfg, init func(config *BuildCfg) erro
 
 	for _, ev := range events {
 		if assetsFilename, _ := s.BaseFs.Assets.MakePathRelative(ev.Name); assetsFilename != "" {
+			assetsChanged[strings.TrimPrefix(filepath.ToSlash(assetsFilename), "/")] = true
 			cachePartitions = append(cachePartitions, resources.ResourceKeyPartitions(assetsFilename)...)
 			if evictCSSRe == nil {
 				if cssFileRe.MatchString(assetsFilename) || cssConfigRe.MatchString(assetsFilename) {
@@ -1090,6 +1144,10 @@ func (s *Site) processPartial(config *BuildCfg, init func(config *BuildCfg) erro
 				i18nChanged = true
 
 			}
+
+			if id.Type != files.ComponentFolderContent {
+				otherChanged = true
+			}
 		}
 	}
 
@@ -1173,6 +1231,18 @@ func (s *Site) processPartial(config *BuildCfg, init func(config *BuildCfg) erro
 		sourceFilesChanged[ev.Name] = true
 	}
 
+	// Content adapters create their pages again when their source changes.
+	contentFilesChanged = append(contentFilesChanged, h.contentAdapters.withChangedSource(dataChanged, assetsChanged)...)
+
+	if config.RenderOnDemand {
+		// Only content changes can be traced to the pages they affect.
+		if config.ErrRecovery || otherChanged {
+			h.resetRenderedOnDemand()
+		} else {
+			h.resetRenderedOnDemandFromEvents(changeIdentities, sourceFilesChanged)
+		}
+	}
+
 	if config.ErrRecovery || tmplAdded || dataChanged {
 		h.resetPageState()
 	} else {
@@ -1190,6 +1260,9 @@ func (s *Site) processPartial(config *BuildCfg, init func(config *BuildCfg) erro
 
 		filenamesChanged = helpers.UniqueStringsReuse(filenamesChanged)
 
+		// Records may have been removed from the source.
+		h.removeContentAdapterPages(filenamesChanged)
+
 		if err := s.readAndProcessContent(*config, filenamesChanged...); err != nil {
 			return err
 		}
@@ -1218,16 +1291,16 @@ func (s *Site) render(ctx *siteRenderContext) (err error) {
 
 	if ctx.outIdx == 0 {
 		// Note that even if disableAliases is set, the aliases themselves are
-		// preserved on page. The motivation with this is to be able to generate
-		// 301 redirects in a .htacess file and similar using a custom output format.
-		if !s.Cfg.GetBool("disableAliases") {
-			// Aliases must be rendered before pages.
-			// Some sites, Hugo docs included, have faulty alias definitions that point
-			// to itself or another real page. These will be overwritten in the next
-			// step.
-			if err = s.renderAliases(); err != nil {
-				return
-			}
+		// preserved on page and collected for the redirect maps. The motivation
+		// with this is to be able to generate 301 redirects in a .htacess file
+		// and similar, see redirectMap, or using a custom output format.
+		//
+		// Aliases must be rendered before pages.
+		// Some sites, Hugo docs included, have faulty alias definitions that point
+		// to itself or another real page. These will be overwritten in the next
+		// step.
+		if err = s.renderAliases(); err != nil {
+			return
 		}
 	}
 
@@ -1404,7 +1477,7 @@ func (s *Site) readAndProcessContent(buildConfig BuildCfg, filenames ...string)
 
 	proc := newPagesProcessor(s.h, sourceSpec)
 
-	c := newPagesCollector(sourceSpec, s.h.getContentMaps(), s.Log, s.h.ContentChanges, proc, filenames...)
+	c := newPagesCollector(s.h, sourceSpec, s.h.getContentMaps(), s.Log, s.h.ContentChanges, proc, filenames...)
 
 	if err := c.Collect(); err != nil {
 		return err
@@ -1696,12 +1769,24 @@ func (s *Site) lookupLayouts(layouts ...string) tpl.Template {
 }
 
 func (s *Site) renderAndWriteXML(statCounter *uint64, name string, targetPath string, d interface{}, templ tpl.Template) error {
+	_, err := s.renderAndWriteXMLMaxSize(statCounter, name, targetPath, d, templ, 0)
+	return err
+}
+
+// renderAndWriteXMLMaxSize is renderAndWriteXML, but if the rendered XML is
+// larger than maxBytes, nothing is written and false is returned.
+// A zero maxBytes means no limit.
+func (s *Site) renderAndWriteXMLMaxSize(statCounter *uint64, name string, targetPath string, d interface{}, templ tpl.Template, maxBytes int) (bool, error) {
 	s.Log.Debugf("Render XML for %q to %q", name, targetPath)
 	renderBuffer := bp.GetBuffer()
 	defer bp.PutBuffer(renderBuffer)
 
 	if err := s.renderForTemplate(name, "", d, renderBuffer, templ); err != nil {
-		return err
+		return false, err
+	}
+
+	if maxBytes > 0 && renderBuffer.Len() > maxBytes {
+		return false, nil
 	}
 
 	pd := publisher.Descriptor{
@@ -1714,7 +1799,7 @@ func (s *Site) renderAndWriteXML(statCounter *uint64, name string, targetPath st
 		AbsURLPath:   s.absURLPath(targetPath),
 	}
 
-	return s.publisher.Publish(pd)
+	return true, s.publisher.Publish(pd)
 }
 
 func (s *Site) renderAndWritePage(statCounter *uint64, name string, targetPath string, p *pageState, templ tpl.Template) error {
@@ -1783,15 +1868,31 @@ type hookRendererTemplate struct {
 }
 
 func (hr hookRendererTemplate) RenderLink(w io.Writer, ctx hooks.LinkContext) error {
-	return hr.templateHandler.Execute(hr.templ, w, ctx)
+	return hr.templateHandler.ExecuteWithContext(dependencyContext(ctx.Page()), hr.templ, w, ctx)
+}
+
+func (hr hookRendererTemplate) RenderImage(w io.Writer, ctx hooks.ImageContext) error {
+	return hr.templateHandler.ExecuteWithContext(dependencyContext(ctx.Page()), hr.templ, w, newImageContextForRenderHook(ctx))
 }
 
 func (hr hookRendererTemplate) RenderHeading(w io.Writer, ctx hooks.HeadingContext) error {
-	return hr.templateHandler.Execute(hr.templ, w, ctx)
+	return hr.templateHandler.ExecuteWithContext(dependencyContext(ctx.Page()), hr.templ, w, ctx)
 }
 
 func (hr hookRendererTemplate) RenderCodeblock(w hugio.FlexiWriter, ctx hooks.CodeblockContext) error {
-	return hr.templateHandler.Execute(hr.templ, w, ctx)
+	return hr.templateHandler.ExecuteWithContext(dependencyContext(ctx.Page()), hr.templ, w, ctx)
+}
+
+func (hr hookRendererTemplate) RenderTable(w hugio.FlexiWriter, ctx hooks.TableContext) error {
+	return hr.templateHandler.ExecuteWithContext(dependencyContext(ctx.Page()), hr.templ, w, ctx)
+}
+
+func (hr hookRendererTemplate) RenderBlockquote(w hugio.FlexiWriter, ctx hooks.BlockquoteContext) error {
+	return hr.templateHandler.ExecuteWithContext(dependencyContext(ctx.Page()), hr.templ, w, ctx)
+}
+
+func (hr hookRendererTemplate) RenderPassthrough(w hugio.FlexiWriter, ctx hooks.PassthroughContext) error {
+	return hr.templateHandler.ExecuteWithContext(dependencyContext(ctx.Page()), hr.templ, w, ctx)
 }
 
 func (hr hookRendererTemplate) ResolvePosition(ctx interface{}) text.Position {
@@ -1808,7 +1909,7 @@ func (s *Site) renderForTemplate(name, outputFormat string, d interface{}, w io.
 		return nil
 	}
 
-	if err = s.Tmpl().Execute(templ, w, d); err != nil {
+	if err = s.Tmpl().ExecuteWithContext(dependencyContext(d), templ, w, d); err != nil {
 		return _errors.Wrapf(err, "render of %q failed", name)
 	}
 	return
@@ -106,3 +106,16 @@ func createSiteOutputFormats(allFormats output.Formats, outputs map[string]inter
 
 	return outFormats, nil
 }
+
+// outputsHasFormat reports whether the output format with the given name is
+// configured for any page kind in outputs.
+func outputsHasFormat(outputs map[string]interface{}, name string) bool {
+	for _, v := range outputs {
+		for _, format := range cast.ToStringSlice(v) {
+			if strings.EqualFold(format, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
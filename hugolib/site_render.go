@@ -14,12 +14,21 @@
 package hugolib
 
 import (
+	"bytes"
 	"fmt"
 	"path"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/media"
+	"github.com/gohugoio/hugo/publisher"
+	"github.com/gohugoio/hugo/redirects"
+	"github.com/gohugoio/hugo/searchindex"
 	"github.com/gohugoio/hugo/tpl"
+	"github.com/spf13/cast"
 
 	"github.com/gohugoio/hugo/config"
 
@@ -41,6 +50,31 @@ type siteRenderContext struct {
 	outIdx int
 
 	multihost bool
+
+	// The pager target filenames of the pages with root-absolute pagination
+	// paths, to detect pages writing their pagers to the same path.
+	pagerPaths *pagerPaths
+}
+
+// pagerPaths maps pager target filenames to the page owning them.
+type pagerPaths struct {
+	mu sync.Mutex
+	m  map[string]*pageState
+}
+
+// claim marks filename as owned by p and returns the other page owning it,
+// if any.
+func (pp *pagerPaths) claim(filename string, p *pageState) *pageState {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if pp.m == nil {
+		pp.m = make(map[string]*pageState)
+	}
+	if other, found := pp.m[filename]; found && other != p {
+		return other
+	}
+	pp.m[filename] = p
+	return nil
 }
 
 // Whether to render 404.html, robotsTXT.txt which usually is rendered
@@ -127,6 +161,13 @@ func pageRenderer(
 			continue
 		}
 
+		if !found && strings.EqualFold(p.outputFormat().Name, output.SearchIndexFormat.Name) {
+			if err := s.renderSearchIndex(p); err != nil {
+				results <- err
+			}
+			continue
+		}
+
 		if !found {
 			s.logMissingLayout("", p.Layout(), p.Kind(), p.f.Name)
 			continue
@@ -134,15 +175,27 @@ func pageRenderer(
 
 		targetPath := p.targetPaths().TargetFilename
 
+		if s.h.incremental != nil && s.h.incremental.skip(p, targetPath) {
+			continue
+		}
+
+		var failed bool
+
 		if err := s.renderAndWritePage(&s.PathSpec.ProcessingStats.Pages, "page "+p.Title(), targetPath, p, templ); err != nil {
 			results <- err
+			failed = true
 		}
 
 		if p.paginator != nil && p.paginator.current != nil {
-			if err := s.renderPaginator(p, templ); err != nil {
+			if err := s.renderPaginator(ctx, p, templ); err != nil {
 				results <- err
+				failed = true
 			}
 		}
+
+		if ctx.cfg.RenderOnDemand && !failed {
+			s.h.addRenderedOnDemand(p)
+		}
 	}
 }
 
@@ -181,9 +234,7 @@ func (s *Site) logMissingLayout(name, layout, kind, outputFormat string) {
 }
 
 // renderPaginator must be run after the owning Page has been rendered.
-func (s *Site) renderPaginator(p *pageState, templ tpl.Template) error {
-	paginatePath := s.Cfg.GetString("paginatePath")
-
+func (s *Site) renderPaginator(ctx *siteRenderContext, p *pageState, templ tpl.Template) error {
 	d := p.targetPathDescriptor
 	f := p.s.rc.Format
 	d.Type = f
@@ -192,10 +243,29 @@ func (s *Site) renderPaginator(p *pageState, templ tpl.Template) error {
 		panic(fmt.Sprintf("invalid paginator state for %q", p.pathOrTitle()))
 	}
 
-	if f.IsHTML {
+	if strings.HasPrefix(d.Pagination.Path, "/") && !p.IsHome() {
+		// A root-absolute path is shared by all pages using it, e.g.
+		// /:section/:number/ for all the terms in a taxonomy.
+		for i := 1; i <= p.paginator.current.TotalPages(); i++ {
+			targetPaths := page.CreateTargetPaths(d.ForPager(i))
+			if other := ctx.pagerPaths.claim(targetPaths.TargetFilename, p); other != nil {
+				return p.errorf(nil, "pagination path %q gives the same pager path %q as for %q, use a token unique to the page, e.g. :sections", d.Pagination.Path, targetPaths.RelPermalink(d.PathSpec), other.pathOrTitle())
+			}
+		}
+	}
+
+	if d.Pagination.FirstPageOwnURL {
+		targetPaths := page.CreateTargetPaths(d.ForPager(1))
+
+		if err := s.renderAndWritePage(
+			&s.PathSpec.ProcessingStats.PaginatorPages,
+			p.Title(),
+			targetPaths.TargetFilename, p, templ); err != nil {
+			return err
+		}
+	} else if f.IsHTML {
 		// Write alias for page 1
-		d.Addends = fmt.Sprintf("/%s/%d", paginatePath, 1)
-		targetPaths := page.CreateTargetPaths(d)
+		targetPaths := page.CreateTargetPaths(d.ForPager(1))
 
 		if err := s.writeDestAlias(targetPaths.TargetFilename, p.Permalink(), f, nil); err != nil {
 			return err
@@ -206,8 +276,7 @@ func (s *Site) renderPaginator(p *pageState, templ tpl.Template) error {
 	for current := p.paginator.current.Next(); current != nil; current = current.Next() {
 
 		p.paginator.current = current
-		d.Addends = fmt.Sprintf("/%s/%d", paginatePath, current.PageNumber())
-		targetPaths := page.CreateTargetPaths(d)
+		targetPaths := page.CreateTargetPaths(d.ForPager(current.PageNumber()))
 
 		if err := s.renderAndWritePage(
 			&s.PathSpec.ProcessingStats.PaginatorPages,
@@ -259,16 +328,40 @@ func (s *Site) render404() error {
 	return s.renderAndWritePage(&s.PathSpec.ProcessingStats.Pages, "404 page", targetPath, p, templ)
 }
 
-func (s *Site) renderSitemap() error {
-	p, err := newPageStandalone(&pageMeta{
+// The maximum size of a sitemap file, as defined by the Sitemap Protocol.
+// This is a variable so tests can lower it.
+var sitemapMaxBytes = 50 * 1024 * 1024
+
+// sitemapIndexEntry is a sitemap listed in a sitemap index.
+type sitemapIndexEntry struct {
+	absURL     string
+	lastChange time.Time
+}
+
+func (e sitemapIndexEntry) SitemapAbsURL() string {
+	return e.absURL
+}
+
+func (e sitemapIndexEntry) LastChange() time.Time {
+	return e.lastChange
+}
+
+func (s *Site) newSitemapPage(filename string) (*pageState, error) {
+	return newPageStandalone(&pageMeta{
 		s:    s,
 		kind: kindSitemap,
 		urlPaths: pagemeta.URLPath{
-			URL: s.siteCfg.sitemap.Filename,
+			URL: filename,
 		},
 	},
 		output.HTMLFormat,
 	)
+}
+
+func (s *Site) renderSitemap() error {
+	s.sitemapShards = nil
+
+	p, err := s.newSitemapPage(s.siteCfg.sitemap.Filename)
 	if err != nil {
 		return err
 	}
@@ -285,7 +378,133 @@ func (s *Site) renderSitemap() error {
 
 	templ := s.lookupLayouts("sitemap.xml", "_default/sitemap.xml", "_internal/_default/sitemap.xml")
 
-	return s.renderAndWriteXML(&s.PathSpec.ProcessingStats.Sitemaps, "sitemap", targetPath, p, templ)
+	if maxURLs := s.siteCfg.sitemap.MaxURLs; maxURLs <= 0 || len(p.Pages()) <= maxURLs {
+		written, err := s.renderAndWriteXMLMaxSize(&s.PathSpec.ProcessingStats.Sitemaps, "sitemap", targetPath, p, templ, sitemapMaxBytes)
+		if err != nil || written {
+			return err
+		}
+	}
+
+	// Too many URLs or too large for one sitemap.
+	if err := s.renderSitemapShards(p.Pages(), templ); err != nil {
+		return err
+	}
+	indexTempl := s.lookupLayouts("sitemapindex.xml", "_default/sitemapindex.xml", "_internal/_default/sitemapindex.xml")
+	return s.renderAndWriteXML(&s.PathSpec.ProcessingStats.Sitemaps, "sitemapindex", targetPath, s.sitemapShards, indexTempl)
+}
+
+// renderSitemapShards splits pages into sitemaps of at most sitemap.maxURLs pages
+// and at most sitemapMaxBytes bytes, named after the sitemap filename,
+// e.g. sitemap-1.xml, sitemap-2.xml.
+func (s *Site) renderSitemapShards(pages page.Pages, templ tpl.Template) error {
+	maxURLs := s.siteCfg.sitemap.MaxURLs
+	if maxURLs <= 0 {
+		maxURLs = len(pages)
+	}
+	filename := s.siteCfg.sitemap.Filename
+	ext := path.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+
+	var chunks []page.Pages
+	for i := 0; i < len(pages); i += maxURLs {
+		end := i + maxURLs
+		if end > len(pages) {
+			end = len(pages)
+		}
+		chunks = append(chunks, pages[i:end])
+	}
+
+	for len(chunks) > 0 {
+		chunk := chunks[0]
+		chunks = chunks[1:]
+
+		p, err := s.newSitemapPage(fmt.Sprintf("%s-%d%s", base, len(s.sitemapShards)+1, ext))
+		if err != nil {
+			return err
+		}
+		// Restrict the sitemap to the pages in this chunk.
+		p.pagesInit.Do(func() {
+			p.pages = chunk
+		})
+
+		maxBytes := sitemapMaxBytes
+		if len(chunk) == 1 {
+			// Can not be split any further.
+			maxBytes = 0
+		}
+
+		written, err := s.renderAndWriteXMLMaxSize(&s.PathSpec.ProcessingStats.Sitemaps, "sitemap", p.targetPaths().TargetFilename, p, templ, maxBytes)
+		if err != nil {
+			return err
+		}
+		if !written {
+			half := len(chunk) / 2
+			chunks = append([]page.Pages{chunk[:half], chunk[half:]}, chunks...)
+			continue
+		}
+
+		var lastChange time.Time
+		for _, pp := range chunk {
+			if pp.Lastmod().After(lastChange) {
+				lastChange = pp.Lastmod()
+			}
+		}
+		s.sitemapShards = append(s.sitemapShards, sitemapIndexEntry{absURL: p.Permalink(), lastChange: lastChange})
+	}
+
+	return nil
+}
+
+// renderSearchIndex indexes the regular pages in the site and publishes the
+// index manifest to the target path of p, with the shards and the query
+// runtime in a directory next to it, e.g. searchindex.json,
+// searchindex/0.json and searchindex/search.js.
+func (s *Site) renderSearchIndex(p *pageState) error {
+	targetPath := p.targetPaths().TargetFilename
+	if targetPath == "" {
+		return errors.New("failed to create targetPath for search index")
+	}
+
+	s.Log.Debugf("Render search index to %q", targetPath)
+	s.h.IncrPageRender()
+
+	idx := searchindex.New(s.siteCfg.searchIndex, s.language.Lang)
+	for _, pp := range s.RegularPages() {
+		idx.Add(searchindex.Document{
+			Title:   pp.Title(),
+			URL:     pp.RelPermalink(),
+			Summary: helpers.StripHTML(string(pp.Summary())),
+			Tags:    cast.ToStringSlice(pp.Params()["tags"]),
+			Content: pp.Plain(),
+		})
+	}
+
+	dir := strings.TrimSuffix(targetPath, filepath.Ext(targetPath))
+	manifest, shards, err := idx.Encode(filepath.Base(dir) + "/")
+	if err != nil {
+		return p.errorf(err, "failed to encode search index")
+	}
+
+	publish := func(targetPath string, b []byte, f output.Format, statCounter *uint64) error {
+		return s.publisher.Publish(publisher.Descriptor{
+			Src:          bytes.NewReader(b),
+			TargetPath:   targetPath,
+			StatCounter:  statCounter,
+			OutputFormat: f,
+		})
+	}
+
+	if err := publish(targetPath, manifest, p.outputFormat(), &s.PathSpec.ProcessingStats.Pages); err != nil {
+		return err
+	}
+	for i, shard := range shards {
+		if err := publish(filepath.Join(dir, fmt.Sprintf("%d.json", i)), shard, output.JSONFormat, &s.PathSpec.ProcessingStats.Files); err != nil {
+			return err
+		}
+	}
+
+	jsFormat := output.Format{Name: "JS", MediaType: media.JavascriptType, IsPlainText: true}
+	return publish(filepath.Join(dir, "search.js"), searchindex.Runtime, jsFormat, &s.PathSpec.ProcessingStats.Files)
 }
 
 func (s *Site) renderRobotsTXT() error {
@@ -314,9 +533,15 @@ func (s *Site) renderRobotsTXT() error {
 	return s.renderAndWritePage(&s.PathSpec.ProcessingStats.Pages, "Robots Txt", p.targetPaths().TargetFilename, p, templ)
 }
 
-// renderAliases renders shell pages that simply have a redirect in the header.
+// renderAliases renders shell pages that simply have a redirect in the header,
+// unless disableAliases is set, and collects the aliases as server side
+// redirects for the redirect maps.
 func (s *Site) renderAliases() error {
 	var err error
+
+	writeAliasPages := !s.Cfg.GetBool("disableAliases")
+	s.aliasRedirects = nil
+
 	s.pageMap.pageTrees.WalkLinkable(func(ss string, n *contentNode) bool {
 		p := n.p
 		if len(p.Aliases()) == 0 {
@@ -357,6 +582,16 @@ func (s *Site) renderAliases() error {
 					a += ".html"
 				}
 
+				s.aliasRedirects = append(s.aliasRedirects, redirects.Redirect{
+					From:   s.aliasRedirectFrom(a),
+					To:     of.RelPermalink(),
+					Status: s.siteCfg.redirectMap.Status,
+				})
+
+				if !writeAliasPages {
+					continue
+				}
+
 				lang := p.Language().Lang
 
 				if s.h.multihost && !strings.HasPrefix(a, "/"+lang) {
@@ -376,6 +611,44 @@ func (s *Site) renderAliases() error {
 	return err
 }
 
+// aliasRedirectFrom returns the request path for the alias a as published
+// by renderAliases, e.g. /docs/old/ for the alias /old with baseURL
+// https://example.org/docs/.
+func (s *Site) aliasRedirectFrom(a string) string {
+	a = path.Clean("/" + a)
+	if !strings.HasSuffix(a, ".html") && a != "/" {
+		a += "/"
+	}
+	return s.PathSpec.PrependBasePath(a, true)
+}
+
+// publishRedirectMap publishes m in the configured redirect map formats to
+// the given publish root, e.g. public/_redirects.
+func (s *Site) publishRedirectMap(root string, m *redirects.Map) error {
+	for _, format := range s.siteCfg.redirectMap.Formats {
+		f := output.Format{Name: "Redirects", MediaType: media.TextType, IsPlainText: true}
+		if format == redirects.FormatJSON {
+			f = output.JSONFormat
+		}
+
+		var b bytes.Buffer
+		if err := m.Write(&b, format); err != nil {
+			return err
+		}
+
+		if err := s.publisher.Publish(publisher.Descriptor{
+			Src:          &b,
+			TargetPath:   filepath.Join(root, redirects.Filename(format)),
+			StatCounter:  &s.PathSpec.ProcessingStats.Files,
+			OutputFormat: f,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // renderMainLanguageRedirect creates a redirect to the main language home,
 // depending on if it lives in sub folder (e.g. /en) or not.
 func (s *Site) renderMainLanguageRedirect() error {
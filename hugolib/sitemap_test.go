@@ -14,7 +14,9 @@
 package hugolib
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -118,3 +120,162 @@ outputs: [ "html", "amp" ]
 	// Should link to the HTML version.
 	b.AssertFileContent("public/sitemap.xml", " <loc>http://example.com/blog/html-amp/</loc>")
 }
+
+func TestSitemapSplit(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS"]
+[sitemap]
+maxURLs = 2
+-- content/p1.md --
+---
+title: "p1"
+lastmod: 2021-01-01
+---
+-- content/p2.md --
+---
+title: "p2"
+lastmod: 2022-03-01
+---
+-- content/p3.md --
+---
+title: "p3"
+---
+-- layouts/_default/single.html --
+{{ .Title }}
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/sitemap.xml",
+		"<sitemapindex",
+		"<loc>https://example.com/sitemap-1.xml</loc>",
+		"<loc>https://example.com/sitemap-2.xml</loc>",
+		"<lastmod>2022-03-01T00:00:00+00:00</lastmod>",
+	)
+	b.Assert(b.FileContent("public/sitemap.xml"), qt.Not(qt.Contains), "sitemap-3.xml")
+	b.AssertFileContent("public/sitemap-1.xml", "<urlset", "<url>")
+	b.AssertFileContent("public/sitemap-2.xml", "<urlset", "<url>")
+
+	all := b.FileContent("public/sitemap-1.xml") + b.FileContent("public/sitemap-2.xml")
+	for _, loc := range []string{"https://example.com/", "https://example.com/p1/", "https://example.com/p2/", "https://example.com/p3/"} {
+		b.Assert(strings.Count(all, "<loc>"+loc+"</loc>"), qt.Equals, 1)
+	}
+}
+
+func TestSitemapSplitMultilingual(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS"]
+defaultContentLanguage = "en"
+[languages]
+[languages.en]
+weight = 1
+[languages.nn]
+weight = 2
+[languages.en.sitemap]
+maxURLs = 2
+-- content/p1.md --
+---
+title: "p1"
+---
+-- content/p2.md --
+---
+title: "p2"
+---
+-- content/p1.nn.md --
+---
+title: "p1 nn"
+---
+-- layouts/_default/single.html --
+{{ .Title }}
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	// The root sitemap index lists the split sitemap files directly.
+	b.AssertFileContent("public/sitemap.xml",
+		"<loc>https://example.com/en/sitemap-1.xml</loc>",
+		"<loc>https://example.com/en/sitemap-2.xml</loc>",
+		"<loc>https://example.com/nn/sitemap.xml</loc>",
+	)
+	b.Assert(b.FileContent("public/sitemap.xml"), qt.Not(qt.Contains), "https://example.com/en/sitemap.xml")
+	b.AssertFileContent("public/en/sitemap.xml", "<sitemapindex", "<loc>https://example.com/en/sitemap-1.xml</loc>")
+	b.AssertFileContent("public/en/sitemap-1.xml", "<urlset")
+	b.AssertFileContent("public/nn/sitemap.xml", "<urlset", "<loc>https://example.com/nn/p1/</loc>")
+}
+
+// Not parallel, as it changes sitemapMaxBytes.
+func TestSitemapSplitBySize(t *testing.T) {
+	defer func(old int) { sitemapMaxBytes = old }(sitemapMaxBytes)
+	sitemapMaxBytes = 330
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["taxonomy", "term", "RSS"]
+-- content/p1.md --
+---
+title: "p1"
+---
+-- content/p2.md --
+---
+title: "p2"
+---
+-- content/p3.md --
+---
+title: "p3"
+---
+-- layouts/_default/single.html --
+{{ .Title }}
+-- layouts/index.html --
+Home.
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/sitemap.xml",
+		"<sitemapindex",
+		"<loc>https://example.com/sitemap-1.xml</loc>",
+		"<loc>https://example.com/sitemap-2.xml</loc>",
+	)
+
+	var all string
+	for i := 1; ; i++ {
+		filename := fmt.Sprintf("public/sitemap-%d.xml", i)
+		if !b.destinationExists(filename) {
+			break
+		}
+		content := b.FileContent(filename)
+		b.Assert(len(content) <= sitemapMaxBytes, qt.IsTrue, qt.Commentf(filename))
+		all += content
+	}
+	for _, loc := range []string{"https://example.com/", "https://example.com/p1/", "https://example.com/p2/", "https://example.com/p3/"} {
+		b.Assert(strings.Count(all, "<loc>"+loc+"</loc>"), qt.Equals, 1)
+	}
+}
@@ -694,3 +694,44 @@ abcdefgs: {{ template "print-page" $abcdefgs }}|IsAncestor: {{ $abcdefgs.IsAnces
     abcdefgs: /abcdefgs/|Abcdefgs|taxonomy|Parent: /|CurrentSection: /|FirstSection: /|IsAncestor: true|IsDescendant: false
 `)
 }
+
+func TestTaxonomyAndTermPermalinks(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+baseURL = "https://example.com/"
+disableKinds = ["RSS", "sitemap", "robotsTXT", "404"]
+[taxonomies]
+tag = "tags"
+category = "categories"
+[permalinks]
+categories = "/c/:slug/"
+[permalinks.taxonomy]
+tags = "/tag/"
+[permalinks.term]
+tags = "/tag/:slug/"
+-- content/p1.md --
+---
+title: "P1"
+tags: ["Go"]
+categories: ["News"]
+---
+-- layouts/_default/single.html --
+{{ .Title }}
+-- layouts/_default/list.html --
+{{ .Kind }}: {{ .RelPermalink }}|{{ range .Pages }}{{ .RelPermalink }}|{{ end }}
+`
+
+	b := NewIntegrationTestBuilder(
+		IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/tag/index.html", "taxonomy: /tag/|/tag/go/|")
+	b.AssertFileContent("public/tag/go/index.html", "term: /tag/go/|/p1/|")
+	b.AssertFileContent("public/categories/index.html", "taxonomy: /categories/|/c/news/|")
+	b.AssertFileContent("public/c/news/index.html", "term: /c/news/|/p1/|")
+}
@@ -0,0 +1,453 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package linkcheck checks the links in a published site.
+package linkcheck
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"golang.org/x/net/html"
+)
+
+// Link types.
+const (
+	// TypeInternal is a link to a file in the site.
+	TypeInternal = "internal"
+
+	// TypeFragment is a link to a missing anchor in an existing page.
+	TypeFragment = "fragment"
+
+	// TypeExternal is a link to another site.
+	TypeExternal = "external"
+)
+
+// Options configures the link checker.
+type Options struct {
+	// The base URLs of the site, used to recognize absolute links to the
+	// site itself and to resolve root relative links.
+	BaseURLs []string
+
+	// Whether to check external links.
+	External bool
+
+	// If set, external links are only requested if this returns nil.
+	AllowExternal func(uri string) error
+
+	// The client to use for external links. Defaults to a client with a
+	// 10 second timeout.
+	HTTPClient *http.Client
+
+	// The number of external links to check in parallel. Defaults to 10.
+	Workers int
+}
+
+// Report is the result of a link check.
+type Report struct {
+	// The number of HTML files checked.
+	Files int `json:"files"`
+
+	// The number of links checked.
+	Links int `json:"links"`
+
+	// The number of unique external URLs checked.
+	External int `json:"external"`
+
+	// The external URLs not checked because they are not allowed.
+	Skipped []string `json:"skipped"`
+
+	Broken []BrokenLink `json:"broken"`
+}
+
+// BrokenLink is a link that could not be resolved.
+type BrokenLink struct {
+	// The published file containing the link, e.g. "/posts/mypost/index.html".
+	Source string `json:"source"`
+
+	// The link as written in the source.
+	Link string `json:"link"`
+
+	// One of TypeInternal, TypeFragment and TypeExternal.
+	Type string `json:"type"`
+
+	// Why the link is broken.
+	Reason string `json:"reason"`
+}
+
+type link struct {
+	source string
+	href   string
+}
+
+type document struct {
+	ids   map[string]bool
+	links []string
+}
+
+type checker struct {
+	opts     Options
+	fs       afero.Fs
+	basePath string
+	baseURLs []*url.URL
+
+	files map[string]bool
+	docs  map[string]*document
+
+	report Report
+}
+
+// Check checks the links in the HTML files in fs, which holds the
+// published site.
+func Check(fs afero.Fs, opts Options) (Report, error) {
+	c := &checker{
+		opts:  opts,
+		fs:    fs,
+		files: make(map[string]bool),
+		docs:  make(map[string]*document),
+	}
+
+	for _, s := range opts.BaseURLs {
+		u, err := url.Parse(s)
+		if err != nil {
+			return c.report, errors.Wrapf(err, "failed to parse base URL %q", s)
+		}
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		c.baseURLs = append(c.baseURLs, u)
+	}
+	if len(c.baseURLs) > 0 {
+		c.basePath = strings.TrimSuffix(c.baseURLs[0].Path, "/")
+	}
+
+	if err := c.collect(); err != nil {
+		return c.report, err
+	}
+
+	external := make(map[string][]link)
+
+	for _, filename := range c.sortedDocs() {
+		for _, href := range c.docs[filename].links {
+			c.report.Links++
+			l := link{source: filename, href: href}
+			if u := c.resolve(l); u != "" {
+				external[u] = append(external[u], l)
+			}
+		}
+	}
+
+	if opts.External {
+		c.checkExternal(external)
+	}
+
+	sort.Slice(c.report.Broken, func(i, j int) bool {
+		bi, bj := c.report.Broken[i], c.report.Broken[j]
+		if bi.Source != bj.Source {
+			return bi.Source < bj.Source
+		}
+		return bi.Link < bj.Link
+	})
+	sort.Strings(c.report.Skipped)
+
+	return c.report, nil
+}
+
+// collect reads all files and parses the HTML documents.
+func (c *checker) collect() error {
+	return afero.Walk(c.fs, "", func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		name := "/" + strings.TrimPrefix(filepath.ToSlash(filename), "/")
+		c.files[name] = true
+		if path.Ext(name) != ".html" && path.Ext(name) != ".htm" {
+			return nil
+		}
+		f, err := c.fs.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		doc, err := parseDocument(f)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse %q", name)
+		}
+		c.docs[name] = doc
+		c.report.Files++
+		return nil
+	})
+}
+
+func (c *checker) sortedDocs() []string {
+	names := make([]string, 0, len(c.docs))
+	for name := range c.docs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// linkAttrs maps elements to the attribute holding the link.
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"area":   "href",
+	"link":   "href",
+	"img":    "src",
+	"script": "src",
+	"source": "src",
+	"iframe": "src",
+	"video":  "src",
+	"audio":  "src",
+}
+
+func parseDocument(r io.Reader) (*document, error) {
+	doc := &document{ids: make(map[string]bool)}
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return doc, nil
+			}
+			return nil, z.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			linkAttr := linkAttrs[t.Data]
+			for _, a := range t.Attr {
+				switch {
+				case a.Key == "id", a.Key == "name" && t.Data == "a":
+					doc.ids[a.Val] = true
+				case a.Key == linkAttr:
+					if t.Data == "link" && !isLinkRelChecked(t) {
+						continue
+					}
+					doc.links = append(doc.links, strings.TrimSpace(a.Val))
+				}
+			}
+		}
+	}
+}
+
+// isLinkRelChecked reports whether the link element points to a resource
+// that must exist, e.g. a stylesheet or an alternate output format.
+func isLinkRelChecked(t html.Token) bool {
+	for _, a := range t.Attr {
+		if a.Key != "rel" {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.ToLower(a.Val)) {
+			switch rel {
+			case "stylesheet", "icon", "alternate", "canonical", "prev", "next", "manifest":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolve checks internal links and fragments, recording any broken ones,
+// and returns the URL to check if l is an external link.
+func (c *checker) resolve(l link) string {
+	if l.href == "" {
+		return ""
+	}
+	u, err := url.Parse(l.href)
+	if err != nil {
+		c.broken(l, TypeInternal, err.Error())
+		return ""
+	}
+
+	switch u.Scheme {
+	case "", "http", "https":
+	default:
+		// mailto:, tel:, javascript: etc.
+		return ""
+	}
+
+	if u.Host != "" {
+		if !c.isSite(u) {
+			if u.Scheme == "" {
+				u.Scheme = "https"
+			}
+			u.Fragment = ""
+			return u.String()
+		}
+		u.Scheme, u.Host, u.User = "", "", nil
+	}
+
+	if u.Path == "" && u.Fragment == "" {
+		// E.g. "?page=2".
+		return ""
+	}
+
+	target := l.source
+	if u.Path != "" {
+		p := u.Path
+		if !strings.HasPrefix(p, "/") {
+			p = path.Join(path.Dir(c.basePath+l.source), p)
+			if strings.HasSuffix(u.Path, "/") && !strings.HasSuffix(p, "/") {
+				p += "/"
+			}
+		}
+		if c.basePath != "" {
+			if p != c.basePath && !strings.HasPrefix(p, c.basePath+"/") {
+				c.broken(l, TypeInternal, fmt.Sprintf("%q is outside of the site's base path %q", p, c.basePath+"/"))
+				return ""
+			}
+			p = strings.TrimPrefix(p, c.basePath)
+		}
+		var found bool
+		target, found = c.lookup(p)
+		if !found {
+			c.broken(l, TypeInternal, fmt.Sprintf("%q not found", p))
+			return ""
+		}
+	}
+
+	if u.Fragment == "" || u.Fragment == "top" {
+		return ""
+	}
+	doc, found := c.docs[target]
+	if !found {
+		// Not an HTML document, e.g. a PDF.
+		return ""
+	}
+	if !doc.ids[u.Fragment] {
+		c.broken(l, TypeFragment, fmt.Sprintf("anchor %q not found in %q", u.Fragment, target))
+	}
+
+	return ""
+}
+
+// lookup finds the published file for the URL path p.
+func (c *checker) lookup(p string) (string, bool) {
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += "index.html"
+		if p == "index.html" {
+			p = "/index.html"
+		}
+		return p, c.files[p]
+	}
+	if c.files[p] {
+		return p, true
+	}
+	if index := p + "/index.html"; c.files[index] {
+		return index, true
+	}
+	return p, false
+}
+
+func (c *checker) isSite(u *url.URL) bool {
+	for _, b := range c.baseURLs {
+		if strings.EqualFold(u.Host, b.Host) && strings.HasPrefix(u.Path+"/", b.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) broken(l link, typ, reason string) {
+	c.report.Broken = append(c.report.Broken, BrokenLink{
+		Source: l.source,
+		Link:   l.href,
+		Type:   typ,
+		Reason: reason,
+	})
+}
+
+// checkExternal requests every external URL once.
+func (c *checker) checkExternal(external map[string][]link) {
+	client := c.opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	workers := c.opts.Workers
+	if workers <= 0 {
+		workers = 10
+	}
+
+	urls := make(chan string)
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for uri := range urls {
+				err := checkURL(client, uri)
+				if err == nil {
+					continue
+				}
+				mu.Lock()
+				for _, l := range external[uri] {
+					c.broken(l, TypeExternal, err.Error())
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	keys := make([]string, 0, len(external))
+	for uri := range external {
+		keys = append(keys, uri)
+	}
+	sort.Strings(keys)
+
+	for _, uri := range keys {
+		if c.opts.AllowExternal != nil {
+			if err := c.opts.AllowExternal(uri); err != nil {
+				c.report.Skipped = append(c.report.Skipped, uri)
+				continue
+			}
+		}
+		c.report.External++
+		urls <- uri
+	}
+	close(urls)
+	wg.Wait()
+}
+
+func checkURL(client *http.Client, uri string) error {
+	resp, err := client.Head(uri)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusForbidden) {
+		// Some servers do not support HEAD requests.
+		resp.Body.Close()
+		resp, err = client.Get(uri)
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linkcheck
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/afero"
+)
+
+func newTestFs(c *qt.C, files map[string]string) afero.Fs {
+	fs := afero.NewMemMapFs()
+	for name, content := range files {
+		c.Assert(afero.WriteFile(fs, filepath.FromSlash(name), []byte(content), 0666), qt.IsNil)
+	}
+	return fs
+}
+
+func TestCheck(t *testing.T) {
+	c := qt.New(t)
+
+	fs := newTestFs(c, map[string]string{
+		"index.html": `<html><head><link rel="stylesheet" href="/css/main.css"><link rel="preconnect" href="/nope/"></head><body>
+<a href="/posts/p1/">P1</a>
+<a href="posts/p1/#intro">P1 intro</a>
+<a href="https://example.org/posts/p1/#missing">P1 missing</a>
+<a href="/posts/p2/">P2</a>
+<a href="#top">Top</a>
+<a href="mailto:mail@example.org">Mail</a>
+<a href="?page=2">Page 2</a>
+<img src="/images/missing.png">
+</body></html>`,
+		"posts/p1/index.html": `<h2 id="intro">Intro</h2><a name="old">Old</a>
+<a href="../../#main">Home</a>
+<a href="#old">Old</a>
+<a href="/files/doc.pdf#page=2">PDF</a>`,
+		"css/main.css":  "body {}",
+		"files/doc.pdf": "PDF",
+		"robots.txt":    "",
+		"posts/p3.html": `<a href="/posts/p1">P1</a><a href="p3.html">Self</a>`,
+	})
+
+	report, err := Check(fs, Options{BaseURLs: []string{"https://example.org/"}})
+	c.Assert(err, qt.IsNil)
+	c.Assert(report.Files, qt.Equals, 3)
+	c.Assert(report.Links, qt.Equals, 14)
+	c.Assert(report.Broken, qt.DeepEquals, []BrokenLink{
+		{Source: "/index.html", Link: "/images/missing.png", Type: TypeInternal, Reason: `"/images/missing.png" not found`},
+		{Source: "/index.html", Link: "/posts/p2/", Type: TypeInternal, Reason: `"/posts/p2/" not found`},
+		{Source: "/index.html", Link: "https://example.org/posts/p1/#missing", Type: TypeFragment, Reason: `anchor "missing" not found in "/posts/p1/index.html"`},
+		{Source: "/posts/p1/index.html", Link: "../../#main", Type: TypeFragment, Reason: `anchor "main" not found in "/index.html"`},
+	})
+}
+
+func TestCheckBasePath(t *testing.T) {
+	c := qt.New(t)
+
+	fs := newTestFs(c, map[string]string{
+		"index.html":          `<a href="/docs/posts/p1/">P1</a><a href="/posts/p1/">P1</a><a href="posts/p1/">P1</a>`,
+		"posts/p1/index.html": `<a href="https://example.org/docs/">Home</a><a href="../">Posts</a>`,
+	})
+
+	report, err := Check(fs, Options{BaseURLs: []string{"https://example.org/docs/"}})
+	c.Assert(err, qt.IsNil)
+	c.Assert(report.Broken, qt.HasLen, 2)
+	c.Assert(report.Broken[0].Link, qt.Equals, "/posts/p1/")
+	c.Assert(report.Broken[0].Reason, qt.Contains, "outside of the site's base path")
+	c.Assert(report.Broken[1].Link, qt.Equals, "../")
+	c.Assert(report.Broken[1].Reason, qt.Equals, `"/posts/" not found`)
+}
+
+func TestCheckExternal(t *testing.T) {
+	c := qt.New(t)
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/ok":
+		case "/nohead":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	fs := newTestFs(c, map[string]string{
+		"index.html": strings.NewReplacer("SRV", srv.URL).Replace(`
+<a href="SRV/ok">OK</a>
+<a href="SRV/ok#foo">OK</a>
+<a href="SRV/nohead">No HEAD</a>
+<a href="SRV/missing">Missing</a>
+<a href="https://blocked.example.com/">Blocked</a>`),
+	})
+
+	opts := Options{
+		BaseURLs: []string{"https://example.org/"},
+		AllowExternal: func(uri string) error {
+			if strings.Contains(uri, "blocked") {
+				return errors.New("not allowed")
+			}
+			return nil
+		},
+		Workers: 1,
+	}
+
+	report, err := Check(fs, opts)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 0)
+	c.Assert(report.Broken, qt.HasLen, 0)
+
+	opts.External = true
+	report, err = Check(fs, opts)
+	c.Assert(err, qt.IsNil)
+	c.Assert(requests, qt.Equals, 4)
+	c.Assert(report.External, qt.Equals, 3)
+	c.Assert(report.Skipped, qt.DeepEquals, []string{"https://blocked.example.com/"})
+	c.Assert(report.Broken, qt.DeepEquals, []BrokenLink{
+		{Source: "/index.html", Link: srv.URL + "/missing", Type: TypeExternal, Reason: "404 Not Found"},
+	})
+}
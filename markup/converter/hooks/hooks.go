@@ -36,6 +36,19 @@ type LinkContext interface {
 	PlainText() string
 }
 
+// ImageContext contains accessors to all attributes that an ImageRenderer
+// can use to render an image.
+type ImageContext interface {
+	LinkContext
+	// IsBlock reports whether the image is alone in its paragraph.
+	IsBlock() bool
+	// Ordinal is the zero-based index of the image on the page.
+	Ordinal() int
+
+	// Attributes (e.g. CSS classes)
+	AttributesProvider
+}
+
 type CodeblockContext interface {
 	AttributesProvider
 	text.Positioner
@@ -56,6 +69,12 @@ type LinkRenderer interface {
 	identity.Provider
 }
 
+// ImageRenderer describes a uniquely identifiable rendering hook.
+type ImageRenderer interface {
+	RenderImage(w io.Writer, ctx ImageContext) error
+	identity.Provider
+}
+
 type CodeBlockRenderer interface {
 	RenderCodeblock(w hugio.FlexiWriter, ctx CodeblockContext) error
 	identity.Provider
@@ -90,6 +109,89 @@ type HeadingRenderer interface {
 	identity.Provider
 }
 
+// TableContext contains accessors to all attributes that a TableRenderer
+// can use to render a table.
+type TableContext interface {
+	// Page is the page containing the table.
+	Page() interface{}
+	// THead returns the header rows.
+	THead() []TableRow
+	// TBody returns the body rows.
+	TBody() []TableRow
+	// Ordinal is the zero-based index of the table on the page.
+	Ordinal() int
+
+	// Attributes (e.g. CSS classes)
+	AttributesProvider
+}
+
+// TableRow holds the cells of a table row.
+type TableRow []TableCell
+
+// TableCell holds a single table cell.
+type TableCell struct {
+	// Text is the rendered (HTML) cell content.
+	Text string
+	// Alignment is the column alignment, one of "left", "right", "center" or "" (none).
+	Alignment string
+	// Attributes holds the cell attributes, e.g. set by an AST transformer.
+	Attributes map[string]interface{}
+}
+
+// TableRenderer describes a uniquely identifiable rendering hook.
+type TableRenderer interface {
+	RenderTable(w hugio.FlexiWriter, ctx TableContext) error
+	identity.Provider
+}
+
+// BlockquoteContext contains accessors to all attributes that a BlockquoteRenderer
+// can use to render a blockquote.
+type BlockquoteContext interface {
+	// Page is the page containing the blockquote.
+	Page() interface{}
+	// Text is the rendered (HTML) content of the blockquote, excluding any alert marker.
+	Text() string
+	// Type is the blockquote type, either "regular" or "alert".
+	Type() string
+	// AlertType is the lower case GitHub alert type (e.g. "note" or "warning")
+	// when Type is "alert", else empty.
+	AlertType() string
+	// Ordinal is the zero-based index of the blockquote on the page.
+	Ordinal() int
+
+	// Attributes (e.g. CSS classes)
+	AttributesProvider
+}
+
+// BlockquoteRenderer describes a uniquely identifiable rendering hook.
+type BlockquoteRenderer interface {
+	RenderBlockquote(w hugio.FlexiWriter, ctx BlockquoteContext) error
+	identity.Provider
+}
+
+// PassthroughContext contains accessors to all attributes that a PassthroughRenderer
+// can use to render a passthrough region, e.g. some LaTeX math.
+type PassthroughContext interface {
+	// Page is the page containing the passthrough region.
+	Page() interface{}
+	// Type is either "inline" or "block".
+	Type() string
+	// Inner is the verbatim content between the delimiters.
+	Inner() string
+	// Ordinal is the zero-based index of the passthrough region on the page,
+	// counted separately for inline and block regions.
+	Ordinal() int
+
+	// Attributes (e.g. CSS classes)
+	AttributesProvider
+}
+
+// PassthroughRenderer describes a uniquely identifiable rendering hook.
+type PassthroughRenderer interface {
+	RenderPassthrough(w hugio.FlexiWriter, ctx PassthroughContext) error
+	identity.Provider
+}
+
 // ElementPositionResolver provides a way to resolve the start Position
 // of a markdown element in the original source document.
 // This may be both slow and aproximate, so should only be
@@ -105,6 +207,9 @@ const (
 	ImageRendererType
 	HeadingRendererType
 	CodeBlockRendererType
+	TableRendererType
+	BlockquoteRendererType
+	PassthroughRendererType
 )
 
 type GetRendererFunc func(t RendererType, id interface{}) interface{}
@@ -21,8 +21,11 @@ import (
 	"runtime/debug"
 
 	"github.com/gohugoio/hugo/markup/goldmark/codeblocks"
+	"github.com/gohugoio/hugo/markup/goldmark/images"
 	"github.com/gohugoio/hugo/markup/goldmark/internal/extensions/attributes"
 	"github.com/gohugoio/hugo/markup/goldmark/internal/render"
+	"github.com/gohugoio/hugo/markup/goldmark/passthrough"
+	"github.com/gohugoio/hugo/markup/goldmark/tables"
 
 	"github.com/gohugoio/hugo/identity"
 
@@ -96,6 +99,7 @@ func newMarkdown(pcfg converter.ProviderConfig) goldmark.Markdown {
 		extensions = []goldmark.Extender{
 			newLinks(),
 			newTocExtension(rendererOptions),
+			images.New(cfg.Parser.WrapStandAloneImageWithinParagraph),
 		}
 		parserOptions []parser.Option
 	)
@@ -105,7 +109,7 @@ func newMarkdown(pcfg converter.ProviderConfig) goldmark.Markdown {
 	}
 
 	if cfg.Extensions.Table {
-		extensions = append(extensions, extension.Table)
+		extensions = append(extensions, extension.Table, tables.New())
 	}
 
 	if cfg.Extensions.Strikethrough {
@@ -132,6 +136,10 @@ func newMarkdown(pcfg converter.ProviderConfig) goldmark.Markdown {
 		extensions = append(extensions, extension.Footnote)
 	}
 
+	if cfg.Extensions.Passthrough.Enable {
+		extensions = append(extensions, passthrough.New(cfg.Extensions.Passthrough))
+	}
+
 	if cfg.Parser.AutoHeadingID {
 		parserOptions = append(parserOptions, parser.WithAutoHeadingID())
 	}
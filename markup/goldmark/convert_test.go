@@ -20,6 +20,9 @@ import (
 
 	"github.com/spf13/cast"
 
+	"github.com/gohugoio/hugo/common/hugio"
+	"github.com/gohugoio/hugo/identity"
+
 	"github.com/gohugoio/hugo/markup/converter/hooks"
 	"github.com/gohugoio/hugo/markup/goldmark/goldmark_config"
 
@@ -499,3 +502,48 @@ LINE5
 		c.Assert(result, qt.Contains, "<span class=\"ln\">2</span><span class=\"cl\">LINE2\n</span></span>")
 	})
 }
+
+type testBlockquoteRenderer struct{}
+
+func (r testBlockquoteRenderer) RenderBlockquote(w hugio.FlexiWriter, ctx hooks.BlockquoteContext) error {
+	_, err := fmt.Fprintf(w, "[%d:%s]", ctx.Ordinal(), strings.TrimSpace(ctx.Text()))
+	return err
+}
+
+func (r testBlockquoteRenderer) GetIdentity() identity.Identity {
+	return identity.NewPathIdentity("layouts", "render-blockquote")
+}
+
+func TestConvertBlockquoteOrdinal(t *testing.T) {
+	c := qt.New(t)
+
+	p, err := Provider.New(
+		converter.ProviderConfig{
+			MarkupConfig: markup_config.Default,
+			Logger:       loggers.NewErrorLogger(),
+		},
+	)
+	c.Assert(err, qt.IsNil)
+
+	getRenderer := func(t hooks.RendererType, id interface{}) interface{} {
+		if t == hooks.BlockquoteRendererType {
+			return testBlockquoteRenderer{}
+		}
+		return nil
+	}
+
+	content := `
+> Outer.
+>
+> > Inner.
+
+> Last.
+`
+
+	conv, err := p.New(converter.DocumentContext{DocumentID: "thedoc"})
+	c.Assert(err, qt.IsNil)
+	b, err := conv.Convert(converter.RenderContext{Src: []byte(content), GetRenderer: getRenderer})
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(string(b.Bytes()), qt.Equals, "[0:<p>Outer.</p>\n[1:<p>Inner.</p>]][2:<p>Last.</p>]")
+}
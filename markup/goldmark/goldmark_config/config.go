@@ -41,6 +41,7 @@ var Default = Config{
 			Title: true,
 			Block: false,
 		},
+		WrapStandAloneImageWithinParagraph: true,
 	},
 }
 
@@ -61,6 +62,29 @@ type Extensions struct {
 	Strikethrough bool
 	Linkify       bool
 	TaskList      bool
+
+	// Leave delimited regions, e.g. LaTeX math, untouched by the Markdown parser.
+	Passthrough Passthrough
+}
+
+// Passthrough configures the passthrough extension.
+type Passthrough struct {
+	// Whether to enable the extension.
+	Enable bool
+
+	// The delimiters to use. If both inline and block delimiters are empty,
+	// $...$ and \(...\) are used for inline and $$...$$ and \[...\] for block regions.
+	Delimiters DelimitersConfig
+}
+
+// DelimitersConfig holds the passthrough delimiters.
+// Each entry is a pair of opening and closing delimiters, e.g. ["$", "$"].
+type DelimitersConfig struct {
+	// Delimiters for inline regions.
+	Inline [][]string
+
+	// Delimiters for block regions.
+	Block [][]string
 }
 
 type Renderer struct {
@@ -86,6 +110,10 @@ type Parser struct {
 
 	// Enables custom attributes.
 	Attribute ParserAttribute
+
+	// Whether to wrap stand-alone images within a paragraph.
+	// If false, any block attributes set on the paragraph are moved to the image.
+	WrapStandAloneImageWithinParagraph bool
 }
 
 type ParserAttribute struct {
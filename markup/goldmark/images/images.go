@@ -0,0 +1,103 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package images marks images that stand alone in their paragraph and
+// optionally unwraps them from that paragraph.
+package images
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// AttrIsBlock is set on images that are alone in their paragraph.
+	AttrIsBlock = internalAttrPrefix + "isBlock"
+
+	internalAttrPrefix = "_h__"
+)
+
+// IsInternalAttribute reports whether name is an attribute set by Hugo
+// for its own use, which should not be exposed to the render hooks.
+func IsInternalAttribute(name []byte) bool {
+	return strings.HasPrefix(string(name), internalAttrPrefix)
+}
+
+// IsBlock reports whether the image node n is alone in its paragraph.
+func IsBlock(n ast.Node) bool {
+	_, found := n.AttributeString(AttrIsBlock)
+	return found
+}
+
+type imagesExtension struct {
+	wrapStandAloneImageWithinParagraph bool
+}
+
+// New returns a Goldmark extension that marks stand-alone images. If
+// wrapStandAloneImageWithinParagraph is false, the surrounding paragraph is
+// removed and its attributes are moved to the image.
+func New(wrapStandAloneImageWithinParagraph bool) goldmark.Extender {
+	return &imagesExtension{wrapStandAloneImageWithinParagraph: wrapStandAloneImageWithinParagraph}
+}
+
+func (e *imagesExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithASTTransformers(
+			// Run after the block attributes have been attached to the paragraph.
+			util.Prioritized(&transformer{wrapStandAloneImageWithinParagraph: e.wrapStandAloneImageWithinParagraph}, 300),
+		),
+	)
+}
+
+type transformer struct {
+	wrapStandAloneImageWithinParagraph bool
+}
+
+func (t *transformer) Transform(doc *ast.Document, reader text.Reader, pctx parser.Context) {
+	var images []*ast.Image
+
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if n, ok := node.(*ast.Image); ok {
+			if parent := n.Parent(); parent.Kind() == ast.KindParagraph && parent.ChildCount() == 1 {
+				images = append(images, n)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, n := range images {
+		n.SetAttribute([]byte(AttrIsBlock), []byte("true"))
+
+		if t.wrapStandAloneImageWithinParagraph {
+			continue
+		}
+
+		paragraph := n.Parent()
+		for _, attr := range paragraph.Attributes() {
+			// Images have no attributes of their own in Markdown,
+			// so there is nothing to overwrite.
+			n.SetAttribute(attr.Name, attr.Value)
+		}
+		grandParent := paragraph.Parent()
+		grandParent.ReplaceChild(grandParent, paragraph, n)
+	}
+}
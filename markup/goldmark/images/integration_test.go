@@ -0,0 +1,124 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images_test
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/hugolib"
+)
+
+func TestImageHook(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+[markup.goldmark.parser]
+wrapStandAloneImageWithinParagraph = WRAP
+[markup.goldmark.parser.attribute]
+block = true
+-- layouts/_default/_markup/render-image.html --
+IMAGE: {{ .Destination | safeURL }}|Ordinal: {{ .Ordinal }}|IsBlock: {{ .IsBlock }}|Attributes: {{ .Attributes }}|Resource: {{ with .Resource }}{{ .Name }}:{{ .ResourceType }}{{ end }}|END
+-- layouts/_default/single.html --
+{{ .Content }}
+-- content/p1/index.md --
+---
+title: "p1"
+---
+
+![a](sunset.jpg)
+{class="foo"}
+
+Inline ![b](images/b.png) image.
+
+![c](/images/c.jpg)
+-- content/p1/sunset.jpg --
+sunset
+-- content/p1/images/b.png --
+b
+`
+
+	for _, wrap := range []bool{true, false} {
+		wrap := wrap
+		t.Run("wrap", func(t *testing.T) {
+			wrapStr := "true"
+			if !wrap {
+				wrapStr = "false"
+			}
+
+			b := hugolib.NewIntegrationTestBuilder(
+				hugolib.IntegrationTestConfig{
+					T:           t,
+					TxtarString: strings.ReplaceAll(files, "WRAP", wrapStr),
+				},
+			).Build()
+
+			b.AssertFileContent("public/p1/index.html",
+				"Inline IMAGE: images/b.png|Ordinal: 1|IsBlock: false|Attributes: map[]|Resource: images/b.png:image|END",
+			)
+
+			if wrap {
+				b.AssertFileContent("public/p1/index.html",
+					"<p class=\"foo\">IMAGE: sunset.jpg|Ordinal: 0|IsBlock: true|Attributes: map[]|Resource: sunset.jpg:image|END",
+					"<p>IMAGE: /images/c.jpg|Ordinal: 2|IsBlock: true|Attributes: map[]|Resource: |END",
+				)
+			} else {
+				b.AssertFileContent("public/p1/index.html",
+					"IMAGE: sunset.jpg|Ordinal: 0|IsBlock: true|Attributes: map[class:foo]|Resource: sunset.jpg:image|END",
+					"IMAGE: /images/c.jpg|Ordinal: 2|IsBlock: true|Attributes: map[]|Resource: |END",
+				)
+				content := b.FileContent("public/p1/index.html")
+				b.Assert(content, qt.Not(qt.Contains), "<p class=\"foo\">")
+				b.Assert(content, qt.Not(qt.Contains), "<p>IMAGE")
+			}
+		})
+	}
+}
+
+func TestImageNoHook(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+[markup.goldmark.parser]
+wrapStandAloneImageWithinParagraph = false
+[markup.goldmark.parser.attribute]
+block = true
+-- layouts/_default/single.html --
+{{ .Content }}
+-- content/p1.md --
+---
+title: "p1"
+---
+
+![a](a.jpg)
+{class="foo"}
+
+Inline ![b](b.jpg) image.
+`
+
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html",
+		"<img src=\"a.jpg\" alt=\"a\" class=\"foo\">",
+		"<p>Inline <img src=\"b.jpg\" alt=\"b\"> image.</p>",
+	)
+}
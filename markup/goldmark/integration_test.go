@@ -423,3 +423,43 @@ title: "p1"
 		<img src="b.jpg" alt="&quot;a&quot;">
 	`)
 }
+
+func TestBlockquoteHook(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+[markup.goldmark.parser.attribute]
+  block = true
+-- content/p1.md --
+---
+title: "p1"
+---
+> Regular quote.
+{class="foo"}
+
+> [!NOTE]
+> Useful information.
+
+> [!warning]
+>
+> Be careful.
+-- layouts/_default/_markup/render-blockquote.html --
+Blockquote: {{ .Ordinal }}|{{ .Type }}|{{ .AlertType }}|{{ .Attributes }}|{{ .Text | safeHTML }}|
+-- layouts/_default/single.html --
+{{ .Content }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html", `
+Blockquote: 0|regular||map[class:foo]|<p>Regular quote.</p>
+Blockquote: 1|alert|note|map[]|<p>Useful information.</p>
+Blockquote: 2|alert|warning|map[]|<p>Be careful.</p>
+	`)
+}
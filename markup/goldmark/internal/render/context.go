@@ -19,6 +19,7 @@ import (
 
 	"github.com/gohugoio/hugo/identity"
 	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/yuin/goldmark/ast"
 )
 
 type BufWriter struct {
@@ -42,6 +43,8 @@ func (b *BufWriter) Flush() error {
 type Context struct {
 	*BufWriter
 	positions []int
+	values    map[ast.NodeKind][]interface{}
+	ordinals  map[ast.NodeKind]int
 	ContextData
 }
 
@@ -56,6 +59,54 @@ func (ctx *Context) PopPos() int {
 	return p
 }
 
+// GetAndIncrementOrdinal returns the current ordinal for the node kind k
+// and increments it, so the next call for the same kind gets the next number.
+func (ctx *Context) GetAndIncrementOrdinal(k ast.NodeKind) int {
+	if ctx.ordinals == nil {
+		ctx.ordinals = make(map[ast.NodeKind]int)
+	}
+	i := ctx.ordinals[k]
+	ctx.ordinals[k]++
+	return i
+}
+
+// PushValue pushes v onto the value stack for the node kind k.
+// This is used to pass state between the renderers of a node and its children,
+// e.g. a table and its cells.
+func (ctx *Context) PushValue(k ast.NodeKind, v interface{}) {
+	if ctx.values == nil {
+		ctx.values = make(map[ast.NodeKind][]interface{})
+	}
+	ctx.values[k] = append(ctx.values[k], v)
+}
+
+// PopValue removes and returns the top of the value stack for the node kind k.
+func (ctx *Context) PopValue(k ast.NodeKind) interface{} {
+	if ctx.values == nil {
+		return nil
+	}
+	v := ctx.values[k]
+	if len(v) == 0 {
+		return nil
+	}
+	i := len(v) - 1
+	r := v[i]
+	ctx.values[k] = v[:i]
+	return r
+}
+
+// PeekValue returns the top of the value stack for the node kind k without removing it.
+func (ctx *Context) PeekValue(k ast.NodeKind) interface{} {
+	if ctx.values == nil {
+		return nil
+	}
+	v := ctx.values[k]
+	if len(v) == 0 {
+		return nil
+	}
+	return v[len(v)-1]
+}
+
 type ContextData interface {
 	RenderContext() converter.RenderContext
 	DocumentContext() converter.DocumentContext
@@ -0,0 +1,91 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package passthrough_test
+
+import (
+	"testing"
+
+	"github.com/gohugoio/hugo/hugolib"
+)
+
+func TestPassthroughRenderHook(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+[markup.goldmark.extensions.passthrough]
+enable = true
+-- content/p1.md --
+---
+title: "p1"
+---
+Inline $a_b * c_d$ and \(x^2\) costs $5 and $10.
+
+$$
+E = mc^2
+$$
+
+Some text with $$x_1 < y$$ in it.
+-- layouts/_default/_markup/render-passthrough.html --
+Passthrough: {{ .Type }}|{{ .Ordinal }}|{{ .Inner }}|
+-- layouts/_default/single.html --
+{{ .Content }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html", `
+<p>Inline Passthrough: inline|0|a_b * c_d|
+and Passthrough: inline|1|x^2|
+costs $5 and $10.</p>
+Passthrough: block|0|E = mc^2|
+<p>Some text with </p>
+Passthrough: block|1|x_1 &lt; y|
+`)
+}
+
+func TestPassthroughDefault(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+[markup.goldmark.extensions.passthrough]
+enable = true
+[markup.goldmark.extensions.passthrough.delimiters]
+inline = [['@@', '@@']]
+-- content/p1.md --
+---
+title: "p1"
+---
+Some @@a_b_c * d@@ math, but not $a*b*c$.
+-- layouts/_default/single.html --
+{{ .Content }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html", `
+<p>Some @@a_b_c * d@@ math, but not $a<em>b</em>c$.</p>
+`)
+}
@@ -0,0 +1,484 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package passthrough provides a Goldmark extension that leaves delimited
+// regions (e.g. LaTeX math) untouched by the Markdown parser.
+package passthrough
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/markup/converter/hooks"
+	"github.com/gohugoio/hugo/markup/goldmark/goldmark_config"
+	"github.com/gohugoio/hugo/markup/goldmark/internal/render"
+	"github.com/gohugoio/hugo/markup/internal/attributes"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	typeInline = "inline"
+	typeBlock  = "block"
+)
+
+// KindPassthroughInline is the kind of an inline passthrough node.
+var KindPassthroughInline = ast.NewNodeKind("PassthroughInline")
+
+// KindPassthroughBlock is the kind of a block passthrough node.
+var KindPassthroughBlock = ast.NewNodeKind("PassthroughBlock")
+
+// DefaultDelimiters are used when no delimiters are configured.
+var DefaultDelimiters = goldmark_config.DelimitersConfig{
+	Inline: [][]string{{`$`, `$`}, {`\(`, `\)`}},
+	Block:  [][]string{{`$$`, `$$`}, {`\[`, `\]`}},
+}
+
+type delimiters struct {
+	open  string
+	close string
+	block bool
+}
+
+// inner returns the content of raw without the delimiters.
+func (d delimiters) inner(raw []byte) string {
+	return string(raw[len(d.open) : len(raw)-len(d.close)])
+}
+
+type passthroughInline struct {
+	ast.BaseInline
+	delims delimiters
+	raw    []byte
+}
+
+func (*passthroughInline) Kind() ast.NodeKind { return KindPassthroughInline }
+
+func (n *passthroughInline) Dump(src []byte, level int) {
+	ast.DumpHelper(n, src, level, map[string]string{"Raw": string(n.raw)}, nil)
+}
+
+type passthroughBlock struct {
+	ast.BaseBlock
+	delims delimiters
+	raw    bytes.Buffer
+	closed bool
+}
+
+func (*passthroughBlock) Kind() ast.NodeKind { return KindPassthroughBlock }
+
+func (*passthroughBlock) IsRaw() bool { return true }
+
+func (n *passthroughBlock) Dump(src []byte, level int) {
+	ast.DumpHelper(n, src, level, map[string]string{"Raw": n.raw.String()}, nil)
+}
+
+type (
+	passthroughExtension struct {
+		delims []delimiters
+	}
+	inlineParser struct {
+		delims []delimiters
+	}
+	blockParser struct {
+		delims []delimiters
+	}
+	paragraphTransformer struct{}
+	htmlRenderer         struct{}
+)
+
+// New returns a Goldmark extension that passes the regions enclosed
+// by the configured delimiters through to the output unchanged.
+func New(cfg goldmark_config.Passthrough) goldmark.Extender {
+	dcfg := cfg.Delimiters
+	if len(dcfg.Inline) == 0 && len(dcfg.Block) == 0 {
+		dcfg = DefaultDelimiters
+	}
+
+	var delims []delimiters
+	add := func(pairs [][]string, block bool) {
+		for _, pair := range pairs {
+			if len(pair) != 2 || pair[0] == "" || pair[1] == "" {
+				continue
+			}
+			delims = append(delims, delimiters{open: pair[0], close: pair[1], block: block})
+		}
+	}
+	add(dcfg.Block, true)
+	add(dcfg.Inline, false)
+
+	// Try the longest opening delimiter first, so "$$" wins over "$".
+	sort.SliceStable(delims, func(i, j int) bool {
+		return len(delims[i].open) > len(delims[j].open)
+	})
+
+	return &passthroughExtension{delims: delims}
+}
+
+func (e *passthroughExtension) Extend(m goldmark.Markdown) {
+	var blockDelims []delimiters
+	for _, d := range e.delims {
+		if d.block {
+			blockDelims = append(blockDelims, d)
+		}
+	}
+
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(&blockParser{delims: blockDelims}, 90),
+		),
+		parser.WithInlineParsers(
+			util.Prioritized(&inlineParser{delims: e.delims}, 90),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(&paragraphTransformer{}, 100),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&htmlRenderer{}, 100),
+	))
+}
+
+func triggers(delims []delimiters) []byte {
+	var b []byte
+	for _, d := range delims {
+		if bytes.IndexByte(b, d.open[0]) == -1 {
+			b = append(b, d.open[0])
+		}
+	}
+	return b
+}
+
+func (p *inlineParser) Trigger() []byte {
+	return triggers(p.delims)
+}
+
+func (p *inlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	for _, d := range p.delims {
+		if !bytes.HasPrefix(line, []byte(d.open)) {
+			continue
+		}
+		if n := p.parseDelimited(block, d); n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+func (p *inlineParser) parseDelimited(block text.Reader, d delimiters) ast.Node {
+	l, pos := block.Position()
+
+	line, _ := block.PeekLine()
+	if d.open == "$" && (len(line) < 2 || util.IsSpace(line[1]) || line[1] == '$') {
+		// Avoid treating e.g. "$ 20" as math.
+		return nil
+	}
+
+	raw := []byte(d.open)
+	block.Advance(len(d.open))
+
+	for {
+		line, _ := block.PeekLine()
+		if line == nil {
+			block.SetPosition(l, pos)
+			return nil
+		}
+
+		if i := indexClose(line, d); i >= 0 {
+			raw = append(raw, line[:i+len(d.close)]...)
+			block.Advance(i + len(d.close))
+			if strings.TrimSpace(d.inner(raw)) == "" {
+				block.SetPosition(l, pos)
+				return nil
+			}
+			return &passthroughInline{delims: d, raw: raw}
+		}
+
+		raw = append(raw, line...)
+		block.AdvanceLine()
+	}
+}
+
+// indexClose returns the index of the closing delimiter in line, -1 if not found.
+func indexClose(line []byte, d delimiters) int {
+	closeb := []byte(d.close)
+	for offset := 0; offset < len(line); {
+		i := bytes.Index(line[offset:], closeb)
+		if i == -1 {
+			return -1
+		}
+		i += offset
+		offset = i + 1
+
+		if d.close[0] != '\\' && i > 0 && line[i-1] == '\\' {
+			// Escaped, e.g. \$.
+			continue
+		}
+
+		if d.close == "$" {
+			// Avoid treating e.g. "$a $20" as math.
+			if i > 0 && util.IsSpace(line[i-1]) {
+				continue
+			}
+			if next := i + 1; next < len(line) && line[next] >= '0' && line[next] <= '9' {
+				continue
+			}
+		}
+
+		return i
+	}
+	return -1
+}
+
+func (p *blockParser) Trigger() []byte {
+	return triggers(p.delims)
+}
+
+func (p *blockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	if w, _ := util.IndentWidth(line, reader.LineOffset()); w >= 4 {
+		return nil, parser.NoChildren
+	}
+	line = line[pos:]
+
+	for _, d := range p.delims {
+		if !bytes.HasPrefix(line, []byte(d.open)) {
+			continue
+		}
+		after := line[len(d.open):]
+		n := &passthroughBlock{delims: d}
+		if i := bytes.Index(after, []byte(d.close)); i >= 0 {
+			if !util.IsBlank(after[i+len(d.close):]) {
+				// Some text after the closing delimiter,
+				// leave this to the paragraph.
+				return nil, parser.NoChildren
+			}
+			n.raw.Write(line[:len(d.open)+i+len(d.close)])
+			n.closed = true
+			return n, parser.NoChildren
+		}
+		n.raw.Write(line)
+		return n, parser.NoChildren
+	}
+
+	return nil, parser.NoChildren
+}
+
+func (p *blockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*passthroughBlock)
+	if n.closed {
+		return parser.Close
+	}
+
+	line, segment := reader.PeekLine()
+	if len(line) == 0 {
+		// End of the document, leave the block unterminated.
+		return parser.Close
+	}
+	newline := 1
+	if line[len(line)-1] != '\n' {
+		newline = 0
+	}
+
+	if i := bytes.Index(line, []byte(n.delims.close)); i >= 0 && util.IsBlank(line[i+len(n.delims.close):]) {
+		n.raw.Write(line[:i+len(n.delims.close)])
+		n.closed = true
+		reader.Advance(segment.Stop - segment.Start - newline - segment.Padding)
+		return parser.Close
+	}
+
+	n.raw.Write(line)
+	reader.Advance(segment.Stop - segment.Start - newline - segment.Padding)
+
+	return parser.Continue | parser.NoChildren
+}
+
+func (p *blockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+}
+
+func (p *blockParser) CanInterruptParagraph() bool {
+	return false
+}
+
+func (p *blockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// Transform moves block passthroughs found inside paragraphs, e.g. "$$ a $$" written
+// in the middle of some text, out to the block level.
+func (t *paragraphTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var paragraphs []*ast.Paragraph
+
+	ast.Walk(doc, func(node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, nil
+		}
+
+		p, ok := node.(*ast.Paragraph)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		for c := p.FirstChild(); c != nil; c = c.NextSibling() {
+			if pt, ok := c.(*passthroughInline); ok && pt.delims.block {
+				paragraphs = append(paragraphs, p)
+				break
+			}
+		}
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	for _, p := range paragraphs {
+		splitParagraph(p, reader.Source())
+	}
+}
+
+func splitParagraph(p *ast.Paragraph, src []byte) {
+	parent := p.Parent()
+	if parent == nil {
+		return
+	}
+
+	var current *ast.Paragraph
+	for c := p.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		if pt, ok := c.(*passthroughInline); ok && pt.delims.block {
+			b := &passthroughBlock{delims: pt.delims, closed: true}
+			b.raw.Write(pt.raw)
+			parent.InsertBefore(parent, p, b)
+			current = nil
+		} else {
+			if current == nil {
+				current = ast.NewParagraph()
+				parent.InsertBefore(parent, p, current)
+			}
+			current.AppendChild(current, c)
+		}
+		c = next
+	}
+
+	parent.RemoveChild(parent, p)
+
+	// Remove any paragraph left with only white space.
+	for c := parent.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		if pp, ok := c.(*ast.Paragraph); ok && isBlankParagraph(pp, src) {
+			parent.RemoveChild(parent, pp)
+		}
+		c = next
+	}
+}
+
+func isBlankParagraph(p *ast.Paragraph, src []byte) bool {
+	for c := p.FirstChild(); c != nil; c = c.NextSibling() {
+		t, ok := c.(*ast.Text)
+		if !ok || !util.IsBlank(t.Segment.Value(src)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *htmlRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindPassthroughInline, r.renderPassthroughInline)
+	reg.Register(KindPassthroughBlock, r.renderPassthroughBlock)
+}
+
+func (r *htmlRenderer) renderPassthroughInline(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*passthroughInline)
+	return r.renderPassthrough(w, n, n.delims, n.raw, true, typeInline)
+}
+
+func (r *htmlRenderer) renderPassthroughBlock(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*passthroughBlock)
+	return r.renderPassthrough(w, n, n.delims, n.raw.Bytes(), n.closed, typeBlock)
+}
+
+func (r *htmlRenderer) renderPassthrough(w util.BufWriter, node ast.Node, delims delimiters, raw []byte, closed bool, typ string) (ast.WalkStatus, error) {
+	ctx := w.(*render.Context)
+	// Inline and block passthroughs are numbered separately.
+	ordinal := ctx.GetAndIncrementOrdinal(node.Kind())
+
+	renderer := ctx.RenderContext().GetRenderer(hooks.PassthroughRendererType, nil)
+	if renderer == nil {
+		// Write the region verbatim, delimiters included, for client side
+		// libraries such as KaTeX or MathJax to pick up.
+		_, _ = w.Write(util.EscapeHTML(raw))
+		if typ == typeBlock {
+			_ = w.WriteByte('\n')
+		}
+		return ast.WalkSkipChildren, nil
+	}
+
+	if !closed {
+		// An unterminated block, e.g. at the end of the document.
+		raw = append(append([]byte(nil), raw...), delims.close...)
+	}
+
+	pctx := &passthroughContext{
+		page:             ctx.DocumentContext().Document,
+		typ:              typ,
+		inner:            strings.TrimSpace(delims.inner(raw)),
+		ordinal:          ordinal,
+		AttributesHolder: attributes.New(node.Attributes(), attributes.AttributesOwnerGeneral),
+	}
+
+	pr := renderer.(hooks.PassthroughRenderer)
+
+	err := pr.RenderPassthrough(w, pctx)
+
+	ctx.AddIdentity(pr)
+
+	return ast.WalkSkipChildren, err
+}
+
+type passthroughContext struct {
+	page    interface{}
+	typ     string
+	inner   string
+	ordinal int
+
+	*attributes.AttributesHolder
+}
+
+func (c *passthroughContext) Page() interface{} {
+	return c.page
+}
+
+func (c *passthroughContext) Type() string {
+	return c.typ
+}
+
+func (c *passthroughContext) Inner() string {
+	return c.inner
+}
+
+func (c *passthroughContext) Ordinal() int {
+	return c.ordinal
+}
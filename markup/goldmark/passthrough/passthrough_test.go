@@ -0,0 +1,105 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package passthrough
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/gohugoio/hugo/common/hugio"
+	"github.com/gohugoio/hugo/identity"
+	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/gohugoio/hugo/markup/converter/hooks"
+	"github.com/gohugoio/hugo/markup/goldmark/goldmark_config"
+	"github.com/gohugoio/hugo/markup/goldmark/internal/render"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type testPassthroughRenderer struct{}
+
+func (r testPassthroughRenderer) RenderPassthrough(w hugio.FlexiWriter, ctx hooks.PassthroughContext) error {
+	_, err := fmt.Fprintf(w, "[%s:%d:%s]", ctx.Type(), ctx.Ordinal(), ctx.Inner())
+	return err
+}
+
+func (r testPassthroughRenderer) GetIdentity() identity.Identity {
+	return identity.NewPathIdentity("layouts", "render-passthrough")
+}
+
+func renderPassthrough(c *qt.C, src string) string {
+	c.Helper()
+
+	md := goldmark.New(goldmark.WithExtensions(New(goldmark_config.Passthrough{Enable: true})))
+	doc := md.Parser().Parse(text.NewReader([]byte(src)))
+
+	buf := &render.BufWriter{Buffer: &bytes.Buffer{}}
+	w := &render.Context{
+		BufWriter: buf,
+		ContextData: &render.RenderContextDataHolder{
+			Rctx: converter.RenderContext{
+				Src: []byte(src),
+				GetRenderer: func(t hooks.RendererType, id interface{}) interface{} {
+					if t == hooks.PassthroughRendererType {
+						return testPassthroughRenderer{}
+					}
+					return nil
+				},
+			},
+			IDs: identity.NewManager(identity.NewPathIdentity("test", "passthrough")),
+		},
+	}
+
+	c.Assert(md.Renderer().Render(w, []byte(src), doc), qt.IsNil)
+
+	return buf.String()
+}
+
+func TestPassthroughOrdinal(t *testing.T) {
+	c := qt.New(t)
+
+	got := renderPassthrough(c, `
+Inline $a$ and $b$.
+
+$$
+c
+$$
+
+Inline $d$.
+
+$$
+e
+$$
+`)
+
+	c.Assert(got, qt.Contains, "[inline:0:a]")
+	c.Assert(got, qt.Contains, "[inline:1:b]")
+	c.Assert(got, qt.Contains, "[block:0:c]")
+	c.Assert(got, qt.Contains, "[inline:2:d]")
+	c.Assert(got, qt.Contains, "[block:1:e]")
+}
+
+func TestPassthroughUnterminatedBlock(t *testing.T) {
+	c := qt.New(t)
+
+	// These must not panic.
+	for _, src := range []string{"$$\na", "$$\na\n", "$$\n\n", "$$"} {
+		renderPassthrough(c, src)
+	}
+
+	c.Assert(renderPassthrough(c, "$$\na\n"), qt.Contains, "[block:0:a]")
+}
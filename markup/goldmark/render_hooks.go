@@ -15,9 +15,11 @@ package goldmark
 
 import (
 	"bytes"
+	"regexp"
 	"strings"
 
 	"github.com/gohugoio/hugo/markup/converter/hooks"
+	"github.com/gohugoio/hugo/markup/goldmark/images"
 	"github.com/gohugoio/hugo/markup/goldmark/internal/render"
 	"github.com/gohugoio/hugo/markup/internal/attributes"
 
@@ -75,6 +77,21 @@ func (ctx linkContext) Title() string {
 	return ctx.title
 }
 
+type imageLinkContext struct {
+	linkContext
+	ordinal int
+	isBlock bool
+	*attributes.AttributesHolder
+}
+
+func (ctx imageLinkContext) IsBlock() bool {
+	return ctx.isBlock
+}
+
+func (ctx imageLinkContext) Ordinal() int {
+	return ctx.ordinal
+}
+
 type headingContext struct {
 	page      interface{}
 	level     int
@@ -104,6 +121,35 @@ func (ctx headingContext) PlainText() string {
 	return ctx.plainText
 }
 
+type blockquoteContext struct {
+	page      interface{}
+	text      string
+	typ       string
+	alertType string
+	ordinal   int
+	*attributes.AttributesHolder
+}
+
+func (ctx blockquoteContext) Page() interface{} {
+	return ctx.page
+}
+
+func (ctx blockquoteContext) Text() string {
+	return ctx.text
+}
+
+func (ctx blockquoteContext) Type() string {
+	return ctx.typ
+}
+
+func (ctx blockquoteContext) AlertType() string {
+	return ctx.alertType
+}
+
+func (ctx blockquoteContext) Ordinal() int {
+	return ctx.ordinal
+}
+
 type hookedRenderer struct {
 	html.Config
 }
@@ -118,18 +164,19 @@ func (r *hookedRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer)
 	reg.Register(ast.KindAutoLink, r.renderAutoLink)
 	reg.Register(ast.KindImage, r.renderImage)
 	reg.Register(ast.KindHeading, r.renderHeading)
+	reg.Register(ast.KindBlockquote, r.renderBlockquote)
 }
 
 func (r *hookedRenderer) renderImage(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Image)
-	var lr hooks.LinkRenderer
+	var ir hooks.ImageRenderer
 
 	ctx, ok := w.(*render.Context)
 	if ok {
 		h := ctx.RenderContext().GetRenderer(hooks.ImageRendererType, nil)
 		ok = h != nil
 		if ok {
-			lr = h.(hooks.LinkRenderer)
+			ir = h.(hooks.ImageRenderer)
 		}
 	}
 
@@ -147,18 +194,30 @@ func (r *hookedRenderer) renderImage(w util.BufWriter, source []byte, node ast.N
 	text := ctx.Buffer.Bytes()[pos:]
 	ctx.Buffer.Truncate(pos)
 
-	err := lr.RenderLink(
+	var attrs []ast.Attribute
+	for _, attr := range n.Attributes() {
+		if !images.IsInternalAttribute(attr.Name) {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	err := ir.RenderImage(
 		w,
-		linkContext{
-			page:        ctx.DocumentContext().Document,
-			destination: string(n.Destination),
-			title:       string(n.Title),
-			text:        string(text),
-			plainText:   string(n.Text(source)),
+		imageLinkContext{
+			linkContext: linkContext{
+				page:        ctx.DocumentContext().Document,
+				destination: string(n.Destination),
+				title:       string(n.Title),
+				text:        string(text),
+				plainText:   string(n.Text(source)),
+			},
+			ordinal:          ctx.GetAndIncrementOrdinal(ast.KindImage),
+			isBlock:          images.IsBlock(n),
+			AttributesHolder: attributes.New(attrs, attributes.AttributesOwnerGeneral),
 		},
 	)
 
-	ctx.AddIdentity(lr)
+	ctx.AddIdentity(ir)
 
 	return ast.WalkContinue, err
 }
@@ -182,11 +241,18 @@ func (r *hookedRenderer) renderImageDefault(w util.BufWriter, source []byte, nod
 		r.Writer.Write(w, n.Title)
 		_ = w.WriteByte('"')
 	}
+	if n.Attributes() != nil {
+		html.RenderAttributes(w, n, html.ImageAttributeFilter)
+	}
 	if r.XHTML {
 		_, _ = w.WriteString(" />")
 	} else {
 		_, _ = w.WriteString(">")
 	}
+	if images.IsBlock(n) && n.Parent().Kind() != ast.KindParagraph {
+		// Not wrapped in a paragraph.
+		_ = w.WriteByte('\n')
+	}
 	return ast.WalkSkipChildren, nil
 }
 
@@ -394,6 +460,102 @@ func (r *hookedRenderer) renderHeadingDefault(w util.BufWriter, source []byte, n
 	return ast.WalkContinue, nil
 }
 
+const (
+	blockquoteTypeRegular = "regular"
+	blockquoteTypeAlert   = "alert"
+)
+
+// gitHubAlertRe matches the GitHub alert marker, e.g. "> [!NOTE]", as rendered
+// at the start of the first paragraph of a blockquote.
+var gitHubAlertRe = regexp.MustCompile(`^<p>\[!(?i)(NOTE|TIP|IMPORTANT|WARNING|CAUTION)\](?:</p>\n|<br\s*/?>\n|\n)`)
+
+// resolveGitHubAlert returns the lower case alert type (one of note, tip, important, warning or caution)
+// and the text with the alert marker removed.
+// If text does not start with an alert marker, the alert type is empty and text is returned unchanged.
+func resolveGitHubAlert(text string) (string, string) {
+	m := gitHubAlertRe.FindStringSubmatch(text)
+	if m == nil {
+		return "", text
+	}
+	rest := text[len(m[0]):]
+	if !strings.HasSuffix(m[0], "</p>\n") {
+		// The marker shares the paragraph with the alert text.
+		rest = "<p>" + rest
+	}
+	return strings.ToLower(m[1]), rest
+}
+
+func (r *hookedRenderer) renderBlockquote(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.Blockquote)
+	var br hooks.BlockquoteRenderer
+
+	ctx, ok := w.(*render.Context)
+	if ok {
+		h := ctx.RenderContext().GetRenderer(hooks.BlockquoteRendererType, nil)
+		ok = h != nil
+		if ok {
+			br = h.(hooks.BlockquoteRenderer)
+		}
+	}
+
+	if !ok {
+		return r.renderBlockquoteDefault(w, source, node, entering)
+	}
+
+	if entering {
+		// Take the ordinal when entering, so an outer blockquote gets a lower
+		// ordinal than the blockquotes nested inside it.
+		ctx.PushValue(ast.KindBlockquote, ctx.GetAndIncrementOrdinal(ast.KindBlockquote))
+		// Store the current pos so we can capture the rendered text.
+		ctx.PushPos(ctx.Buffer.Len())
+		return ast.WalkContinue, nil
+	}
+
+	ordinal := ctx.PopValue(ast.KindBlockquote).(int)
+	pos := ctx.PopPos()
+	text := string(ctx.Buffer.Bytes()[pos:])
+	ctx.Buffer.Truncate(pos)
+
+	typ := blockquoteTypeRegular
+	alertType, text := resolveGitHubAlert(text)
+	if alertType != "" {
+		typ = blockquoteTypeAlert
+	}
+
+	err := br.RenderBlockquote(
+		w,
+		blockquoteContext{
+			page:             ctx.DocumentContext().Document,
+			text:             text,
+			typ:              typ,
+			alertType:        alertType,
+			ordinal:          ordinal,
+			AttributesHolder: attributes.New(n.Attributes(), attributes.AttributesOwnerGeneral),
+		},
+	)
+
+	ctx.AddIdentity(br)
+
+	return ast.WalkContinue, err
+}
+
+// Fall back to the default Goldmark render funcs. Method below borrowed from:
+// https://github.com/yuin/goldmark/blob/b611cd333a492416b56aa8d94b04a67bf0096ab2/renderer/html/html.go#L257
+func (r *hookedRenderer) renderBlockquoteDefault(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		if n.Attributes() != nil {
+			_, _ = w.WriteString("<blockquote")
+			html.RenderAttributes(w, n, html.BlockquoteAttributeFilter)
+			_ = w.WriteByte('>')
+		} else {
+			_, _ = w.WriteString("<blockquote>\n")
+		}
+	} else {
+		_, _ = w.WriteString("</blockquote>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
 type links struct{}
 
 // Extend implements goldmark.Extender.
@@ -0,0 +1,99 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables_test
+
+import (
+	"testing"
+
+	"github.com/gohugoio/hugo/hugolib"
+)
+
+func TestTableHook(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+[markup.goldmark.parser.attribute]
+block = true
+-- layouts/_default/_markup/render-table.html --
+Ordinal: {{ .Ordinal }}|
+Attributes: {{ .Attributes }}|
+{{ range .THead }}Head: {{ range . }}{{ .Text | safeHTML }}:{{ .Alignment }}|{{ end }}{{ end }}
+{{ range .TBody }}Row: {{ range . }}{{ .Text | safeHTML }}:{{ .Alignment }}|{{ end }}
+{{ end }}
+-- layouts/_default/single.html --
+{{ .Content }}
+-- content/p1.md --
+---
+title: "p1"
+---
+
+| Item  | In Stock | Price |
+| :---- | :------: | ----: |
+| Python Hat | **True** | 23.99 |
+| SQL Hat | False | 23.99 |
+{class="foo"}
+
+| A | B |
+|---|---|
+| 1 | 2 |
+`
+
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html",
+		"Ordinal: 0|",
+		"Attributes: map[class:foo]|",
+		"Head: Item:left|In Stock:center|Price:right|",
+		"Row: Python Hat:left|<strong>True</strong>:center|23.99:right|",
+		"Row: SQL Hat:left|False:center|23.99:right|",
+		"Ordinal: 1|",
+		"Head: A:|B:|",
+		"Row: 1:|2:|",
+	)
+}
+
+func TestTableNoHook(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- layouts/_default/single.html --
+{{ .Content }}
+-- content/p1.md --
+---
+title: "p1"
+---
+
+| A | B |
+|:--|---|
+| 1 | 2 |
+`
+
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html",
+		"<table>\n<thead>\n<tr>\n<th style=\"text-align:left\">A</th>\n<th>B</th>",
+		"<td style=\"text-align:left\">1</td>",
+	)
+}
@@ -0,0 +1,221 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tables adds support for table render hooks to Goldmark's GFM tables.
+package tables
+
+import (
+	"github.com/gohugoio/hugo/markup/converter/hooks"
+	"github.com/gohugoio/hugo/markup/goldmark/internal/render"
+	"github.com/gohugoio/hugo/markup/internal/attributes"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+type (
+	tablesExtension struct{}
+	htmlRenderer    struct {
+		// The Goldmark table renderer, used when no render hook is provided.
+		defaultRenderer renderer.NodeRenderer
+		defaultFuncs    nodeRendererFuncs
+	}
+)
+
+// New returns a Goldmark extension that renders tables using the
+// table render hook, if provided. It must be used together with extension.Table.
+func New() goldmark.Extender {
+	return &tablesExtension{}
+}
+
+func (e *tablesExtension) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(newHTMLRenderer(), 100),
+	))
+}
+
+func newHTMLRenderer() renderer.NodeRenderer {
+	r := &htmlRenderer{
+		defaultRenderer: extension.NewTableHTMLRenderer(),
+		defaultFuncs:    make(nodeRendererFuncs),
+	}
+	return r
+}
+
+var _ renderer.SetOptioner = (*htmlRenderer)(nil)
+
+func (r *htmlRenderer) SetOption(name renderer.OptionName, value interface{}) {
+	if so, ok := r.defaultRenderer.(renderer.SetOptioner); ok {
+		so.SetOption(name, value)
+	}
+}
+
+func (r *htmlRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	r.defaultRenderer.RegisterFuncs(r.defaultFuncs)
+
+	reg.Register(east.KindTable, r.renderTable)
+	reg.Register(east.KindTableHeader, r.renderHeader)
+	reg.Register(east.KindTableRow, r.renderRow)
+	reg.Register(east.KindTableCell, r.renderCell)
+}
+
+func (r *htmlRenderer) renderTable(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	ctx := w.(*render.Context)
+
+	if entering {
+		ordinal := ctx.GetAndIncrementOrdinal(east.KindTable)
+		renderer := ctx.RenderContext().GetRenderer(hooks.TableRendererType, nil)
+		if renderer == nil {
+			// Mark this table as rendered by Goldmark.
+			ctx.PushValue(east.KindTable, (*tableContext)(nil))
+			return r.defaultFuncs.render(w, src, node, entering)
+		}
+
+		ctx.PushValue(east.KindTable, &tableContext{
+			page:             ctx.DocumentContext().Document,
+			ordinal:          ordinal,
+			renderer:         renderer.(hooks.TableRenderer),
+			AttributesHolder: attributes.New(node.Attributes(), attributes.AttributesOwnerGeneral),
+		})
+
+		return ast.WalkContinue, nil
+	}
+
+	tctx, _ := ctx.PopValue(east.KindTable).(*tableContext)
+	if tctx == nil {
+		return r.defaultFuncs.render(w, src, node, entering)
+	}
+
+	err := tctx.renderer.RenderTable(w, tctx)
+
+	ctx.AddIdentity(tctx.renderer)
+
+	return ast.WalkContinue, err
+}
+
+func (r *htmlRenderer) renderHeader(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	ctx := w.(*render.Context)
+	tctx := currentTable(ctx)
+	if tctx == nil {
+		return r.defaultFuncs.render(w, src, node, entering)
+	}
+
+	if entering {
+		tctx.thead = append(tctx.thead, hooks.TableRow{})
+	}
+
+	return ast.WalkContinue, nil
+}
+
+func (r *htmlRenderer) renderRow(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	ctx := w.(*render.Context)
+	tctx := currentTable(ctx)
+	if tctx == nil {
+		return r.defaultFuncs.render(w, src, node, entering)
+	}
+
+	if entering {
+		tctx.tbody = append(tctx.tbody, hooks.TableRow{})
+	}
+
+	return ast.WalkContinue, nil
+}
+
+func (r *htmlRenderer) renderCell(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	ctx := w.(*render.Context)
+	tctx := currentTable(ctx)
+	if tctx == nil {
+		return r.defaultFuncs.render(w, src, node, entering)
+	}
+
+	if entering {
+		// Store the current pos so we can capture the rendered text.
+		ctx.PushPos(ctx.Buffer.Len())
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*east.TableCell)
+
+	pos := ctx.PopPos()
+	text := ctx.Buffer.Bytes()[pos:]
+	cell := hooks.TableCell{
+		Text:       string(text),
+		Alignment:  alignment(n.Alignment),
+		Attributes: attributes.New(n.Attributes(), attributes.AttributesOwnerGeneral).Attributes(),
+	}
+	ctx.Buffer.Truncate(pos)
+
+	if n.Parent().Kind() == east.KindTableHeader {
+		i := len(tctx.thead) - 1
+		tctx.thead[i] = append(tctx.thead[i], cell)
+	} else {
+		i := len(tctx.tbody) - 1
+		tctx.tbody[i] = append(tctx.tbody[i], cell)
+	}
+
+	return ast.WalkContinue, nil
+}
+
+func currentTable(ctx *render.Context) *tableContext {
+	tctx, _ := ctx.PeekValue(east.KindTable).(*tableContext)
+	return tctx
+}
+
+func alignment(a east.Alignment) string {
+	switch a {
+	case east.AlignLeft, east.AlignRight, east.AlignCenter:
+		return a.String()
+	default:
+		return ""
+	}
+}
+
+type tableContext struct {
+	page     interface{}
+	ordinal  int
+	thead    []hooks.TableRow
+	tbody    []hooks.TableRow
+	renderer hooks.TableRenderer
+
+	*attributes.AttributesHolder
+}
+
+func (c *tableContext) Page() interface{} {
+	return c.page
+}
+
+func (c *tableContext) THead() []hooks.TableRow {
+	return c.thead
+}
+
+func (c *tableContext) TBody() []hooks.TableRow {
+	return c.tbody
+}
+
+func (c *tableContext) Ordinal() int {
+	return c.ordinal
+}
+
+// nodeRendererFuncs collects the render funcs of a renderer.NodeRenderer.
+type nodeRendererFuncs map[ast.NodeKind]renderer.NodeRendererFunc
+
+func (f nodeRendererFuncs) Register(kind ast.NodeKind, fn renderer.NodeRendererFunc) {
+	f[kind] = fn
+}
+
+func (f nodeRendererFuncs) render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	return f[node.Kind()](w, src, node, entering)
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/gohugoio/hugo/common/hugio"
+	"github.com/gohugoio/hugo/identity"
+	"github.com/gohugoio/hugo/markup/converter"
+	"github.com/gohugoio/hugo/markup/converter/hooks"
+	"github.com/gohugoio/hugo/markup/goldmark/internal/render"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// cellAttributesTransformer sets a data-col attribute on every table cell.
+type cellAttributesTransformer struct{}
+
+func (t *cellAttributesTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && n.Kind() == east.KindTableCell {
+			col := 0
+			for c := n.PreviousSibling(); c != nil; c = c.PreviousSibling() {
+				col++
+			}
+			n.SetAttributeString("data-col", []byte(fmt.Sprint(col)))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+type testTableRenderer struct{}
+
+func (r testTableRenderer) RenderTable(w hugio.FlexiWriter, ctx hooks.TableContext) error {
+	for _, row := range append(ctx.THead(), ctx.TBody()...) {
+		for _, cell := range row {
+			fmt.Fprintf(w, "%s:%s:%v|", cell.Text, cell.Alignment, cell.Attributes)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func (r testTableRenderer) GetIdentity() identity.Identity {
+	return identity.NewPathIdentity("layouts", "render-table")
+}
+
+func TestTableCellAttributes(t *testing.T) {
+	c := qt.New(t)
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.Table, New()),
+		goldmark.WithParserOptions(
+			parser.WithASTTransformers(util.Prioritized(&cellAttributesTransformer{}, 1000)),
+		),
+	)
+
+	src := []byte(`
+| A | B |
+|:--|--:|
+| 1 | 2 |
+`)
+
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	buf := &render.BufWriter{Buffer: &bytes.Buffer{}}
+	w := &render.Context{
+		BufWriter: buf,
+		ContextData: &render.RenderContextDataHolder{
+			Rctx: converter.RenderContext{
+				Src: src,
+				GetRenderer: func(t hooks.RendererType, id interface{}) interface{} {
+					if t == hooks.TableRendererType {
+						return testTableRenderer{}
+					}
+					return nil
+				},
+			},
+			IDs: identity.NewManager(identity.NewPathIdentity("test", "tables")),
+		},
+	}
+
+	c.Assert(md.Renderer().Render(w, src, doc), qt.IsNil)
+	c.Assert(buf.String(), qt.Equals, "A:left:map[data-col:0]|B:right:map[data-col:1]|\n1:left:map[data-col:0]|2:right:map[data-col:1]|\n")
+}
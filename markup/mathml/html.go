@@ -0,0 +1,496 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mathml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ConvertHTML converts the LaTeX math expression src to static HTML.
+//
+// The HTML uses inline styles only, so it needs no stylesheet, fonts or
+// JavaScript in the browser. The layout is an approximation of what a
+// MathML renderer would produce.
+func ConvertHTML(src string, opts Options) (string, error) {
+	root, err := parse(src, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if opts.DisplayMode {
+		b.WriteString(`<span class="math math-display" style="display:block;text-align:center;margin:1em 0;` + htmlFontFamily + `">`)
+	} else {
+		b.WriteString(`<span class="math" style="white-space:nowrap;` + htmlFontFamily + `">`)
+	}
+	w := &htmlWriter{b: &b}
+	w.write(root, htmlStyle{display: opts.DisplayMode})
+	b.WriteString("</span>")
+
+	return b.String(), nil
+}
+
+const htmlFontFamily = `font-family:'Latin Modern Math','STIX Two Math','Cambria Math',serif`
+
+// htmlStyle holds the inherited style while writing HTML.
+type htmlStyle struct {
+	// Display style, e.g. full size fractions and limits under and over
+	// big operators.
+	display bool
+
+	// Inside a script, where operators are not spaced.
+	script bool
+}
+
+type htmlWriter struct {
+	b *strings.Builder
+}
+
+func (w *htmlWriter) open(styles ...string) {
+	w.b.WriteString(`<span style="`)
+	w.b.WriteString(escape(strings.Join(styles, ";")))
+	w.b.WriteString(`">`)
+}
+
+func (w *htmlWriter) close() {
+	w.b.WriteString("</span>")
+}
+
+func (w *htmlWriter) write(e *element, st htmlStyle) {
+	switch e.name {
+	case "mi":
+		variant := e.getAttr("mathvariant")
+		if variant == "" {
+			if utf8.RuneCountInString(e.text) == 1 {
+				variant = "italic"
+			} else {
+				variant = "normal"
+			}
+		}
+		if e.function {
+			w.open("margin-right:0.1667em")
+			w.writeText(e.text, variant)
+			w.close()
+			return
+		}
+		w.writeText(e.text, variant)
+	case "mn":
+		w.writeText(e.text, e.getAttr("mathvariant"))
+	case "mtext":
+		w.open("white-space:pre")
+		w.writeText(e.text, e.getAttr("mathvariant"))
+		w.close()
+	case "mo":
+		w.writeOperator(e, st, true, 1)
+	case "mspace":
+		w.open("display:inline-block", "width:"+e.getAttr("width"))
+		w.close()
+	case "mstyle":
+		if v := e.getAttr("displaystyle"); v != "" {
+			st.display = v == "true"
+		}
+		if color := e.getAttr("mathcolor"); color != "" {
+			w.open("color:" + color)
+			w.writeRow(e.children, st)
+			w.close()
+			return
+		}
+		w.writeRow(e.children, st)
+	case "msup":
+		w.write(e.children[0], st)
+		w.writeScript(e.children[1], st, "vertical-align:0.5em")
+	case "msub":
+		w.write(e.children[0], st)
+		w.writeScript(e.children[1], st, "vertical-align:-0.3em")
+	case "msubsup":
+		w.write(e.children[0], st)
+		w.open("display:inline-flex", "flex-direction:column", "vertical-align:middle", "line-height:1")
+		w.writeScript(e.children[2], st)
+		w.writeScript(e.children[1], st)
+		w.close()
+	case "mover":
+		w.writeStack(e, e.children[0], e.children[1], nil, st)
+	case "munder":
+		w.writeStack(e, e.children[0], nil, e.children[1], st)
+	case "munderover":
+		w.writeStack(e, e.children[0], e.children[2], e.children[1], st)
+	case "mfrac":
+		w.writeFraction(e, st)
+	case "msqrt":
+		w.writeRoot(e.children[0], nil, st)
+	case "mroot":
+		w.writeRoot(e.children[0], e.children[1], st)
+	case "menclose":
+		if e.getAttr("notation") == "box" {
+			w.open("display:inline-block", "border:1px solid", "padding:0.1em 0.2em")
+		} else {
+			w.open("background:linear-gradient(to top right,transparent calc(50% - 0.5px),currentColor,transparent calc(50% + 0.5px))")
+		}
+		w.writeRow(e.children, st)
+		w.close()
+	case "mtable":
+		w.writeTable(e, st)
+	default:
+		w.writeRow(e.children, st)
+	}
+}
+
+// writeRow writes elems in a row, spacing infix operators and stretching
+// fences to the height of the row.
+func (w *htmlWriter) writeRow(elems []*element, st htmlStyle) {
+	h := 1.0
+	for _, e := range elems {
+		if eh := height(e); eh > h {
+			h = eh
+		}
+	}
+
+	var prev *element
+	for _, e := range elems {
+		if e.name == "mo" {
+			// An operator first in a row or after another operator,
+			// e.g. a minus sign, is a prefix and not spaced.
+			prefix := prev == nil || (prev.name == "mo" && !closingChars[prev.text])
+			w.writeOperator(e, st, prefix, h)
+		} else {
+			w.write(e, st)
+		}
+		prev = e
+	}
+}
+
+func (w *htmlWriter) writeOperator(e *element, st htmlStyle, prefix bool, rowHeight float64) {
+	if e.text == "\u2061" {
+		// Function application is invisible.
+		return
+	}
+
+	var styles []string
+
+	if !st.script {
+		r, _ := utf8.DecodeRuneInString(e.text)
+		switch {
+		case e.getAttr("lspace") != "":
+			styles = append(styles, "margin:0 "+e.getAttr("lspace"))
+		case relationChars[r]:
+			styles = append(styles, "margin:0 0.2778em")
+		case binaryChars[r] && !prefix:
+			styles = append(styles, "margin:0 0.2222em")
+		case e.text == "," || e.text == ";":
+			styles = append(styles, "margin-right:0.1667em")
+		}
+	}
+
+	if largeOperatorChars[e.text] {
+		if st.display {
+			styles = append(styles, "font-size:1.6em", "vertical-align:-0.25em")
+		} else {
+			styles = append(styles, "font-size:1.2em", "vertical-align:-0.1em")
+		}
+	}
+
+	scale := 1.0
+	if size := e.getAttr("minsize"); size != "" {
+		if f, err := strconv.ParseFloat(strings.TrimSuffix(size, "em"), 64); err == nil {
+			scale = f
+		}
+	} else if e.getAttr("stretchy") == "true" {
+		scale = rowHeight
+	}
+	if scale > 1 {
+		styles = append(styles, "display:inline-block", fmt.Sprintf("transform:scaleY(%.2f)", scale))
+	}
+
+	if len(styles) == 0 {
+		w.b.WriteString(escape(e.text))
+		return
+	}
+	w.open(styles...)
+	w.b.WriteString(escape(e.text))
+	w.close()
+}
+
+// writeScript writes a sub- or superscript.
+func (w *htmlWriter) writeScript(e *element, st htmlStyle, styles ...string) {
+	st.script = true
+	st.display = false
+	w.open(append([]string{"font-size:70%"}, styles...)...)
+	w.write(e, st)
+	w.close()
+}
+
+// writeStack writes over and under, both optional, centered above and below
+// base, aligned on the baseline of base.
+func (w *htmlWriter) writeStack(e, base, over, under *element, st htmlStyle) {
+	accent := e.getAttr("accent") == "true"
+	accentUnder := e.getAttr("accentunder") == "true"
+
+	// Lines drawn above or below the base.
+	if accent && over.text == "‾" {
+		w.open("display:inline-block", "border-top:1px solid")
+		w.write(base, st)
+		w.close()
+		return
+	}
+	if accentUnder && under.text == "_" {
+		w.open("display:inline-block", "border-bottom:1px solid")
+		w.write(base, st)
+		w.close()
+		return
+	}
+
+	// The baseline of an inline-block is that of its last line, the
+	// baseline of an inline-table that of its first row.
+	w.open("display:inline-block", "text-align:center")
+	if over != nil {
+		if accent {
+			w.open("display:block", "line-height:0.5")
+			w.write(over, st)
+			w.close()
+		} else {
+			w.open("display:block", "line-height:1")
+			w.writeScript(over, st)
+			w.close()
+		}
+	}
+	w.open("display:inline-table")
+	w.open("display:table-row")
+	w.write(base, st)
+	w.close()
+	if under != nil {
+		w.open("display:table-row", "line-height:1")
+		if accentUnder {
+			w.write(under, st)
+		} else {
+			w.writeScript(under, st)
+		}
+		w.close()
+	}
+	w.close()
+	w.close()
+}
+
+func (w *htmlWriter) writeFraction(e *element, st htmlStyle) {
+	styles := []string{"display:inline-block", "vertical-align:middle", "text-align:center", "margin:0 0.1em"}
+	if !st.display && !st.script {
+		styles = append(styles, "font-size:80%")
+	}
+	inner := st
+	inner.display = false
+
+	w.open(styles...)
+	if e.getAttr("linethickness") == "0" {
+		w.open("display:block", "padding:0 0.1em")
+	} else {
+		w.open("display:block", "padding:0 0.1em", "border-bottom:1px solid")
+	}
+	w.write(e.children[0], inner)
+	w.close()
+	w.open("display:block", "padding:0 0.1em")
+	w.write(e.children[1], inner)
+	w.close()
+	w.close()
+}
+
+// writeRoot writes a square root or, if index is set, a root.
+func (w *htmlWriter) writeRoot(arg, index *element, st htmlStyle) {
+	w.open("white-space:nowrap")
+	if index != nil {
+		w.writeScript(index, st, "vertical-align:0.8em", "margin-right:-0.4em")
+	}
+	w.b.WriteString("√")
+	w.open("display:inline-block", "border-top:1px solid", "padding:0.1em 0.1em 0")
+	w.write(arg, st)
+	w.close()
+	w.close()
+}
+
+func (w *htmlWriter) writeTable(e *element, st htmlStyle) {
+	if v := e.getAttr("displaystyle"); v != "" {
+		st.display = v == "true"
+	}
+	aligns := strings.Fields(e.getAttr("columnalign"))
+	spacing := strings.Fields(e.getAttr("columnspacing"))
+
+	styles := []string{"display:inline-table", "vertical-align:middle"}
+	if e.getAttr("scriptlevel") == "1" {
+		styles = append(styles, "font-size:70%")
+	}
+
+	w.open(styles...)
+	for _, tr := range e.children {
+		w.open("display:table-row")
+		for i, td := range tr.children {
+			align := "center"
+			if len(aligns) > 0 {
+				align = aligns[min(i, len(aligns)-1)]
+			}
+			left, right := "0.4em", "0.4em"
+			if len(spacing) > 0 {
+				left, right = "0", "0"
+				if i > 0 {
+					left = spacing[min(i-1, len(spacing)-1)]
+				}
+			}
+			w.open("display:table-cell", "padding:0.2em "+right+" 0.2em "+left, "text-align:"+align)
+			w.writeRow(td.children, st)
+			w.close()
+		}
+		w.close()
+	}
+	w.close()
+}
+
+// writeText writes s in the given math variant.
+func (w *htmlWriter) writeText(s, variant string) {
+	switch variant {
+	case "", "normal":
+		w.b.WriteString(escape(s))
+	case "italic":
+		w.open("font-style:italic")
+		w.b.WriteString(escape(s))
+		w.close()
+	case "bold":
+		w.open("font-weight:bold")
+		w.b.WriteString(escape(s))
+		w.close()
+	case "bold-italic":
+		w.open("font-weight:bold", "font-style:italic")
+		w.b.WriteString(escape(s))
+		w.close()
+	default:
+		w.b.WriteString(escape(mapVariant(s, variant)))
+	}
+}
+
+// height estimates the height of e in lines, used to stretch fences.
+func height(e *element) float64 {
+	switch e.name {
+	case "mfrac":
+		return height(e.children[0]) + height(e.children[1])
+	case "mtable":
+		var h float64
+		for _, tr := range e.children {
+			rh := 1.0
+			for _, td := range tr.children {
+				if th := height(td); th > rh {
+					rh = th
+				}
+			}
+			h += rh
+		}
+		return h
+	case "mover", "munder":
+		return height(e.children[0]) + 0.5
+	case "munderover":
+		return height(e.children[0]) + 1
+	}
+
+	h := 1.0
+	for _, c := range e.children {
+		if ch := height(c); ch > h {
+			h = ch
+		}
+	}
+	return h
+}
+
+func (e *element) getAttr(k string) string {
+	for _, a := range e.attrs {
+		if a[0] == k {
+			return a[1]
+		}
+	}
+	return ""
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Operators spaced as relations, e.g. =, and binary operators, e.g. +.
+var relationChars, binaryChars = runeSet("=<>:≤≥≠≈≡∼≃≅∝≪≫≺≻⪯⪰∈∉∋⊂⊃⊆⊇⊑⊒⊥∥∣∤⊢⊣⊨≍≐≔≲≳⩽⩾→←↔⇒⇐⇔⟹⟸⟺↦⟶⟵⟷⟼↑↓↕⇑⇓↗↘↙↖↪↩⇀↼⇌"),
+	runeSet("+−±∓×÷⋅∗⋆∘∙⊕⊖⊗⊘⊙∪∩∖∧∨⊔⊓⊎⨿†‡≀⋄")
+
+// Closing delimiters, after which an operator is infix.
+var closingChars = map[string]bool{
+	")": true, "]": true, "}": true, "⟩": true, "⌋": true, "⌉": true, "|": true, "‖": true, "′": true,
+}
+
+var largeOperatorChars = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, op := range largeOperators {
+		m[op.s] = true
+	}
+	return m
+}()
+
+func runeSet(s string) map[rune]bool {
+	m := make(map[rune]bool)
+	for _, r := range s {
+		m[r] = true
+	}
+	return m
+}
+
+// The first code points of the letters A-Z and a-z and the digits 0-9 in the
+// Mathematical Alphanumeric Symbols block for the variants without a
+// CSS equivalent.
+var variantOffsets = map[string]struct{ letters, digits rune }{
+	"double-struck": {0x1D538, 0x1D7D8},
+	"script":        {0x1D49C, 0},
+	"fraktur":       {0x1D504, 0},
+	"sans-serif":    {0x1D5A0, 0x1D7E2},
+	"monospace":     {0x1D670, 0x1D7F6},
+}
+
+// Letters in the variants above that are encoded in the Letterlike Symbols
+// block instead.
+var variantExceptions = map[string]map[rune]rune{
+	"double-struck": {'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ'},
+	"script": {
+		'B': 'ℬ', 'E': 'ℰ', 'F': 'ℱ', 'H': 'ℋ', 'I': 'ℐ', 'L': 'ℒ', 'M': 'ℳ', 'R': 'ℛ',
+		'e': 'ℯ', 'g': 'ℊ', 'o': 'ℴ',
+	},
+	"fraktur": {'C': 'ℭ', 'H': 'ℌ', 'I': 'ℑ', 'R': 'ℜ', 'Z': 'ℨ'},
+}
+
+// mapVariant maps the ASCII letters and digits in s to their Unicode
+// mathematical variant, e.g. R to ℝ for double-struck.
+func mapVariant(s, variant string) string {
+	offsets, found := variantOffsets[variant]
+	if !found {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if ex, found := variantExceptions[variant][r]; found {
+			return ex
+		}
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return offsets.letters + r - 'A'
+		case r >= 'a' && r <= 'z':
+			return offsets.letters + 26 + r - 'a'
+		case r >= '0' && r <= '9' && offsets.digits != 0:
+			return offsets.digits + r - '0'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,906 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mathml converts LaTeX math expressions to MathML or static HTML.
+//
+// It supports the commonly used subset of LaTeX math: scripts, fractions,
+// roots, Greek letters and symbols, named functions, big operators with limits,
+// fonts, accents, \left/\right delimiters, text and matrix-like environments.
+package mathml
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Options configures the conversion.
+type Options struct {
+	// Render the expression in display (block) mode.
+	DisplayMode bool
+}
+
+// Convert converts the LaTeX math expression src to a MathML <math> element.
+func Convert(src string, opts Options) (string, error) {
+	root, err := parse(src, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if opts.DisplayMode {
+		b.WriteString(` display="block"`)
+	}
+	b.WriteString("><semantics>")
+	root.write(&b)
+	b.WriteString(`<annotation encoding="application/x-tex">`)
+	b.WriteString(escape(strings.TrimSpace(src)))
+	b.WriteString("</annotation></semantics></math>")
+
+	return b.String(), nil
+}
+
+// parse parses src into a tree of MathML elements.
+func parse(src string, opts Options) (*element, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{toks: toks, display: opts.DisplayMode}
+	elems, err := p.parseExpr(stopAtEOF)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.val, t.pos)
+	}
+
+	return row(elems), nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokCommand
+	tokText // The raw argument of e.g. \text.
+	tokLetter
+	tokNumber
+	tokChar
+	tokOpenBrace
+	tokCloseBrace
+	tokSup
+	tokSub
+	tokAmp
+	tokNewline
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+	pos  int
+}
+
+// Commands that take a raw text argument.
+var textCommands = map[string]bool{
+	"text": true, "textrm": true, "textbf": true, "textit": true, "mbox": true,
+	"operatorname": true, "begin": true, "end": true, "color": true, "textcolor": true,
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+
+	for i := 0; i < len(src); {
+		r, w := utf8.DecodeRuneInString(src[i:])
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i += w
+		case r == '%':
+			// Comment.
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case r == '\\':
+			i++
+			if i >= len(src) {
+				return nil, fmt.Errorf("unexpected end of input after \\")
+			}
+			if isLetter(src[i]) {
+				j := i
+				for j < len(src) && isLetter(src[j]) {
+					j++
+				}
+				name := src[i:j]
+				if j < len(src) && src[j] == '*' && (name == "begin" || name == "end") {
+					// Not valid LaTeX, but be lenient.
+					j++
+				}
+				i = j
+				toks = append(toks, token{kind: tokCommand, val: name, pos: start})
+				if textCommands[name] {
+					text, n, ok := rawGroup(src[i:])
+					if ok {
+						toks = append(toks, token{kind: tokText, val: text, pos: i})
+						i += n
+					}
+				}
+			} else if src[i] == '\\' {
+				i++
+				toks = append(toks, token{kind: tokNewline, val: `\\`, pos: start})
+			} else {
+				_, w := utf8.DecodeRuneInString(src[i:])
+				toks = append(toks, token{kind: tokCommand, val: src[i : i+w], pos: start})
+				i += w
+			}
+		case r >= '0' && r <= '9':
+			j := i
+			for j < len(src) && (isDigit(src[j]) || (src[j] == '.' && j+1 < len(src) && isDigit(src[j+1]))) {
+				j++
+			}
+			toks = append(toks, token{kind: tokNumber, val: src[i:j], pos: start})
+			i = j
+		case unicode.IsLetter(r):
+			toks = append(toks, token{kind: tokLetter, val: string(r), pos: start})
+			i += w
+		case r == '{':
+			toks = append(toks, token{kind: tokOpenBrace, val: "{", pos: start})
+			i += w
+		case r == '}':
+			toks = append(toks, token{kind: tokCloseBrace, val: "}", pos: start})
+			i += w
+		case r == '^':
+			toks = append(toks, token{kind: tokSup, val: "^", pos: start})
+			i += w
+		case r == '_':
+			toks = append(toks, token{kind: tokSub, val: "_", pos: start})
+			i += w
+		case r == '&':
+			toks = append(toks, token{kind: tokAmp, val: "&", pos: start})
+			i += w
+		default:
+			toks = append(toks, token{kind: tokChar, val: string(r), pos: start})
+			i += w
+		}
+	}
+
+	return append(toks, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// rawGroup returns the content of the brace group at the start of s,
+// ignoring leading white space, and the number of bytes consumed.
+func rawGroup(s string) (string, int, bool) {
+	i := 0
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+		i++
+	}
+	if i >= len(s) || s[i] != '{' {
+		return "", 0, false
+	}
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[i+1 : j], j + 1, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// element is a MathML element.
+type element struct {
+	name     string
+	attrs    [][2]string
+	text     string
+	children []*element
+
+	// Whether this is a big operator or function that takes its
+	// scripts as limits (under/over) in display mode.
+	limits bool
+	// Whether this is a named function, e.g. sin.
+	function bool
+}
+
+func (e *element) attr(k, v string) *element {
+	e.attrs = append(e.attrs, [2]string{k, v})
+	return e
+}
+
+func (e *element) write(b *strings.Builder) {
+	b.WriteString("<")
+	b.WriteString(e.name)
+	for _, a := range e.attrs {
+		fmt.Fprintf(b, ` %s="%s"`, a[0], escape(a[1]))
+	}
+	if e.text == "" && len(e.children) == 0 && e.name == "mspace" {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteString(">")
+	b.WriteString(escape(e.text))
+	for _, c := range e.children {
+		c.write(b)
+	}
+	b.WriteString("</")
+	b.WriteString(e.name)
+	b.WriteString(">")
+}
+
+func newElement(name, text string) *element {
+	return &element{name: name, text: text}
+}
+
+func newElementChildren(name string, children ...*element) *element {
+	return &element{name: name, children: children}
+}
+
+// row wraps elems in an <mrow> unless there is exactly one element.
+func row(elems []*element) *element {
+	if len(elems) == 1 {
+		return elems[0]
+	}
+	return newElementChildren("mrow", elems...)
+}
+
+func mo(s string) *element {
+	return newElement("mo", s)
+}
+
+func fence(s string) *element {
+	return mo(s).attr("fence", "true").attr("stretchy", "true")
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\u2061", "&#x2061;")
+
+func escape(s string) string {
+	return xmlEscaper.Replace(s)
+}
+
+type parser struct {
+	toks    []token
+	pos     int
+	display bool
+	variant string
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func stopAtEOF(t token) bool {
+	return t.kind == tokEOF
+}
+
+func stopAtCloseBrace(t token) bool {
+	return t.kind == tokEOF || t.kind == tokCloseBrace
+}
+
+// parseExpr parses a list of elements until stop returns true for the next token.
+func (p *parser) parseExpr(stop func(t token) bool) ([]*element, error) {
+	var elems []*element
+	for {
+		t := p.peek()
+		if stop(t) {
+			return elems, nil
+		}
+		if t.kind == tokCloseBrace {
+			return nil, fmt.Errorf("unexpected } at position %d", t.pos)
+		}
+		if t.kind == tokAmp || t.kind == tokNewline {
+			return nil, fmt.Errorf("unexpected %s at position %d outside of an environment", t.val, t.pos)
+		}
+
+		if t.kind == tokCommand {
+			switch t.val {
+			case "displaystyle", "textstyle":
+				// Applies to the rest of the group.
+				p.next()
+				rest, err := p.parseExpr(stop)
+				if err != nil {
+					return nil, err
+				}
+				style := newElementChildren("mstyle", rest...).attr("displaystyle", fmt.Sprint(t.val == "displaystyle"))
+				return append(elems, style), nil
+			case "color":
+				// Applies to the rest of the group.
+				p.next()
+				color, err := p.textArg(t)
+				if err != nil {
+					return nil, err
+				}
+				rest, err := p.parseExpr(stop)
+				if err != nil {
+					return nil, err
+				}
+				return append(elems, newElementChildren("mstyle", rest...).attr("mathcolor", color)), nil
+			}
+		}
+
+		base, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := p.parseScripts(base)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, e)
+		if base != nil && base.function {
+			// Function application.
+			elems = append(elems, mo("\u2061"))
+		}
+	}
+}
+
+// parseScripts parses any sub- and superscripts following base.
+func (p *parser) parseScripts(base *element) (*element, error) {
+	var sub, sup *element
+	var primes string
+
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokSup:
+			p.next()
+			if sup != nil {
+				return nil, fmt.Errorf("double superscript at position %d", t.pos)
+			}
+			arg, err := p.parseArg()
+			if err != nil {
+				return nil, err
+			}
+			sup = arg
+		case t.kind == tokSub:
+			p.next()
+			if sub != nil {
+				return nil, fmt.Errorf("double subscript at position %d", t.pos)
+			}
+			arg, err := p.parseArg()
+			if err != nil {
+				return nil, err
+			}
+			sub = arg
+		case t.kind == tokChar && t.val == "'":
+			p.next()
+			primes += "′"
+		case t.kind == tokCommand && (t.val == "limits" || t.val == "nolimits"):
+			p.next()
+			if base != nil {
+				base.limits = t.val == "limits"
+			}
+		default:
+			if primes != "" {
+				pe := mo(primes)
+				if sup == nil {
+					sup = pe
+				} else {
+					sup = newElementChildren("mrow", pe, sup)
+				}
+			}
+			if sub == nil && sup == nil {
+				return base, nil
+			}
+			if base == nil {
+				base = newElement("mrow", "")
+			}
+			under := base.limits && p.display
+			switch {
+			case sub != nil && sup != nil:
+				name := "msubsup"
+				if under {
+					name = "munderover"
+				}
+				return newElementChildren(name, base, sub, sup), nil
+			case sub != nil:
+				name := "msub"
+				if under {
+					name = "munder"
+				}
+				return newElementChildren(name, base, sub), nil
+			default:
+				name := "msup"
+				if under {
+					name = "mover"
+				}
+				return newElementChildren(name, base, sup), nil
+			}
+		}
+	}
+}
+
+// parseArg parses a command argument, either a group or a single token.
+func (p *parser) parseArg() (*element, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokEOF:
+		return nil, fmt.Errorf("missing argument at position %d", t.pos)
+	case tokNumber:
+		if len(t.val) > 1 {
+			// E.g. \frac12 or x^23, only the first digit is the argument.
+			p.toks[p.pos].val = t.val[1:]
+			p.toks[p.pos].pos++
+			return newElement("mn", t.val[:1]), nil
+		}
+	case tokSup, tokSub, tokCloseBrace, tokAmp, tokNewline:
+		return nil, fmt.Errorf("missing argument at position %d", t.pos)
+	}
+	return p.parseAtom()
+}
+
+// textArg returns the raw text argument of the command t.
+func (p *parser) textArg(t token) (string, error) {
+	if p.peek().kind != tokText {
+		return "", fmt.Errorf("missing argument to \\%s at position %d", t.val, t.pos)
+	}
+	return p.next().val, nil
+}
+
+func (p *parser) parseGroup() (*element, error) {
+	elems, err := p.parseExpr(stopAtCloseBrace)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokCloseBrace {
+		return nil, fmt.Errorf("missing } at position %d", t.pos)
+	}
+	return row(elems), nil
+}
+
+func (p *parser) identifier(s string) *element {
+	e := newElement("mi", s)
+	if p.variant != "" {
+		e.attr("mathvariant", p.variant)
+	}
+	return e
+}
+
+// parseAtom parses a single element, e.g. an identifier, a group or a command with its arguments.
+// It returns nil for elements without any output, e.g. \limits.
+func (p *parser) parseAtom() (*element, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLetter:
+		return p.identifier(t.val), nil
+	case tokNumber:
+		e := newElement("mn", t.val)
+		if p.variant == "bold" || p.variant == "bold-italic" {
+			e.attr("mathvariant", "bold")
+		}
+		return e, nil
+	case tokChar:
+		switch t.val {
+		case "-":
+			return mo("−"), nil
+		case "*":
+			return mo("∗"), nil
+		case "'":
+			return mo("′"), nil
+		case "(", ")", "[", "]", "|":
+			return mo(t.val).attr("stretchy", "false"), nil
+		}
+		return mo(t.val), nil
+	case tokOpenBrace:
+		return p.parseGroup()
+	case tokSup, tokSub:
+		// A script without a base, e.g. {}^{14}C or ^2.
+		p.pos--
+		return nil, nil
+	case tokCommand:
+		return p.parseCommand(t)
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of input")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.val, t.pos)
+}
+
+func (p *parser) parseCommand(t token) (*element, error) {
+	name := t.val
+
+	if s, ok := identifiers[name]; ok {
+		return p.identifier(s), nil
+	}
+	if s, ok := uprightIdentifiers[name]; ok {
+		return newElement("mi", s).attr("mathvariant", "normal"), nil
+	}
+	if s, ok := operators[name]; ok {
+		return mo(s), nil
+	}
+	if op, ok := largeOperators[name]; ok {
+		e := mo(op.s)
+		e.limits = op.limits
+		if op.limits {
+			e.attr("movablelimits", "true")
+		}
+		return e, nil
+	}
+	if limits, ok := functions[name]; ok {
+		e := newElement("mi", name)
+		e.function = true
+		e.limits = limits
+		return e, nil
+	}
+	if w, ok := spaces[name]; ok {
+		return newElement("mspace", "").attr("width", w), nil
+	}
+	if size, ok := bigSizes[name]; ok {
+		d, err := p.parseDelimiter(t)
+		if err != nil {
+			return nil, err
+		}
+		return mo(d).attr("minsize", size).attr("maxsize", size), nil
+	}
+	if variant, ok := fontVariants[name]; ok {
+		old := p.variant
+		p.variant = variant
+		defer func() { p.variant = old }()
+		return p.parseArg()
+	}
+	if a, ok := accents[name]; ok {
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		return newElementChildren("mover", arg, mo(a.s).attr("stretchy", fmt.Sprint(a.stretch))).attr("accent", "true"), nil
+	}
+	if s, ok := underAccents[name]; ok {
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		return newElementChildren("munder", arg, mo(s).attr("stretchy", "true")).attr("accentunder", "true"), nil
+	}
+
+	switch name {
+	case "{", "}":
+		return mo(name).attr("stretchy", "false"), nil
+	case "|":
+		return mo("‖").attr("stretchy", "false"), nil
+	case "%", "$", "#", "&", "_":
+		return mo(name), nil
+	case "frac", "dfrac", "tfrac", "cfrac":
+		num, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		den, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		frac := newElementChildren("mfrac", num, den)
+		switch name {
+		case "dfrac", "cfrac":
+			return newElementChildren("mstyle", frac).attr("displaystyle", "true"), nil
+		case "tfrac":
+			return newElementChildren("mstyle", frac).attr("displaystyle", "false"), nil
+		}
+		return frac, nil
+	case "binom", "dbinom", "tbinom":
+		n, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		k, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		frac := newElementChildren("mfrac", n, k).attr("linethickness", "0")
+		return newElementChildren("mrow", mo("("), frac, mo(")")), nil
+	case "sqrt":
+		var index *element
+		if t := p.peek(); t.kind == tokChar && t.val == "[" {
+			p.next()
+			elems, err := p.parseExpr(func(t token) bool {
+				return t.kind == tokEOF || (t.kind == tokChar && t.val == "]")
+			})
+			if err != nil {
+				return nil, err
+			}
+			if t := p.next(); t.kind == tokEOF {
+				return nil, fmt.Errorf("missing ] at position %d", t.pos)
+			}
+			index = row(elems)
+		}
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		if index != nil {
+			return newElementChildren("mroot", arg, index), nil
+		}
+		return newElementChildren("msqrt", arg), nil
+	case "text", "textrm", "mbox":
+		s, err := p.textArg(t)
+		if err != nil {
+			return nil, err
+		}
+		return newElement("mtext", s), nil
+	case "textbf":
+		s, err := p.textArg(t)
+		if err != nil {
+			return nil, err
+		}
+		return newElement("mtext", s).attr("mathvariant", "bold"), nil
+	case "textit":
+		s, err := p.textArg(t)
+		if err != nil {
+			return nil, err
+		}
+		return newElement("mtext", s).attr("mathvariant", "italic"), nil
+	case "operatorname":
+		s, err := p.textArg(t)
+		if err != nil {
+			return nil, err
+		}
+		e := newElement("mi", s)
+		if utf8.RuneCountInString(s) == 1 {
+			e.attr("mathvariant", "normal")
+		}
+		e.function = true
+		return e, nil
+	case "textcolor":
+		color, err := p.textArg(t)
+		if err != nil {
+			return nil, err
+		}
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		return newElementChildren("mstyle", arg).attr("mathcolor", color), nil
+	case "boxed", "fbox":
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		return newElementChildren("menclose", arg).attr("notation", "box"), nil
+	case "cancel":
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		return newElementChildren("menclose", arg).attr("notation", "updiagonalstrike"), nil
+	case "overset", "stackrel", "underset":
+		over, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		base, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		if name == "underset" {
+			return newElementChildren("munder", base, over), nil
+		}
+		return newElementChildren("mover", base, over), nil
+	case "not":
+		next, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		if next == nil || next.name != "mo" {
+			return nil, fmt.Errorf("\\not must be followed by a relation at position %d", t.pos)
+		}
+		next.text += "̸"
+		return next, nil
+	case "pmod":
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		return newElementChildren("mrow",
+			newElement("mspace", "").attr("width", "1em"),
+			mo("("), newElement("mi", "mod"), newElement("mspace", "").attr("width", "0.3333em"), arg, mo(")")), nil
+	case "left":
+		return p.parseLeftRight(t)
+	case "right", "middle":
+		return nil, fmt.Errorf("\\%s without matching \\left at position %d", name, t.pos)
+	case "begin":
+		return p.parseEnvironment(t)
+	case "end":
+		return nil, fmt.Errorf("\\end without matching \\begin at position %d", t.pos)
+	}
+
+	return nil, fmt.Errorf("undefined control sequence \\%s at position %d", name, t.pos)
+}
+
+// parseDelimiter parses the delimiter following e.g. \left.
+func (p *parser) parseDelimiter(cmd token) (string, error) {
+	t := p.next()
+	switch t.kind {
+	case tokChar:
+		if delimiterChars[t.val] || t.val == "<" || t.val == ">" {
+			switch t.val {
+			case ".":
+				return "", nil
+			case "<":
+				return "⟨", nil
+			case ">":
+				return "⟩", nil
+			}
+			return t.val, nil
+		}
+	case tokCommand:
+		switch t.val {
+		case "{", "}":
+			return t.val, nil
+		case "|":
+			return "‖", nil
+		}
+		if s, ok := operators[t.val]; ok {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("missing or invalid delimiter after \\%s at position %d", cmd.val, cmd.pos)
+}
+
+func (p *parser) parseLeftRight(left token) (*element, error) {
+	open, err := p.parseDelimiter(left)
+	if err != nil {
+		return nil, err
+	}
+
+	elems := []*element{fence(open)}
+	for {
+		inner, err := p.parseExpr(func(t token) bool {
+			return t.kind == tokEOF || (t.kind == tokCommand && (t.val == "right" || t.val == "middle"))
+		})
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, inner...)
+
+		t := p.next()
+		if t.kind == tokEOF {
+			return nil, fmt.Errorf("missing \\right for \\left at position %d", left.pos)
+		}
+		d, err := p.parseDelimiter(t)
+		if err != nil {
+			return nil, err
+		}
+		if t.val == "middle" {
+			elems = append(elems, fence(d).attr("lspace", "0.05em").attr("rspace", "0.05em"))
+			continue
+		}
+		elems = append(elems, fence(d))
+		return newElementChildren("mrow", elems...), nil
+	}
+}
+
+func (p *parser) parseEnvironment(begin token) (*element, error) {
+	name, err := p.textArg(begin)
+	if err != nil {
+		return nil, err
+	}
+	delims, ok := matrixEnvironments[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown environment %q at position %d", name, begin.pos)
+	}
+
+	var columnAlign []string
+	if name == "array" {
+		// The column specification, e.g. {cc|l}.
+		if p.peek().kind != tokOpenBrace {
+			return nil, fmt.Errorf("missing column specification for array at position %d", begin.pos)
+		}
+		p.next()
+		for t := p.next(); t.kind != tokCloseBrace; t = p.next() {
+			if t.kind == tokEOF {
+				return nil, fmt.Errorf("missing } at position %d", t.pos)
+			}
+			switch t.val {
+			case "l":
+				columnAlign = append(columnAlign, "left")
+			case "c":
+				columnAlign = append(columnAlign, "center")
+			case "r":
+				columnAlign = append(columnAlign, "right")
+			}
+		}
+	}
+
+	stopAtCell := func(t token) bool {
+		return t.kind == tokEOF || t.kind == tokAmp || t.kind == tokNewline || (t.kind == tokCommand && t.val == "end")
+	}
+
+	table := newElement("mtable", "")
+	var tr *element
+	for {
+		if tr == nil {
+			tr = newElement("mtr", "")
+			table.children = append(table.children, tr)
+		}
+		cell, err := p.parseExpr(stopAtCell)
+		if err != nil {
+			return nil, err
+		}
+		tr.children = append(tr.children, newElementChildren("mtd", cell...))
+
+		t := p.next()
+		switch t.kind {
+		case tokAmp:
+		case tokNewline:
+			tr = nil
+		case tokCommand:
+			endName, err := p.textArg(t)
+			if err != nil {
+				return nil, err
+			}
+			if endName != name {
+				return nil, fmt.Errorf("\\begin{%s} ended by \\end{%s} at position %d", name, endName, t.pos)
+			}
+			return p.finishEnvironment(name, delims, columnAlign, table), nil
+		default:
+			return nil, fmt.Errorf("missing \\end{%s} for \\begin{%s} at position %d", name, name, begin.pos)
+		}
+	}
+}
+
+func (p *parser) finishEnvironment(name string, delims [2]string, columnAlign []string, table *element) *element {
+	// Drop an empty last row, e.g. from a trailing \\.
+	if n := len(table.children); n > 1 {
+		last := table.children[n-1]
+		if len(last.children) == 1 && len(last.children[0].children) == 0 {
+			table.children = table.children[:n-1]
+		}
+	}
+
+	switch name {
+	case "cases", "rcases":
+		table.attr("columnalign", "left left")
+	case "aligned", "align", "align*", "split":
+		table.attr("columnalign", "right left right left right left").attr("columnspacing", "0em 2em 0em 2em 0em")
+		table.attr("displaystyle", "true")
+	case "gathered", "gather", "gather*":
+		table.attr("displaystyle", "true")
+	case "array":
+		if len(columnAlign) > 0 {
+			table.attr("columnalign", strings.Join(columnAlign, " "))
+		}
+	case "smallmatrix":
+		table.attr("scriptlevel", "1")
+	}
+
+	if delims[0] == "" && delims[1] == "" {
+		return table
+	}
+
+	return newElementChildren("mrow", fence(delims[0]), table, fence(delims[1]))
+}
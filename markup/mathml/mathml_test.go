@@ -0,0 +1,128 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mathml
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestConvert(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		name    string
+		src     string
+		display bool
+		expect  interface{}
+	}{
+		{"Identifiers", `x + y`, false, `<mrow><mi>x</mi><mo>+</mo><mi>y</mi></mrow>`},
+		{"Number", `3.14`, false, `<mn>3.14</mn>`},
+		{"Minus", `a-b`, false, `<mo>−</mo>`},
+		{"Greek", `\alpha\Omega`, false, `<mi>α</mi><mi mathvariant="normal">Ω</mi>`},
+		{"Superscript", `x^2`, false, `<msup><mi>x</mi><mn>2</mn></msup>`},
+		{"Subscript group", `a_{ij}`, false, `<msub><mi>a</mi><mrow><mi>i</mi><mi>j</mi></mrow></msub>`},
+		{"Subsup", `x_1^2`, false, `<msubsup><mi>x</mi><mn>1</mn><mn>2</mn></msubsup>`},
+		{"Digit argument", `x^23`, false, `<msup><mi>x</mi><mn>2</mn></msup><mn>3</mn>`},
+		{"Prime", `f'(x)`, false, `<msup><mi>f</mi><mo>′</mo></msup>`},
+		{"Fraction", `\frac{a}{b}`, false, `<mfrac><mi>a</mi><mi>b</mi></mfrac>`},
+		{"Fraction short", `\frac12`, false, `<mfrac><mn>1</mn><mn>2</mn></mfrac>`},
+		{"Sqrt", `\sqrt{x}`, false, `<msqrt><mi>x</mi></msqrt>`},
+		{"Root", `\sqrt[3]{x}`, false, `<mroot><mi>x</mi><mn>3</mn></mroot>`},
+		{"Sum inline", `\sum_{i=1}^n i`, false, `<msubsup><mo movablelimits="true">∑</mo>`},
+		{"Sum display", `\sum_{i=1}^n i`, true, `<munderover><mo movablelimits="true">∑</mo>`},
+		{"Function", `\sin x`, false, `<mi>sin</mi><mo>&#x2061;</mo><mi>x</mi>`},
+		{"Limit display", `\lim_{x \to 0}`, true, `<munder><mi>lim</mi><mrow><mi>x</mi><mo>→</mo><mn>0</mn></mrow></munder>`},
+		{"Font", `\mathbb{R}`, false, `<mi mathvariant="double-struck">R</mi>`},
+		{"Accent", `\hat{x}`, false, `<mover accent="true"><mi>x</mi><mo stretchy="false">^</mo></mover>`},
+		{"Text", `\text{if } x<0`, false, `<mtext>if </mtext><mi>x</mi><mo>&lt;</mo><mn>0</mn>`},
+		{"Left right", `\left( \frac{a}{b} \right)`, false, `<mrow><mo fence="true" stretchy="true">(</mo><mfrac>`},
+		{"Space", `a\quad b`, false, `<mspace width="1em"/>`},
+		{"Not", `a \not= b`, false, `<mo>≠</mo>`},
+		{"Color", `\textcolor{red}{x}`, false, `<mstyle mathcolor="red"><mi>x</mi></mstyle>`},
+		{"Matrix", `\begin{pmatrix} a & b \\ c & d \end{pmatrix}`, false, `<mtable><mtr><mtd><mi>a</mi></mtd><mtd><mi>b</mi></mtd></mtr><mtr><mtd><mi>c</mi></mtd><mtd><mi>d</mi></mtd></mtr></mtable>`},
+		{"Cases", `f(x) = \begin{cases} 1 & x > 0 \\ 0 & \text{otherwise} \end{cases}`, false, `<mtable columnalign="left left">`},
+		{"Comment", "x % a comment\n+ y", false, `<mrow><mi>x</mi><mo>+</mo><mi>y</mi></mrow>`},
+		{"Annotation", `a<b`, false, `<annotation encoding="application/x-tex">a&lt;b</annotation>`},
+		{"Display", `x`, true, `<math xmlns="http://www.w3.org/1998/Math/MathML" display="block"><semantics><mi>x</mi>`},
+		{"Unknown command", `\foo`, false, false},
+		{"Unbalanced group", `{x`, false, false},
+		{"Unbalanced close", `x}`, false, false},
+		{"Missing right", `\left( x`, false, false},
+		{"Mismatched environment", `\begin{matrix} a \end{pmatrix}`, false, false},
+		{"Double superscript", `x^1^2`, false, false},
+	} {
+		test := test
+		c.Run(test.name, func(c *qt.C) {
+			result, err := Convert(test.src, Options{DisplayMode: test.display})
+			if b, ok := test.expect.(bool); ok && !b {
+				c.Assert(err, qt.Not(qt.IsNil))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(result, qt.Contains, test.expect.(string))
+			c.Assert(strings.HasPrefix(result, "<math "), qt.IsTrue)
+			c.Assert(strings.HasSuffix(result, "</math>"), qt.IsTrue)
+		})
+	}
+}
+
+func TestConvertHTML(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		name    string
+		src     string
+		display bool
+		expect  interface{}
+	}{
+		{"Identifier", `x`, false, `<span style="font-style:italic">x</span>`},
+		{"Function", `\sin x`, false, `<span style="margin-right:0.1667em">sin</span><span style="font-style:italic">x</span>`},
+		{"Relation", `a=b`, false, `<span style="margin:0 0.2778em">=</span>`},
+		{"Binary", `a+b`, false, `<span style="margin:0 0.2222em">+</span>`},
+		{"Prefix minus", `-a`, false, `<span class="math" style="white-space:nowrap;` + htmlFontFamily + `">−<span`},
+		{"Superscript", `x^2`, false, `<span style="font-style:italic">x</span><span style="font-size:70%;vertical-align:0.5em">2</span>`},
+		{"No spacing in scripts", `x^{a+b}`, false, `<span style="font-style:italic">a</span>+<span`},
+		{"Fraction", `\frac{a}{b}`, false, `<span style="display:inline-block;vertical-align:middle;text-align:center;margin:0 0.1em;font-size:80%"><span style="display:block;padding:0 0.1em;border-bottom:1px solid">`},
+		{"Fraction display", `\frac{a}{b}`, true, `<span style="display:inline-block;vertical-align:middle;text-align:center;margin:0 0.1em"><span`},
+		{"Binomial", `\binom{n}{k}`, false, `<span style="display:block;padding:0 0.1em"><span style="font-style:italic">n</span>`},
+		{"Sqrt", `\sqrt{x}`, false, `√<span style="display:inline-block;border-top:1px solid;padding:0.1em 0.1em 0">`},
+		{"Limits display", `\sum_{i=1}^n`, true, `<span style="display:block;line-height:1"><span style="font-size:70%"><span style="font-style:italic">n</span></span></span><span style="display:inline-table"><span style="display:table-row"><span style="font-size:1.6em;vertical-align:-0.25em">∑</span>`},
+		{"Stretchy fences", `\left( \frac{a}{b} \right)`, true, `<span style="display:inline-block;transform:scaleY(2.00)">(</span>`},
+		{"Double-struck", `\mathbb{R}^n`, false, `ℝ`},
+		{"Fraktur", `\mathfrak{g}`, false, "\U0001D524"},
+		{"Bold", `\mathbf{v}`, false, `<span style="font-weight:bold">v</span>`},
+		{"Text", `\text{if } x`, false, `<span style="white-space:pre">if </span>`},
+		{"Color", `\textcolor{red}{x}`, false, `<span style="color:red">`},
+		{"Matrix", `\begin{pmatrix} a & b \\ c & d \end{pmatrix}`, false, `<span style="display:table-row"><span style="display:table-cell;padding:0.2em 0.4em 0.2em 0.4em;text-align:center">`},
+		{"Cases", `\begin{cases} 1 & x > 0 \end{cases}`, false, `text-align:left`},
+		{"Escape", `a<b`, false, `<span style="margin:0 0.2778em">&lt;</span>`},
+		{"Unknown command", `\foo`, false, false},
+	} {
+		test := test
+		c.Run(test.name, func(c *qt.C) {
+			result, err := ConvertHTML(test.src, Options{DisplayMode: test.display})
+			if b, ok := test.expect.(bool); ok && !b {
+				c.Assert(err, qt.Not(qt.IsNil))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(result, qt.Contains, test.expect.(string))
+			c.Assert(result, qt.Not(qt.Contains), "<m")
+			c.Assert(strings.Count(result, "<span"), qt.Equals, strings.Count(result, "</span>"))
+		})
+	}
+}
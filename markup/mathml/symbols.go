@@ -0,0 +1,187 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mathml
+
+// Identifiers, rendered as <mi>.
+var identifiers = map[string]string{
+	// Greek lower case.
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "omicron": "ο", "pi": "π", "varpi": "ϖ",
+	"rho": "ρ", "varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ",
+	"phi": "ϕ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+
+	// Other identifiers.
+	"infty": "∞", "partial": "∂", "nabla": "∇", "ell": "ℓ", "hbar": "ℏ", "imath": "ı", "jmath": "ȷ",
+	"emptyset": "∅", "varnothing": "∅", "aleph": "ℵ", "beth": "ℶ", "Re": "ℜ", "Im": "ℑ", "wp": "℘",
+	"top": "⊤", "bot": "⊥", "angle": "∠", "triangle": "△", "prime": "′", "degree": "°",
+}
+
+// Upright identifiers, e.g. capital Greek letters.
+var uprightIdentifiers = map[string]string{
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+// Operators, relations, arrows and punctuation, rendered as <mo>.
+var operators = map[string]string{
+	// Binary operators.
+	"pm": "±", "mp": "∓", "times": "×", "div": "÷", "cdot": "⋅", "ast": "∗", "star": "⋆",
+	"circ": "∘", "bullet": "∙", "oplus": "⊕", "ominus": "⊖", "otimes": "⊗", "oslash": "⊘",
+	"odot": "⊙", "cup": "∪", "cap": "∩", "setminus": "∖", "wedge": "∧", "land": "∧",
+	"vee": "∨", "lor": "∨", "sqcup": "⊔", "sqcap": "⊓", "uplus": "⊎", "amalg": "⨿",
+	"dagger": "†", "ddagger": "‡", "wr": "≀", "diamond": "⋄",
+
+	// Relations.
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈",
+	"equiv": "≡", "sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝", "ll": "≪", "gg": "≫",
+	"prec": "≺", "succ": "≻", "preceq": "⪯", "succeq": "⪰", "in": "∈", "notin": "∉", "ni": "∋",
+	"subset": "⊂", "supset": "⊃", "subseteq": "⊆", "supseteq": "⊇", "sqsubseteq": "⊑",
+	"sqsupseteq": "⊒", "perp": "⊥", "parallel": "∥", "mid": "∣", "nmid": "∤", "vdash": "⊢",
+	"dashv": "⊣", "models": "⊨", "asymp": "≍", "doteq": "≐", "coloneqq": "≔", "lesssim": "≲",
+	"gtrsim": "≳", "leqslant": "⩽", "geqslant": "⩾",
+
+	// Arrows.
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔",
+	"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "implies": "⟹", "impliedby": "⟸",
+	"iff": "⟺", "mapsto": "↦", "longrightarrow": "⟶", "longleftarrow": "⟵",
+	"longleftrightarrow": "⟷", "Longrightarrow": "⟹", "Longleftarrow": "⟸",
+	"Longleftrightarrow": "⟺", "longmapsto": "⟼", "uparrow": "↑", "downarrow": "↓",
+	"updownarrow": "↕", "Uparrow": "⇑", "Downarrow": "⇓", "nearrow": "↗", "searrow": "↘",
+	"swarrow": "↙", "nwarrow": "↖", "hookrightarrow": "↪", "hookleftarrow": "↩",
+	"rightharpoonup": "⇀", "leftharpoonup": "↼", "rightleftharpoons": "⇌",
+
+	// Logic and sets.
+	"forall": "∀", "exists": "∃", "nexists": "∄", "neg": "¬", "lnot": "¬", "therefore": "∴",
+	"because": "∵",
+
+	// Dots.
+	"ldots": "…", "dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+
+	// Delimiters.
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+	"lvert": "|", "rvert": "|", "vert": "|", "lVert": "‖", "rVert": "‖", "Vert": "‖",
+	"backslash": "∖", "lbrace": "{", "rbrace": "}", "lbrack": "[", "rbrack": "]",
+
+	// Misc.
+	"colon": ":", "bmod": "mod",
+}
+
+// Large operators, rendered as <mo largeop="true">.
+// The value tells whether the operator takes limits in display mode.
+var largeOperators = map[string]struct {
+	s      string
+	limits bool
+}{
+	"sum": {"∑", true}, "prod": {"∏", true}, "coprod": {"∐", true},
+	"bigcup": {"⋃", true}, "bigcap": {"⋂", true}, "bigvee": {"⋁", true}, "bigwedge": {"⋀", true},
+	"bigoplus": {"⨁", true}, "bigotimes": {"⨂", true}, "bigodot": {"⨀", true}, "biguplus": {"⨄", true},
+	"bigsqcup": {"⨆", true},
+	"int":      {"∫", false}, "iint": {"∬", false}, "iiint": {"∭", false}, "oint": {"∮", false},
+}
+
+// Named functions, rendered upright. The value tells whether the function
+// takes limits in display mode.
+var functions = map[string]bool{
+	"sin": false, "cos": false, "tan": false, "cot": false, "sec": false, "csc": false,
+	"arcsin": false, "arccos": false, "arctan": false, "sinh": false, "cosh": false,
+	"tanh": false, "coth": false, "log": false, "ln": false, "lg": false, "exp": false,
+	"deg": false, "dim": false, "ker": false, "arg": false, "hom": false,
+	"lim": true, "liminf": true, "limsup": true, "max": true, "min": true, "sup": true,
+	"inf": true, "det": true, "gcd": true, "Pr": true,
+}
+
+// Characters allowed as delimiters after \left, \right and \big etc.
+var delimiterChars = map[string]bool{
+	"(": true, ")": true, "[": true, "]": true, "|": true, "/": true, ".": true,
+}
+
+// Math variants set by the font commands.
+var fontVariants = map[string]string{
+	"mathrm":     "normal",
+	"mathbf":     "bold",
+	"mathit":     "italic",
+	"mathbb":     "double-struck",
+	"mathcal":    "script",
+	"mathscr":    "script",
+	"mathfrak":   "fraktur",
+	"mathsf":     "sans-serif",
+	"mathtt":     "monospace",
+	"boldsymbol": "bold-italic",
+	"bm":         "bold-italic",
+}
+
+// Accents, rendered as <mover accent="true">.
+// The bool tells whether the accent should stretch.
+var accents = map[string]struct {
+	s       string
+	stretch bool
+}{
+	"hat": {"^", false}, "widehat": {"^", true}, "check": {"ˇ", false}, "tilde": {"~", false},
+	"widetilde": {"~", true}, "acute": {"´", false}, "grave": {"`", false}, "dot": {"˙", false},
+	"ddot": {"¨", false}, "breve": {"˘", false}, "bar": {"¯", false}, "vec": {"→", false},
+	"overline": {"‾", true}, "overrightarrow": {"→", true}, "overleftarrow": {"←", true},
+	"overbrace": {"⏞", true},
+}
+
+// Under accents, rendered as <munder accentunder="true">.
+var underAccents = map[string]string{
+	"underline":  "_",
+	"underbrace": "⏟",
+}
+
+// Spacing commands and their widths.
+var spaces = map[string]string{
+	",":            "0.1667em",
+	"thinspace":    "0.1667em",
+	":":            "0.2222em",
+	">":            "0.2222em",
+	"medspace":     "0.2222em",
+	";":            "0.2778em",
+	"thickspace":   "0.2778em",
+	"!":            "-0.1667em",
+	"negthinspace": "-0.1667em",
+	" ":            "0.25em",
+	"quad":         "1em",
+	"qquad":        "2em",
+}
+
+// Sizes for \big and friends.
+var bigSizes = map[string]string{
+	"big": "1.2em", "bigl": "1.2em", "bigr": "1.2em", "bigm": "1.2em",
+	"Big": "1.623em", "Bigl": "1.623em", "Bigr": "1.623em", "Bigm": "1.623em",
+	"bigg": "2.047em", "biggl": "2.047em", "biggr": "2.047em", "biggm": "2.047em",
+	"Bigg": "2.470em", "Biggl": "2.470em", "Biggr": "2.470em", "Biggm": "2.470em",
+}
+
+// Matrix like environments and their delimiters.
+var matrixEnvironments = map[string][2]string{
+	"matrix":      {"", ""},
+	"smallmatrix": {"", ""},
+	"pmatrix":     {"(", ")"},
+	"bmatrix":     {"[", "]"},
+	"Bmatrix":     {"{", "}"},
+	"vmatrix":     {"|", "|"},
+	"Vmatrix":     {"‖", "‖"},
+	"cases":       {"{", ""},
+	"rcases":      {"", "}"},
+	"aligned":     {"", ""},
+	"align":       {"", ""},
+	"align*":      {"", ""},
+	"gathered":    {"", ""},
+	"gather":      {"", ""},
+	"gather*":     {"", ""},
+	"split":       {"", ""},
+	"array":       {"", ""},
+}
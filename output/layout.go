@@ -45,6 +45,10 @@ type LayoutDescriptor struct {
 
 	RenderingHook bool
 	Baseof        bool
+
+	// OutputFormatOnly indicates that we should only look for layouts with
+	// the output format name in them, e.g. "index.searchindex.json".
+	OutputFormatOnly bool
 }
 
 func (d LayoutDescriptor) isList() bool {
@@ -225,14 +229,21 @@ func (l *layoutBuilder) resolveVariations() []string {
 	var variations []string
 	name := strings.ToLower(l.f.Name)
 
+	formatOnly := l.d.OutputFormatOnly && !l.d.Baseof
+
 	if l.d.Lang != "" {
 		// We prefer the most specific type before language.
-		variations = append(variations, []string{l.d.Lang + "." + name, name, l.d.Lang}...)
+		variations = append(variations, []string{l.d.Lang + "." + name, name}...)
+		if !formatOnly {
+			variations = append(variations, l.d.Lang)
+		}
 	} else {
 		variations = append(variations, name)
 	}
 
-	variations = append(variations, "")
+	if !formatOnly {
+		variations = append(variations, "")
+	}
 
 	for _, typeVar := range l.typeVariations {
 		for _, variation := range variations {
@@ -947,6 +947,25 @@ func TestLayout(t *testing.T) {
 				"_default/baseof.html",
 			},
 		},
+		{
+			"Home, output format only, french language",
+			LayoutDescriptor{Kind: "home", Lang: "fr", OutputFormatOnly: true},
+			"", SearchIndexFormat,
+			[]string{
+				"index.fr.searchindex.json",
+				"home.fr.searchindex.json",
+				"list.fr.searchindex.json",
+				"index.searchindex.json",
+				"home.searchindex.json",
+				"list.searchindex.json",
+				"_default/index.fr.searchindex.json",
+				"_default/home.fr.searchindex.json",
+				"_default/list.fr.searchindex.json",
+				"_default/index.searchindex.json",
+				"_default/home.searchindex.json",
+				"_default/list.searchindex.json",
+			},
+		},
 		{
 			"Content hook",
 			LayoutDescriptor{Kind: "render-link", RenderingHook: true, Layout: "mylayout", Section: "blog"},
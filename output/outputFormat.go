@@ -168,6 +168,19 @@ var (
 		Rel:       "alternate",
 	}
 
+	// SearchIndexFormat is rendered by Hugo's full-text search indexer if
+	// no template is provided. See the searchindex package.
+	// It is not in DefaultFormats, but added to the site's formats if
+	// configured in outputs.
+	SearchIndexFormat = Format{
+		Name:           "SearchIndex",
+		MediaType:      media.JSONType,
+		BaseName:       "searchindex",
+		IsPlainText:    true,
+		NotAlternative: true,
+		Rel:            "search",
+	}
+
 	SitemapFormat = Format{
 		Name:      "Sitemap",
 		MediaType: media.XMLType,
@@ -68,6 +68,12 @@ func TestDefaultTypes(t *testing.T) {
 	c.Assert(RSSFormat.NoUgly, qt.Equals, true)
 	c.Assert(CalendarFormat.IsHTML, qt.Equals, false)
 
+	c.Assert(SearchIndexFormat.Name, qt.Equals, "SearchIndex")
+	c.Assert(SearchIndexFormat.MediaType, qt.Equals, media.JSONType)
+	c.Assert(SearchIndexFormat.BaseName, qt.Equals, "searchindex")
+	c.Assert(SearchIndexFormat.IsPlainText, qt.Equals, true)
+	c.Assert(SearchIndexFormat.NotAlternative, qt.Equals, true)
+
 	c.Assert(len(DefaultFormats), qt.Equals, 10)
 
 }
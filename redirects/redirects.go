@@ -0,0 +1,344 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redirects builds server side redirect maps for web servers and hosts.
+package redirects
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// Redirect map formats.
+const (
+	// FormatNetlify is a Netlify _redirects file.
+	FormatNetlify = "netlify"
+
+	// FormatNginx is an nginx config file with one map per status code.
+	FormatNginx = "nginx"
+
+	// FormatApache is an Apache .htaccess file.
+	FormatApache = "apache"
+
+	// FormatCaddy is a Caddyfile snippet.
+	FormatCaddy = "caddy"
+
+	// FormatJSON is a JSON manifest.
+	FormatJSON = "json"
+)
+
+var filenames = map[string]string{
+	FormatNetlify: "_redirects",
+	FormatNginx:   "redirects.nginx.conf",
+	FormatApache:  ".htaccess",
+	FormatCaddy:   "redirects.caddy",
+	FormatJSON:    "redirects.json",
+}
+
+// Filename returns the name of the file to publish the redirect map in
+// format to, or an empty string if format is not supported.
+func Filename(format string) string {
+	return filenames[format]
+}
+
+// DefaultConfig is the default redirect map config.
+var DefaultConfig = Config{
+	Status: http.StatusMovedPermanently,
+}
+
+/*
+Config configures the redirect maps.
+
+An example site config.toml:
+
+	[redirectMap]
+	formats = ["netlify", "nginx"]
+	status = 308
+*/
+type Config struct {
+	// The redirect map formats to publish, any of netlify, nginx, apache,
+	// caddy and json. No redirect maps are published if empty.
+	Formats []string
+
+	// The HTTP status code for page aliases and for redirects in the
+	// redirects config without one. Default is 301.
+	Status int
+}
+
+// DecodeConfig creates a redirect map config from m, using DefaultConfig
+// for any missing values.
+func DecodeConfig(m maps.Params) (Config, error) {
+	c := DefaultConfig
+	if m == nil {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(m, &c); err != nil {
+		return c, err
+	}
+
+	for i, f := range c.Formats {
+		f = strings.ToLower(f)
+		if Filename(f) == "" {
+			return c, errors.Errorf("unsupported redirect map format %q", f)
+		}
+		c.Formats[i] = f
+	}
+
+	if !isValidStatus(c.Status) {
+		return c, errors.Errorf("unsupported redirect status %d", c.Status)
+	}
+
+	return c, nil
+}
+
+// Redirect redirects requests for the From path to To.
+type Redirect struct {
+	// The path to redirect from, e.g. /old/.
+	From string `json:"from"`
+
+	// The path or URL to redirect to.
+	To string `json:"to"`
+
+	// The HTTP status code. 200 rewrites the request to To without
+	// redirecting the client.
+	Status int `json:"status"`
+}
+
+// IsZero returns true if r is the zero value.
+func (r Redirect) IsZero() bool {
+	return r.From == ""
+}
+
+// DecodeRedirects decodes a list of redirects, e.g. the redirects config,
+// using status for the redirects without a status code.
+func DecodeRedirects(v interface{}, status int) ([]Redirect, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	var redirects []Redirect
+	if err := mapstructure.WeakDecode(v, &redirects); err != nil {
+		return nil, errors.Wrap(err, "failed to decode redirects")
+	}
+
+	for i, r := range redirects {
+		if r.From == "" || r.To == "" {
+			return nil, errors.Errorf("redirect %d must have both from and to set", i+1)
+		}
+		if !strings.HasPrefix(r.From, "/") {
+			return nil, errors.Errorf("redirect from %q must be an absolute path, e.g. \"/old/\"", r.From)
+		}
+		if r.Status == 0 {
+			r.Status = status
+		}
+		if !isValidStatus(r.Status) {
+			return nil, errors.Errorf("unsupported status %d in redirect from %q", r.Status, r.From)
+		}
+		redirects[i] = r
+	}
+
+	return redirects, nil
+}
+
+func isValidStatus(status int) bool {
+	switch status {
+	case http.StatusOK,
+		http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusSeeOther,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// Map is a set of redirects, sorted by their From path.
+type Map struct {
+	redirects []Redirect
+	index     map[string]int
+}
+
+// NewMap creates a new Map. If more than one redirect has the same From
+// path, the first one wins. Redirects to self are skipped.
+func NewMap(redirects []Redirect) *Map {
+	m := &Map{index: make(map[string]int)}
+	seen := make(map[string]bool)
+	for _, r := range redirects {
+		if seen[r.From] || r.From == r.To {
+			continue
+		}
+		seen[r.From] = true
+		m.redirects = append(m.redirects, r)
+	}
+
+	sort.SliceStable(m.redirects, func(i, j int) bool {
+		return m.redirects[i].From < m.redirects[j].From
+	})
+
+	for i, r := range m.redirects {
+		m.index[r.From] = i
+	}
+
+	return m
+}
+
+// Redirects returns the redirects in m.
+func (m *Map) Redirects() []Redirect {
+	if m == nil {
+		return nil
+	}
+	return m.redirects
+}
+
+// Match returns the redirect for the request path p, if any. A request for
+// /old/index.html or /old matches a redirect from /old/.
+func (m *Map) Match(p string) Redirect {
+	if m == nil || len(m.redirects) == 0 {
+		return Redirect{}
+	}
+
+	candidates := []string{p}
+	if strings.HasSuffix(p, "/index.html") {
+		candidates = append(candidates, strings.TrimSuffix(p, "index.html"))
+	} else if !strings.HasSuffix(p, "/") && path.Ext(p) == "" {
+		candidates = append(candidates, p+"/")
+	}
+
+	for _, c := range candidates {
+		if i, found := m.index[c]; found {
+			return m.redirects[i]
+		}
+	}
+
+	return Redirect{}
+}
+
+// Write writes the redirects in m to w in the given format.
+func (m *Map) Write(w io.Writer, format string) error {
+	bw := bufio.NewWriter(w)
+
+	var err error
+	switch format {
+	case FormatNetlify:
+		m.writeNetlify(bw)
+	case FormatNginx:
+		m.writeNginx(bw)
+	case FormatApache:
+		m.writeApache(bw)
+	case FormatCaddy:
+		m.writeCaddy(bw)
+	case FormatJSON:
+		err = m.writeJSON(bw)
+	default:
+		return errors.Errorf("unsupported redirect map format %q", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func (m *Map) writeNetlify(w io.Writer) {
+	for _, r := range m.Redirects() {
+		fmt.Fprintf(w, "%s %s %d\n", r.From, r.To, r.Status)
+	}
+}
+
+// writeNginx writes one map per status code, as nginx cannot return a
+// status code from a variable.
+func (m *Map) writeNginx(w io.Writer) {
+	for i, status := range m.statuses() {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		name := fmt.Sprintf("$hugo_redirect_%d", status)
+		fmt.Fprintln(w, "# Include in the http block and use in a server block:")
+		if status == http.StatusOK {
+			fmt.Fprintf(w, "#   if (%s) { rewrite ^ %s last; }\n", name, name)
+		} else {
+			fmt.Fprintf(w, "#   if (%s) { return %d %s; }\n", name, status, name)
+		}
+		fmt.Fprintf(w, "map $uri %s {\n", name)
+		for _, r := range m.Redirects() {
+			if r.Status == status {
+				fmt.Fprintf(w, "    \"%s\" \"%s\";\n", r.From, r.To)
+			}
+		}
+		fmt.Fprintln(w, "}")
+	}
+}
+
+func (m *Map) writeApache(w io.Writer) {
+	var rewriteEngineOn bool
+	for _, r := range m.Redirects() {
+		pattern := "^" + regexp.QuoteMeta(r.From) + "$"
+		if r.Status == http.StatusOK {
+			if !rewriteEngineOn {
+				fmt.Fprintln(w, "RewriteEngine On")
+				rewriteEngineOn = true
+			}
+			fmt.Fprintf(w, "RewriteCond %%{REQUEST_URI} \"%s\"\n", pattern)
+			fmt.Fprintf(w, "RewriteRule ^ \"%s\" [L]\n", r.To)
+			continue
+		}
+		fmt.Fprintf(w, "RedirectMatch %d \"%s\" \"%s\"\n", r.Status, pattern, r.To)
+	}
+}
+
+func (m *Map) writeCaddy(w io.Writer) {
+	for _, r := range m.Redirects() {
+		if r.Status == http.StatusOK {
+			fmt.Fprintf(w, "rewrite \"%s\" \"%s\"\n", r.From, r.To)
+			continue
+		}
+		fmt.Fprintf(w, "redir \"%s\" \"%s\" %d\n", r.From, r.To, r.Status)
+	}
+}
+
+func (m *Map) writeJSON(w io.Writer) error {
+	redirects := m.Redirects()
+	if redirects == nil {
+		redirects = []Redirect{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(redirects)
+}
+
+func (m *Map) statuses() []int {
+	seen := make(map[int]bool)
+	var statuses []int
+	for _, r := range m.Redirects() {
+		if !seen[r.Status] {
+			seen[r.Status] = true
+			statuses = append(statuses, r.Status)
+		}
+	}
+	sort.Ints(statuses)
+	return statuses
+}
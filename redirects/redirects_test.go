@@ -0,0 +1,156 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redirects
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/maps"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	conf, err := DecodeConfig(nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(conf.Status, qt.Equals, 301)
+	c.Assert(conf.Formats, qt.HasLen, 0)
+
+	conf, err = DecodeConfig(maps.Params{"formats": []interface{}{"Netlify", "json"}, "status": 308})
+	c.Assert(err, qt.IsNil)
+	c.Assert(conf.Formats, qt.DeepEquals, []string{"netlify", "json"})
+	c.Assert(conf.Status, qt.Equals, 308)
+
+	_, err = DecodeConfig(maps.Params{"formats": []interface{}{"iis"}})
+	c.Assert(err, qt.ErrorMatches, `unsupported redirect map format "iis"`)
+
+	_, err = DecodeConfig(maps.Params{"status": 404})
+	c.Assert(err, qt.ErrorMatches, `unsupported redirect status 404`)
+}
+
+func TestDecodeRedirects(t *testing.T) {
+	c := qt.New(t)
+
+	redirects, err := DecodeRedirects([]interface{}{
+		map[string]interface{}{"from": "/old/", "to": "/new/"},
+		map[string]interface{}{"from": "/app/", "to": "/app/index.html", "status": 200},
+	}, 301)
+	c.Assert(err, qt.IsNil)
+	c.Assert(redirects, qt.DeepEquals, []Redirect{
+		{From: "/old/", To: "/new/", Status: 301},
+		{From: "/app/", To: "/app/index.html", Status: 200},
+	})
+
+	redirects, err = DecodeRedirects(nil, 301)
+	c.Assert(err, qt.IsNil)
+	c.Assert(redirects, qt.IsNil)
+
+	_, err = DecodeRedirects([]interface{}{map[string]interface{}{"from": "/old/"}}, 301)
+	c.Assert(err, qt.ErrorMatches, `redirect 1 must have both from and to set`)
+
+	_, err = DecodeRedirects([]interface{}{map[string]interface{}{"from": "old/", "to": "/new/"}}, 301)
+	c.Assert(err, qt.ErrorMatches, `redirect from "old/" must be an absolute path.*`)
+
+	_, err = DecodeRedirects([]interface{}{map[string]interface{}{"from": "/old/", "to": "/new/", "status": 500}}, 301)
+	c.Assert(err, qt.ErrorMatches, `unsupported status 500 in redirect from "/old/"`)
+}
+
+func TestMapMatch(t *testing.T) {
+	c := qt.New(t)
+
+	m := NewMap([]Redirect{
+		{From: "/old/", To: "/new/", Status: 301},
+		{From: "/old/", To: "/other/", Status: 302},
+		{From: "/p.html", To: "/posts/p/", Status: 308},
+		{From: "/self/", To: "/self/", Status: 301},
+	})
+
+	c.Assert(m.Redirects(), qt.HasLen, 2)
+	c.Assert(m.Match("/old/").To, qt.Equals, "/new/")
+	c.Assert(m.Match("/old").To, qt.Equals, "/new/")
+	c.Assert(m.Match("/old/index.html").To, qt.Equals, "/new/")
+	c.Assert(m.Match("/p.html").Status, qt.Equals, 308)
+	c.Assert(m.Match("/new/").IsZero(), qt.IsTrue)
+	c.Assert(m.Match("/old.css").IsZero(), qt.IsTrue)
+	c.Assert(m.Match("/self/").IsZero(), qt.IsTrue)
+
+	var nilMap *Map
+	c.Assert(nilMap.Match("/old/").IsZero(), qt.IsTrue)
+}
+
+func TestMapWrite(t *testing.T) {
+	c := qt.New(t)
+
+	m := NewMap([]Redirect{
+		{From: "/old/", To: "/new/", Status: 301},
+		{From: "/app/", To: "/app/index.html", Status: 200},
+		{From: "/blog/a+b/", To: "https://blog.example.org/", Status: 302},
+	})
+
+	write := func(format string) string {
+		var buf bytes.Buffer
+		c.Assert(m.Write(&buf, format), qt.IsNil)
+		return buf.String()
+	}
+
+	c.Assert(write(FormatNetlify), qt.Equals, `/app/ /app/index.html 200
+/blog/a+b/ https://blog.example.org/ 302
+/old/ /new/ 301
+`)
+
+	c.Assert(write(FormatNginx), qt.Equals, `# Include in the http block and use in a server block:
+#   if ($hugo_redirect_200) { rewrite ^ $hugo_redirect_200 last; }
+map $uri $hugo_redirect_200 {
+    "/app/" "/app/index.html";
+}
+
+# Include in the http block and use in a server block:
+#   if ($hugo_redirect_301) { return 301 $hugo_redirect_301; }
+map $uri $hugo_redirect_301 {
+    "/old/" "/new/";
+}
+
+# Include in the http block and use in a server block:
+#   if ($hugo_redirect_302) { return 302 $hugo_redirect_302; }
+map $uri $hugo_redirect_302 {
+    "/blog/a+b/" "https://blog.example.org/";
+}
+`)
+
+	c.Assert(write(FormatApache), qt.Equals, `RewriteEngine On
+RewriteCond %{REQUEST_URI} "^/app/$"
+RewriteRule ^ "/app/index.html" [L]
+RedirectMatch 302 "^/blog/a\+b/$" "https://blog.example.org/"
+RedirectMatch 301 "^/old/$" "/new/"
+`)
+
+	c.Assert(write(FormatCaddy), qt.Equals, `rewrite "/app/" "/app/index.html"
+redir "/blog/a+b/" "https://blog.example.org/" 302
+redir "/old/" "/new/" 301
+`)
+
+	c.Assert(write(FormatJSON), qt.Contains, `{
+    "from": "/old/",
+    "to": "/new/",
+    "status": 301
+  }`)
+
+	var buf bytes.Buffer
+	c.Assert(NewMap(nil).Write(&buf, FormatJSON), qt.IsNil)
+	c.Assert(buf.String(), qt.Equals, "[]\n")
+
+	c.Assert(m.Write(&buf, "iis"), qt.ErrorMatches, `unsupported redirect map format "iis"`)
+}
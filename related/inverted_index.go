@@ -21,15 +21,54 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/common/text"
 
 	"github.com/gohugoio/hugo/common/types"
 	"github.com/mitchellh/mapstructure"
 )
 
+// Index types.
+const (
+	// TypeBasic indexes keywords, e.g. the tags of a page.
+	TypeBasic = "basic"
+
+	// TypeText indexes the terms in a text, e.g. the content of a page,
+	// weighted with BM25 (a TF-IDF variant).
+	TypeText = "text"
+
+	// TypeFragments indexes the fragments of a document, e.g. the headings
+	// of a page. Besides its own fragments, a document queries this index
+	// with the keywords in its basic indices, so a page tagged "tls" will
+	// match a heading "TLS" in another page.
+	TypeFragments = "fragments"
+)
+
+// Sources of the text in indices of type text.
+const (
+	// SourceContent indexes the plain content of a page.
+	SourceContent = "content"
+
+	// SourceHeadings indexes the headings in the table of contents of a page.
+	SourceHeadings = "headings"
+)
+
+// BM25 parameters for text indices.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// maxTextQueryTerms is the maximum number of terms used to query a text index.
+// The most significant terms in the query are used, so long documents
+// are matched on what sets them apart, not on every word they contain.
+const maxTextQueryTerms = 25
+
 var (
 	_        Keyword = (*StringKeyword)(nil)
+	_        Keyword = Fragment{}
 	zeroDate         = time.Time{}
 
 	// DefaultConfig is the default related config.
@@ -60,6 +99,15 @@ An example site config.toml:
 	name  = "date"
 	weight = 1
 	pattern = "2006"
+	[[related.indices]]
+	name  = "content"
+	type = "text"
+	source = "content"
+	weight = 80
+	[[related.indices]]
+	name  = "fragments"
+	type = "fragments"
+	weight = 80
 */
 type Config struct {
 	// Only include matches >= threshold, a normalized rank between 0 and 100.
@@ -73,6 +121,11 @@ type Config struct {
 	ToLower bool
 
 	Indices IndexConfigs
+
+	// Tokenize splits the text of indices of type text into terms.
+	// Hugo sets this to a tokenizer for the site language. Defaults to
+	// splitting into lower case words.
+	Tokenize func(s string) []string `json:"-"`
 }
 
 // Add adds a given index.
@@ -91,6 +144,13 @@ type IndexConfig struct {
 	// The index name. This directly maps to a field or Param name.
 	Name string
 
+	// The index type, TypeBasic (default), TypeText or TypeFragments.
+	Type string
+
+	// The text to index in indices of type text, SourceContent or
+	// SourceHeadings. If not set, the Param with the index Name is used.
+	Source string
+
 	// Contextual pattern used to convert the Param value into a string.
 	// Currently only used for dates. Can be used to, say, bump posts in the same
 	// time frame when searching for related documents.
@@ -125,6 +185,12 @@ type InvertedIndex struct {
 	cfg   Config
 	index map[string]map[Keyword][]Document
 
+	// The indices of type text.
+	text map[string]*textIndex
+
+	// The indices of type fragments.
+	fragments map[string]fragmentIndex
+
 	minWeight int
 	maxWeight int
 }
@@ -142,9 +208,23 @@ func (idx *InvertedIndex) getIndexCfg(name string) (IndexConfig, bool) {
 // NewInvertedIndex creates a new InvertedIndex.
 // Documents to index must be added in Add.
 func NewInvertedIndex(cfg Config) *InvertedIndex {
-	idx := &InvertedIndex{index: make(map[string]map[Keyword][]Document), cfg: cfg}
+	idx := &InvertedIndex{
+		index:     make(map[string]map[Keyword][]Document),
+		text:      make(map[string]*textIndex),
+		fragments: make(map[string]fragmentIndex),
+		cfg:       cfg,
+	}
+	if idx.cfg.Tokenize == nil {
+		idx.cfg.Tokenize = defaultTokenizer.Tokens
+	}
 	for _, conf := range cfg.Indices {
 		idx.index[conf.Name] = make(map[Keyword][]Document)
+		switch conf.Type {
+		case TypeText:
+			idx.text[conf.Name] = newTextIndex()
+		case TypeFragments:
+			idx.fragments[conf.Name] = make(fragmentIndex)
+		}
 		if conf.Weight < idx.minWeight {
 			// By default, the weight scale starts at 0, but we allow
 			// negative weights.
@@ -167,6 +247,8 @@ func (idx *InvertedIndex) Add(docs ...Document) error {
 			continue
 		}
 		setm := idx.index[config.Name]
+		textm := idx.text[config.Name]
+		fragm := idx.fragments[config.Name]
 
 		for _, doc := range docs {
 			var words []Keyword
@@ -175,6 +257,16 @@ func (idx *InvertedIndex) Add(docs ...Document) error {
 				continue
 			}
 
+			if textm != nil {
+				textm.add(doc, idx.tokenize(words))
+				continue
+			}
+
+			if fragm != nil {
+				fragm.add(doc, words)
+				continue
+			}
+
 			for _, keyword := range words {
 				setm[keyword] = append(setm[keyword], doc)
 			}
@@ -184,6 +276,199 @@ func (idx *InvertedIndex) Add(docs ...Document) error {
 	return err
 }
 
+// tokenize splits the text in keywords into terms.
+func (idx *InvertedIndex) tokenize(keywords []Keyword) []string {
+	texts := make([]string, len(keywords))
+	for i, kw := range keywords {
+		texts[i] = kw.String()
+	}
+	return idx.cfg.Tokenize(strings.Join(texts, " "))
+}
+
+var defaultTokenizer = text.NewTokenizer("", []string{})
+
+// textIndex holds the term frequencies for an index of type text.
+type textIndex struct {
+	terms       map[string]map[Document]int
+	lengths     map[Document]int
+	totalLength int
+}
+
+func newTextIndex() *textIndex {
+	return &textIndex{
+		terms:   make(map[string]map[Document]int),
+		lengths: make(map[Document]int),
+	}
+}
+
+func (ti *textIndex) add(doc Document, terms []string) {
+	if len(terms) == 0 {
+		return
+	}
+	for _, term := range terms {
+		docs, found := ti.terms[term]
+		if !found {
+			docs = make(map[Document]int)
+			ti.terms[term] = docs
+		}
+		docs[doc]++
+	}
+	ti.lengths[doc] += len(terms)
+	ti.totalLength += len(terms)
+}
+
+// search scores the documents matching the most significant terms in the
+// query with BM25, and returns the documents with their weight, the index
+// weight scaled by their score relative to the best match.
+func (ti *textIndex) search(terms []string, weight int) map[Document]int {
+	if len(ti.lengths) == 0 || len(terms) == 0 {
+		return nil
+	}
+
+	n := float64(len(ti.lengths))
+	avgLength := float64(ti.totalLength) / n
+
+	tf := make(map[string]int)
+	for _, term := range terms {
+		tf[term]++
+	}
+
+	type queryTerm struct {
+		term       string
+		idf, tfidf float64
+	}
+
+	query := make([]queryTerm, 0, len(tf))
+	for term, f := range tf {
+		docs, found := ti.terms[term]
+		if !found {
+			continue
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		query = append(query, queryTerm{term: term, idf: idf, tfidf: float64(f) * idf})
+	}
+
+	sort.Slice(query, func(i, j int) bool {
+		if query[i].tfidf != query[j].tfidf {
+			return query[i].tfidf > query[j].tfidf
+		}
+		return query[i].term < query[j].term
+	})
+	if len(query) > maxTextQueryTerms {
+		query = query[:maxTextQueryTerms]
+	}
+
+	scores := make(map[Document]float64)
+	var maxScore float64
+	for _, q := range query {
+		for doc, f := range ti.terms[q.term] {
+			norm := 1 - bm25B + bm25B*float64(ti.lengths[doc])/avgLength
+			scores[doc] += q.idf * float64(f) * (bm25K1 + 1) / (float64(f) + bm25K1*norm)
+			if scores[doc] > maxScore {
+				maxScore = scores[doc]
+			}
+		}
+	}
+
+	result := make(map[Document]int, len(scores))
+	for doc, score := range scores {
+		if w := int(math.Round(float64(weight) * score / maxScore)); w != 0 {
+			result[doc] = w
+		}
+	}
+
+	return result
+}
+
+// Fragment is a part of a document that can be linked to, e.g. a heading in
+// a page. Documents return their fragments as keywords for indices of type
+// fragments.
+type Fragment struct {
+	// The fragment identifier, e.g. the heading ID "configuring-tls".
+	ID string
+
+	// The fragment title, e.g. the heading text "Configuring TLS".
+	Title string
+}
+
+func (f Fragment) String() string {
+	return f.ID
+}
+
+// fragmentIndex maps the normalized fragment IDs and titles to the
+// documents and their fragments.
+type fragmentIndex map[string]map[Document][]Fragment
+
+func (fi fragmentIndex) add(doc Document, keywords []Keyword) {
+	for _, kw := range keywords {
+		f, ok := kw.(Fragment)
+		if !ok {
+			f = Fragment{ID: kw.String()}
+		}
+		if f.ID == "" {
+			continue
+		}
+		for _, key := range fragmentKeys(f) {
+			docs, found := fi[key]
+			if !found {
+				docs = make(map[Document][]Fragment)
+				fi[key] = docs
+			}
+			docs[doc] = appendFragment(docs[doc], f)
+		}
+	}
+}
+
+// search returns the documents with fragments matching any of the keywords,
+// with the matching fragments in keyword order.
+func (fi fragmentIndex) search(keywords []Keyword) map[Document][]Fragment {
+	result := make(map[Document][]Fragment)
+	for _, kw := range keywords {
+		f, ok := kw.(Fragment)
+		if !ok {
+			f = Fragment{Title: kw.String()}
+		}
+		for _, key := range fragmentKeys(f) {
+			for doc, fragments := range fi[key] {
+				for _, ff := range fragments {
+					result[doc] = appendFragment(result[doc], ff)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// fragmentKeys returns the distinct, normalized ID and title of f.
+func fragmentKeys(f Fragment) []string {
+	var keys []string
+	for _, s := range []string{f.ID, f.Title} {
+		if key := normalizeFragment(s); key != "" && (len(keys) == 0 || keys[0] != key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// normalizeFragment lower cases s and joins its words with "-", so
+// "Configuring TLS" and "configuring-tls" are the same fragment.
+func normalizeFragment(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(words, "-")
+}
+
+func appendFragment(fragments []Fragment, f Fragment) []Fragment {
+	for _, ff := range fragments {
+		if ff.ID == f.ID {
+			return fragments
+		}
+	}
+	return append(fragments, f)
+}
+
 // queryElement holds the index name and keywords that can be used to compose a
 // search for related content.
 type queryElement struct {
@@ -198,9 +483,10 @@ func newQueryElement(index string, keywords ...Keyword) queryElement {
 type ranks []*rank
 
 type rank struct {
-	Doc     Document
-	Weight  int
-	Matches int
+	Doc       Document
+	Weight    int
+	Matches   int
+	Fragments []Fragment
 }
 
 func (r *rank) addWeight(w int) {
@@ -231,6 +517,45 @@ func (r ranks) Less(i, j int) bool {
 // threshold (normalize to 0..100) will be removed.
 // If an index name is provided, only that index will be queried.
 func (idx *InvertedIndex) SearchDoc(doc Document, indices ...string) ([]Document, error) {
+	q, err := idx.docQuery(doc, indices...)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx.searchDate(doc.PublishDate(), q...)
+}
+
+// Match is a document found in a search.
+type Match struct {
+	Doc Document
+
+	// The fragments in Doc matching the search in indices of type fragments,
+	// best match first.
+	Fragments []Fragment
+}
+
+// SearchDocMatches is like SearchDoc, but also returns the fragments
+// in the documents that matched.
+func (idx *InvertedIndex) SearchDocMatches(doc Document, indices ...string) ([]Match, error) {
+	q, err := idx.docQuery(doc, indices...)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := idx.searchRanks(doc.PublishDate(), q...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Match, len(matches))
+	for i, m := range matches {
+		result[i] = Match{Doc: m.Doc, Fragments: m.Fragments}
+	}
+
+	return result, nil
+}
+
+func (idx *InvertedIndex) docQuery(doc Document, indices ...string) ([]queryElement, error) {
 	var q []queryElement
 
 	var configs IndexConfigs
@@ -254,11 +579,24 @@ func (idx *InvertedIndex) SearchDoc(doc Document, indices ...string) ([]Document
 			return nil, err
 		}
 
+		if cfg.Type == TypeFragments {
+			for _, other := range idx.cfg.Indices {
+				if other.Weight == 0 || (other.Type != "" && other.Type != TypeBasic) {
+					continue
+				}
+				otherKeywords, err := doc.RelatedKeywords(other)
+				if err != nil {
+					return nil, err
+				}
+				keywords = append(keywords, otherKeywords...)
+			}
+		}
+
 		q = append(q, newQueryElement(cfg.Name, keywords...))
 
 	}
 
-	return idx.searchDate(doc.PublishDate(), q...)
+	return q, nil
 }
 
 // ToKeywords returns a Keyword slice of the given input.
@@ -336,18 +674,67 @@ func (idx *InvertedIndex) search(query ...queryElement) ([]Document, error) {
 }
 
 func (idx *InvertedIndex) searchDate(upperDate time.Time, query ...queryElement) ([]Document, error) {
+	matches, err := idx.searchRanks(upperDate, query...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Document, len(matches))
+
+	for i, m := range matches {
+		result[i] = m.Doc
+	}
+
+	return result, nil
+}
+
+func (idx *InvertedIndex) searchRanks(upperDate time.Time, query ...queryElement) (ranks, error) {
 	matchm := make(map[Document]*rank, 200)
 	applyDateFilter := !idx.cfg.IncludeNewer && !upperDate.IsZero()
 
 	for _, el := range query {
 		setm, found := idx.index[el.Index]
 		if !found {
-			return []Document{}, fmt.Errorf("index for %q not found", el.Index)
+			return nil, fmt.Errorf("index for %q not found", el.Index)
 		}
 
 		config, found := idx.getIndexCfg(el.Index)
 		if !found {
-			return []Document{}, fmt.Errorf("index config for %q not found", el.Index)
+			return nil, fmt.Errorf("index config for %q not found", el.Index)
+		}
+
+		if textm := idx.text[el.Index]; textm != nil {
+			for doc, weight := range textm.search(idx.tokenize(el.Keywords), config.Weight) {
+				if applyDateFilter && doc.PublishDate().After(upperDate) {
+					continue
+				}
+				r, found := matchm[doc]
+				if !found {
+					matchm[doc] = newRank(doc, weight)
+				} else {
+					r.addWeight(weight)
+				}
+			}
+			continue
+		}
+
+		if fragm := idx.fragments[el.Index]; fragm != nil {
+			for doc, fragments := range fragm.search(el.Keywords) {
+				if applyDateFilter && doc.PublishDate().After(upperDate) {
+					continue
+				}
+				for _, f := range fragments {
+					r, found := matchm[doc]
+					if !found {
+						r = newRank(doc, config.Weight)
+						matchm[doc] = r
+					} else {
+						r.addWeight(config.Weight)
+					}
+					r.Fragments = append(r.Fragments, f)
+				}
+			}
+			continue
 		}
 
 		for _, kw := range el.Keywords {
@@ -371,7 +758,7 @@ func (idx *InvertedIndex) searchDate(upperDate time.Time, query ...queryElement)
 	}
 
 	if len(matchm) == 0 {
-		return []Document{}, nil
+		return nil, nil
 	}
 
 	matches := make(ranks, 0, 100)
@@ -388,13 +775,7 @@ func (idx *InvertedIndex) searchDate(upperDate time.Time, query ...queryElement)
 
 	sort.Stable(matches)
 
-	result := make([]Document, len(matches))
-
-	for i, m := range matches {
-		result[i] = m.Doc
-	}
-
-	return result, nil
+	return matches, nil
 }
 
 // normalizes num to a number between 0 and 100.
@@ -425,6 +806,25 @@ func DecodeConfig(m maps.Params) (Config, error) {
 		return Config{}, errors.New("related threshold must be between 0 and 100")
 	}
 
+	for i, index := range c.Indices {
+		c.Indices[i].Type = strings.ToLower(index.Type)
+		switch c.Indices[i].Type {
+		case "", TypeBasic, TypeText, TypeFragments:
+		default:
+			return Config{}, fmt.Errorf("invalid type %q for related index %q", index.Type, index.Name)
+		}
+		c.Indices[i].Source = strings.ToLower(index.Source)
+		switch c.Indices[i].Source {
+		case "":
+		case SourceContent, SourceHeadings:
+			if c.Indices[i].Type != TypeText {
+				return Config{}, fmt.Errorf("source %q for related index %q requires type %q", index.Source, index.Name, TypeText)
+			}
+		default:
+			return Config{}, fmt.Errorf("invalid source %q for related index %q", index.Source, index.Name)
+		}
+	}
+
 	if c.ToLower {
 		for i := range c.Indices {
 			c.Indices[i].ToLower = true
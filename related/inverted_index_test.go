@@ -20,6 +20,8 @@ import (
 	"time"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/common/types"
 )
 
 type testDoc struct {
@@ -212,6 +214,195 @@ func TestToKeywordsToLower(t *testing.T) {
 	})
 }
 
+func TestTextIndex(t *testing.T) {
+	c := qt.New(t)
+
+	config := Config{
+		Threshold:    0,
+		IncludeNewer: true,
+		Indices: IndexConfigs{
+			IndexConfig{Name: "content", Type: TypeText, Weight: 100},
+			IndexConfig{Name: "tags", Weight: 50},
+		},
+	}
+
+	idx := NewInvertedIndex(config)
+
+	d1 := newTestDoc("content", "Hugo builds sites from Markdown files. Markdown is converted to HTML.")
+	d2 := newTestDoc("content", "Convert your Markdown files to HTML with Hugo templates.")
+	d3 := newTestDoc("content", "Deploy your sites to a CDN.").addKeywords("tags", "hugo")
+	d4 := newTestDoc("content", "Cooking pasta takes ten minutes.")
+	d5 := newTestDoc("tags", "hugo")
+
+	c.Assert(idx.Add(d1, d2, d3, d4, d5), qt.IsNil)
+	c.Assert(idx.text["content"].lengths, qt.HasLen, 4)
+
+	m, err := idx.SearchDoc(d1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 3)
+	c.Assert(m[0], qt.Equals, d1)
+	c.Assert(m[1], qt.Equals, d2)
+	c.Assert(m[2], qt.Equals, d3)
+
+	m, err = idx.SearchDoc(d1, "content")
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 3)
+
+	m, err = idx.SearchKeyValues(types.NewKeyValuesStrings("content", "pasta"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 1)
+	c.Assert(m[0], qt.Equals, d4)
+
+	// The tags index matches d3 and d5 with 50/100.
+	idx = NewInvertedIndex(Config{Threshold: 60, IncludeNewer: true, Indices: config.Indices})
+	c.Assert(idx.Add(d1, d2, d3, d4, d5), qt.IsNil)
+	m, err = idx.SearchDoc(d3, "tags")
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 0)
+	m, err = idx.SearchDoc(d2, "content")
+	c.Assert(err, qt.IsNil)
+	c.Assert(m[0], qt.Equals, d2)
+}
+
+func TestTextIndexTokenize(t *testing.T) {
+	c := qt.New(t)
+
+	config := Config{
+		IncludeNewer: true,
+		Indices: IndexConfigs{
+			IndexConfig{Name: "content", Type: TypeText, Weight: 100},
+		},
+		Tokenize: func(s string) []string {
+			return []string{"same"}
+		},
+	}
+
+	idx := NewInvertedIndex(config)
+	d1 := newTestDoc("content", "a")
+	d2 := newTestDoc("content", "b")
+	c.Assert(idx.Add(d1, d2), qt.IsNil)
+
+	m, err := idx.SearchDoc(d1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 2)
+}
+
+func TestDecodeConfigIndexType(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := DecodeConfig(maps.Params{
+		"indices": []interface{}{
+			maps.Params{"name": "content", "type": "Text", "weight": 10},
+			maps.Params{"name": "tags", "weight": 10},
+			maps.Params{"name": "fragments", "type": "fragments", "weight": 10},
+		},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Indices[0].Type, qt.Equals, TypeText)
+	c.Assert(cfg.Indices[1].Type, qt.Equals, "")
+	c.Assert(cfg.Indices[2].Type, qt.Equals, TypeFragments)
+
+	_, err = DecodeConfig(maps.Params{
+		"indices": []interface{}{
+			maps.Params{"name": "content", "type": "fuzzy", "weight": 10},
+		},
+	})
+	c.Assert(err, qt.ErrorMatches, `invalid type "fuzzy" for related index "content"`)
+}
+
+func TestDecodeConfigIndexSource(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := DecodeConfig(maps.Params{
+		"indices": []interface{}{
+			maps.Params{"name": "body", "type": "text", "source": "Content", "weight": 10},
+			maps.Params{"name": "toc", "type": "text", "source": "headings", "weight": 10},
+			maps.Params{"name": "description", "type": "text", "weight": 10},
+		},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Indices[0].Source, qt.Equals, SourceContent)
+	c.Assert(cfg.Indices[1].Source, qt.Equals, SourceHeadings)
+	c.Assert(cfg.Indices[2].Source, qt.Equals, "")
+
+	_, err = DecodeConfig(maps.Params{
+		"indices": []interface{}{
+			maps.Params{"name": "content", "type": "text", "source": "summary", "weight": 10},
+		},
+	})
+	c.Assert(err, qt.ErrorMatches, `invalid source "summary" for related index "content"`)
+
+	_, err = DecodeConfig(maps.Params{
+		"indices": []interface{}{
+			maps.Params{"name": "content", "source": "content", "weight": 10},
+		},
+	})
+	c.Assert(err, qt.ErrorMatches, `source "content" for related index "content" requires type "text"`)
+}
+
+func TestFragmentIndex(t *testing.T) {
+	c := qt.New(t)
+
+	config := Config{
+		Threshold:    20,
+		IncludeNewer: true,
+		Indices: IndexConfigs{
+			IndexConfig{Name: "tags", Weight: 100},
+			IndexConfig{Name: "fragments", Type: TypeFragments, Weight: 80},
+		},
+	}
+
+	idx := NewInvertedIndex(config)
+
+	newDoc := func(name string, tags []string, fragments ...Fragment) *testDoc {
+		d := newTestDoc("tags", tags...)
+		d.name = name
+		d.keywords["fragments"] = make([]Keyword, len(fragments))
+		for i, f := range fragments {
+			d.keywords["fragments"][i] = f
+		}
+		return d
+	}
+
+	tls := Fragment{ID: "configuring-tls", Title: "Configuring TLS"}
+	hosting := newDoc("hosting", []string{"Configuring TLS"})
+	server := newDoc("server", nil, Fragment{ID: "flags", Title: "Flags"}, tls)
+	deploy := newDoc("deploy", []string{"cdn"}, Fragment{ID: "cdn", Title: "CDN"}, Fragment{ID: "cdn-1", Title: "CDN"})
+	other := newDoc("other", []string{"recipes"}, Fragment{ID: "flags", Title: "Flags"})
+
+	c.Assert(idx.Add(hosting, server, deploy, other), qt.IsNil)
+
+	// The document itself matches on its tags.
+	matches, err := idx.SearchDocMatches(hosting)
+	c.Assert(err, qt.IsNil)
+	c.Assert(matches, qt.HasLen, 2)
+	c.Assert(matches[0].Doc, qt.Equals, hosting)
+	c.Assert(matches[0].Fragments, qt.HasLen, 0)
+	c.Assert(matches[1].Doc, qt.Equals, server)
+	c.Assert(matches[1].Fragments, qt.DeepEquals, []Fragment{tls})
+
+	// Documents with the same fragments.
+	matches, err = idx.SearchDocMatches(server, "fragments")
+	c.Assert(err, qt.IsNil)
+	c.Assert(matches, qt.HasLen, 2)
+	c.Assert(matches[0].Doc, qt.Equals, server)
+	c.Assert(matches[0].Fragments, qt.DeepEquals, []Fragment{{ID: "flags", Title: "Flags"}, tls})
+	c.Assert(matches[1].Doc, qt.Equals, other)
+
+	// A tag matching the title of more than one fragment.
+	matches, err = idx.SearchDocMatches(deploy)
+	c.Assert(err, qt.IsNil)
+	c.Assert(matches[0].Doc, qt.Equals, deploy)
+	c.Assert(matches[0].Fragments, qt.DeepEquals, []Fragment{{ID: "cdn", Title: "CDN"}, {ID: "cdn-1", Title: "CDN"}})
+
+	docs, err := idx.SearchKeyValues(types.NewKeyValuesStrings("fragments", "configuring tls"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(docs, qt.HasLen, 1)
+	c.Assert(docs[0], qt.Equals, server)
+
+	c.Assert(normalizeFragment("Configuring  TLS (1.3)!"), qt.Equals, "configuring-tls-1-3")
+}
+
 func BenchmarkRelatedNewIndex(b *testing.B) {
 	pages := make([]*testDoc, 100)
 	numkeywords := 30
@@ -120,10 +120,26 @@ func (e *errorResource) Filter(filters ...interface{}) (resource.Image, error) {
 	panic(e.error)
 }
 
+func (e *errorResource) ImageSet(options map[string]interface{}) (*resource.ImageSet, error) {
+	panic(e.error)
+}
+
 func (e *errorResource) Exif() *exif.Exif {
 	panic(e.error)
 }
 
+func (e *errorResource) BlurHash() (string, error) {
+	panic(e.error)
+}
+
+func (e *errorResource) Placeholder() (string, error) {
+	panic(e.error)
+}
+
+func (e *errorResource) Colors() ([]string, error) {
+	panic(e.error)
+}
+
 func (e *errorResource) DecodeImage() (image.Image, error) {
 	panic(e.error)
 }
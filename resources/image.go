@@ -44,6 +44,8 @@ import (
 	"github.com/gohugoio/hugo/helpers"
 	"github.com/gohugoio/hugo/resources/images"
 
+	"golang.org/x/sync/errgroup"
+
 	// Blind import for image.Decode
 	_ "golang.org/x/image/webp"
 )
@@ -62,10 +64,18 @@ type imageResource struct {
 	// original (first).
 	root *imageResource
 
+	// Whether this image is the result of image processing, which means that
+	// the Exif orientation of the original is already applied.
+	processed bool
+
 	metaInit    sync.Once
 	metaInitErr error
 	meta        *imageMeta
 
+	placeholdersInit    sync.Once
+	placeholdersInitErr error
+	placeholders        *images.Placeholders
+
 	baseResource
 }
 
@@ -137,10 +147,90 @@ func (i *imageResource) getExif() *exif.Exif {
 	return i.meta.Exif
 }
 
+// BlurHash returns a BlurHash for the image, see https://blurha.sh
+func (i *imageResource) BlurHash() (string, error) {
+	p, err := i.getPlaceholders()
+	if err != nil {
+		return "", err
+	}
+	return p.BlurHash, nil
+}
+
+// Placeholder returns a tiny version of the image as a base64 encoded
+// data URL, to be scaled up and blurred while the image loads.
+func (i *imageResource) Placeholder() (string, error) {
+	p, err := i.getPlaceholders()
+	if err != nil {
+		return "", err
+	}
+	return p.Placeholder, nil
+}
+
+// Colors returns the dominant colors in the image as hex strings, the most
+// dominant first.
+func (i *imageResource) Colors() ([]string, error) {
+	p, err := i.getPlaceholders()
+	if err != nil {
+		return nil, err
+	}
+	return p.Colors, nil
+}
+
+func (i *imageResource) getPlaceholders() (*images.Placeholders, error) {
+	i.placeholdersInit.Do(func() {
+		key := i.getImagePlaceholdersCacheTargetPath()
+
+		read := func(info filecache.ItemInfo, r io.ReadSeeker) error {
+			placeholders := &images.Placeholders{}
+			data, err := ioutil.ReadAll(r)
+			if err != nil {
+				return err
+			}
+
+			if err = json.Unmarshal(data, placeholders); err != nil {
+				return err
+			}
+
+			i.placeholders = placeholders
+
+			return nil
+		}
+
+		create := func(info filecache.ItemInfo, w io.WriteCloser) error {
+			defer w.Close()
+
+			src, err := i.DecodeImage()
+			if err != nil {
+				return _errors.Wrap(err, "failed to decode image")
+			}
+
+			placeholders, err := i.Proc.CreatePlaceholders(src, i.orientation())
+			if err != nil {
+				return err
+			}
+
+			i.placeholders = placeholders
+
+			// Also write it to cache
+			enc := json.NewEncoder(w)
+			return enc.Encode(i.placeholders)
+		}
+
+		_, i.placeholdersInitErr = i.getSpec().imageCache.fileCache.ReadOrCreate(key, read, create)
+	})
+
+	if i.placeholdersInitErr != nil {
+		return nil, _errors.Wrapf(i.placeholdersInitErr, "failed to create placeholders for image %q", i.Key())
+	}
+
+	return i.placeholders, nil
+}
+
 func (i *imageResource) Clone() resource.Resource {
 	gr := i.baseResource.Clone().(baseResource)
 	return &imageResource{
 		root:         i.root,
+		processed:    i.processed,
 		Image:        i.WithSpec(gr),
 		baseResource: gr,
 	}
@@ -162,6 +252,7 @@ func (i *imageResource) cloneWithUpdates(u *transformationUpdate) (baseResource,
 
 	return &imageResource{
 		root:         i.root,
+		processed:    i.processed,
 		Image:        img,
 		baseResource: base,
 	}, nil
@@ -255,6 +346,63 @@ func (i *imageResource) Filter(filters ...interface{}) (resource.Image, error) {
 	})
 }
 
+// ImageSet resizes the image to the widths or pixel densities in options, in
+// each of the target formats, and returns the images with ready to use srcset
+// and sizes values. See images.ImageSetConfig for the available options.
+func (i *imageResource) ImageSet(options map[string]interface{}) (*resource.ImageSet, error) {
+	conf, err := images.DecodeImageSetConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	formats := conf.Formats
+	if len(formats) == 0 {
+		// Keep the source format.
+		formats = []string{""}
+	}
+	variants := conf.Variants(i.Width())
+
+	// The images are fetched from the image cache in parallel. Note that the
+	// processing of new images is still limited by imageProcWorkers.
+	resized := make([]resource.ImageVariant, len(formats)*len(variants))
+	var g errgroup.Group
+	for fi, format := range formats {
+		for vi, v := range variants {
+			idx := fi*len(variants) + vi
+			spec := conf.Spec(v.Width, format)
+			descriptor := v.Descriptor
+			g.Go(func() error {
+				img, err := i.Resize(spec)
+				if err != nil {
+					return err
+				}
+				resized[idx] = resource.ImageVariant{Image: img, Descriptor: descriptor}
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	set := &resource.ImageSet{
+		Variants: resized,
+		Sizes:    conf.Sizes,
+	}
+
+	for fi := range formats {
+		formatVariants := resized[fi*len(variants) : (fi+1)*len(variants)]
+		source := resource.NewImageSource(formatVariants[0].MediaType().Type(), formatVariants)
+		if fi == len(formats)-1 {
+			set.Fallback = source
+		} else {
+			set.Sources = append(set.Sources, source)
+		}
+	}
+
+	return set, nil
+}
+
 // Serialize image processing. The imaging library spins up its own set of Go routines,
 // so there is not much to gain from adding more load to the mix. That
 // can even have negative effect in low resource scenarios.
@@ -279,14 +427,23 @@ func (i *imageResource) doWithImageConfig(conf images.ImageConfig, f func(src im
 			return nil, nil, &os.PathError{Op: errOp, Path: errPath, Err: err}
 		}
 
+		if anim, ok := src.(images.AnimatedGIF); ok && !conf.TargetFormat.SupportsAnimation() {
+			// E.g. a PNG thumbnail, which can only hold the first frame.
+			i.getSpec().Logger.Infof("Only the first frame of the animated GIF %q is kept in %s, use gif or webp to keep the animation", i.Key(), conf.TargetFormat.MediaType().SubType)
+			src = anim.GIF().Image[0]
+		}
+
 		converted, err := f(src)
 		if err != nil {
 			return nil, nil, &os.PathError{Op: errOp, Path: errPath, Err: err}
 		}
 
+		_, animated := converted.(images.AnimatedGIF)
+
 		hasAlpha := !images.IsOpaque(converted)
 		shouldFill := conf.BgColor != nil && hasAlpha
 		shouldFill = shouldFill || (!conf.TargetFormat.SupportsTransparency() && hasAlpha)
+		shouldFill = shouldFill && !animated
 		var bgColor color.Color
 
 		if shouldFill {
@@ -315,6 +472,14 @@ func (i *imageResource) doWithImageConfig(conf images.ImageConfig, f func(src im
 			}
 		}
 
+		if conf.Metadata != images.MetadataStrip && i.Format == images.JPEG && conf.TargetFormat == images.JPEG {
+			exif, err := i.readJPEGExif()
+			if err != nil {
+				return nil, nil, &os.PathError{Op: errOp, Path: errPath, Err: err}
+			}
+			converted = images.WithExif(converted, images.PrepareExif(exif, conf.Metadata, conf.Orientation > 1))
+		}
+
 		ci := i.clone(converted)
 		ci.setBasePath(conf)
 		ci.Format = conf.TargetFormat
@@ -336,9 +501,65 @@ func (i *imageResource) decodeImageConfig(action, spec string) (images.ImageConf
 		return conf, err
 	}
 
+	conf.Orientation = i.orientation()
+
 	return conf, nil
 }
 
+// Width returns the width of the image as it's displayed, which is the height
+// of the stored image if auto orientation transposes it.
+func (i *imageResource) Width() int {
+	if i.isTransposed() {
+		return i.Image.Height()
+	}
+	return i.Image.Width()
+}
+
+// Height returns the height of the image as it's displayed, which is the
+// width of the stored image if auto orientation transposes it.
+func (i *imageResource) Height() int {
+	if i.isTransposed() {
+		return i.Image.Width()
+	}
+	return i.Image.Height()
+}
+
+// isTransposed reports whether the Exif orientation to apply swaps the width
+// and the height of the image, which is the case for orientations 5 to 8.
+func (i *imageResource) isTransposed() bool {
+	o := i.orientation()
+	return o >= 5 && o <= 8
+}
+
+// orientation returns the Exif orientation to apply to the image, or 0 if
+// auto orientation is disabled or not needed.
+func (i *imageResource) orientation() int {
+	if i.processed || !i.Proc.Cfg.Cfg.AutoOrient {
+		return 0
+	}
+	x := i.Exif()
+	if x == nil {
+		return 0
+	}
+	return x.Orientation
+}
+
+// readJPEGExif reads the raw Exif data from the image source.
+func (i *imageResource) readJPEGExif() ([]byte, error) {
+	f, err := i.ReadSeekCloser()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	exif, err := images.ReadJPEGExif(f)
+	if err != nil {
+		// Keep no metadata rather than failing on odd JPEG files.
+		return nil, nil
+	}
+	return exif, nil
+}
+
 // DecodeImage decodes the image source into an Image.
 // This an internal method and may change.
 func (i *imageResource) DecodeImage() (image.Image, error) {
@@ -347,6 +568,9 @@ func (i *imageResource) DecodeImage() (image.Image, error) {
 		return nil, _errors.Wrap(err, "failed to open image for decode")
 	}
 	defer f.Close()
+	if i.Format == images.GIF {
+		return images.DecodeGIF(f)
+	}
 	img, _, err := image.Decode(f)
 	return img, err
 }
@@ -364,6 +588,7 @@ func (i *imageResource) clone(img image.Image) *imageResource {
 	return &imageResource{
 		Image:        image,
 		root:         i.root,
+		processed:    true,
 		baseResource: spec,
 	}
 }
@@ -373,7 +598,7 @@ func (i *imageResource) setBasePath(conf images.ImageConfig) {
 }
 
 func (i *imageResource) getImageMetaCacheTargetPath() string {
-	const imageMetaVersionNumber = 1 // Increment to invalidate the meta cache
+	const imageMetaVersionNumber = 2 // Increment to invalidate the meta cache
 
 	cfgHash := i.getSpec().imaging.Cfg.CfgHash
 	df := i.getResourcePaths().relTargetDirFile
@@ -387,6 +612,15 @@ func (i *imageResource) getImageMetaCacheTargetPath() string {
 	return p
 }
 
+// getImagePlaceholdersCacheTargetPath returns the cache path for the
+// placeholders, next to the image meta.
+func (i *imageResource) getImagePlaceholdersCacheTargetPath() string {
+	const imagePlaceholdersVersionNumber = 1 // Increment to invalidate the placeholders cache
+
+	p := strings.TrimSuffix(i.getImageMetaCacheTargetPath(), ".json")
+	return fmt.Sprintf("%s_placeholders%d.json", p, imagePlaceholdersVersionNumber)
+}
+
 func (i *imageResource) relTargetPathFromConfig(conf images.ImageConfig) dirFile {
 	p1, p2 := paths.FileAndExt(i.getResourcePaths().relTargetDirFile.file)
 	if conf.TargetFormat != i.Format {
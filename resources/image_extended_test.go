@@ -16,8 +16,12 @@
 package resources
 
 import (
+	"bytes"
+	"io/ioutil"
 	"testing"
 
+	"github.com/gohugoio/hugo/resources/resource"
+
 	"github.com/gohugoio/hugo/media"
 
 	qt "github.com/frankban/quicktest"
@@ -39,3 +43,24 @@ func TestImageResizeWebP(t *testing.T) {
 	c.Assert(resized.RelPermalink(), qt.Equals, "/a/sunset_hu36ee0b61ba924719ad36da960c273f96_59826_123x0_resize_q68_h2_linear_2.webp")
 	c.Assert(resized.Width(), qt.Equals, 123)
 }
+
+func TestImageAnimatedGIFToWebP(t *testing.T) {
+	c := qt.New(t)
+
+	giphy := fetchImage(c, "giphy.gif")
+
+	resized, err := giphy.Resize("30x webp")
+	c.Assert(err, qt.IsNil)
+	c.Assert(resized.MediaType(), qt.Equals, media.WEBPType)
+	c.Assert(resized.Width(), qt.Equals, 30)
+	c.Assert(resized.Height(), qt.Equals, 20)
+
+	f, err := resized.(resource.ReadSeekCloserResource).ReadSeekCloser()
+	c.Assert(err, qt.IsNil)
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b[8:12]), qt.Equals, "WEBP")
+	c.Assert(bytes.Count(b, []byte("ANIM")), qt.Equals, 1)
+	c.Assert(bytes.Count(b, []byte("ANMF")), qt.Equals, 3)
+}
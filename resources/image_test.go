@@ -16,6 +16,7 @@ package resources
 import (
 	"fmt"
 	"image"
+	"image/gif"
 	"io/ioutil"
 	"math/big"
 	"math/rand"
@@ -40,6 +41,7 @@ import (
 
 	"github.com/gohugoio/hugo/media"
 	"github.com/gohugoio/hugo/resources/images"
+	"github.com/gohugoio/hugo/resources/images/exif"
 	"github.com/gohugoio/hugo/resources/resource"
 	"github.com/google/go-cmp/cmp"
 
@@ -195,6 +197,56 @@ func TestImageTransformFormat(t *testing.T) {
 	assertFileCache(c, fileCache, path.Base(imageGif.RelPermalink()), 225, 141)
 }
 
+func TestImageSet(t *testing.T) {
+	c := qt.New(t)
+
+	image := fetchSunset(c)
+
+	set, err := image.ImageSet(map[string]interface{}{
+		"widths":  []interface{}{1200, 300, 600},
+		"formats": []interface{}{"png", "jpg"},
+		"quality": 50,
+		"sizes":   "(min-width: 600px) 50vw, 100vw",
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(set.Variants, qt.HasLen, 4)
+	c.Assert(set.Sizes, qt.Equals, "(min-width: 600px) 50vw, 100vw")
+
+	c.Assert(set.Sources, qt.HasLen, 1)
+	png := set.Sources[0]
+	c.Assert(png.Type, qt.Equals, "image/png")
+	c.Assert(png.Variants, qt.HasLen, 2)
+	c.Assert(png.Variants[0].Width(), qt.Equals, 300)
+	c.Assert(png.Variants[0].Descriptor, qt.Equals, "300w")
+	c.Assert(png.Variants[1].Width(), qt.Equals, 600)
+	c.Assert(png.Srcset, qt.Equals, png.Variants[0].RelPermalink()+" 300w, "+png.Variants[1].RelPermalink()+" 600w")
+
+	jpg := set.Fallback
+	c.Assert(jpg.Type, qt.Equals, "image/jpeg")
+	c.Assert(paths.Ext(jpg.Variants[0].RelPermalink()), qt.Equals, ".jpg")
+	c.Assert(jpg.Variants[0].RelPermalink(), qt.Contains, "_300x0_resize_q50_")
+	c.Assert(set.Srcset(), qt.Equals, jpg.Srcset)
+	c.Assert(set.Image().Width(), qt.Equals, 600)
+
+	resized, err := image.Resize("600x jpg q50")
+	c.Assert(err, qt.IsNil)
+	c.Assert(set.Image(), eq, resized)
+
+	set, err = image.ImageSet(map[string]interface{}{
+		"densities": []interface{}{1, 2},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(set.Sources, qt.HasLen, 0)
+	c.Assert(set.Fallback.Type, qt.Equals, "image/jpeg")
+	c.Assert(set.Fallback.Variants, qt.HasLen, 2)
+	c.Assert(set.Fallback.Variants[0].Width(), qt.Equals, 450)
+	c.Assert(set.Fallback.Variants[1].Width(), qt.Equals, 900)
+	c.Assert(set.Srcset(), qt.Contains, " 1x, ")
+
+	_, err = image.ImageSet(map[string]interface{}{})
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
 // https://github.com/gohugoio/hugo/issues/5730
 func TestImagePermalinkPublishOrder(t *testing.T) {
 	for _, checkOriginalFirst := range []bool{true, false} {
@@ -367,6 +419,156 @@ func TestImageWithMetadata(t *testing.T) {
 	c.Assert(resized.Name(), qt.Equals, "Sunset #1")
 }
 
+func TestImageAnimatedGIF(t *testing.T) {
+	c := qt.New(t)
+
+	giphy := fetchImage(c, "giphy.gif")
+	c.Assert(giphy.MediaType().Type(), qt.Equals, "image/gif")
+	c.Assert(giphy.Width(), qt.Equals, 60)
+
+	assertFrames := func(img resource.Image, frames int) *gif.GIF {
+		c.Helper()
+		decoded, err := img.DecodeImage()
+		c.Assert(err, qt.IsNil)
+		anim, ok := decoded.(images.AnimatedGIF)
+		c.Assert(ok, qt.IsTrue)
+		g := anim.GIF()
+		c.Assert(g.Image, qt.HasLen, frames)
+		return g
+	}
+
+	resized, err := giphy.Resize("30x")
+	c.Assert(err, qt.IsNil)
+	c.Assert(resized.Width(), qt.Equals, 30)
+	c.Assert(resized.Height(), qt.Equals, 20)
+	g := assertFrames(resized, 3)
+	c.Assert(g.Delay, qt.DeepEquals, []int{10, 20, 30})
+	c.Assert(g.LoopCount, qt.Equals, 0)
+	for _, frame := range g.Image {
+		c.Assert(frame.Bounds(), qt.Equals, image.Rect(0, 0, 30, 20))
+	}
+
+	filled, err := resized.Fill("10x10 center")
+	c.Assert(err, qt.IsNil)
+	assertFrames(filled, 3)
+
+	f := &images.Filters{}
+	filtered, err := giphy.Filter(f.Grayscale())
+	c.Assert(err, qt.IsNil)
+	assertFrames(filtered, 3)
+
+	png, err := giphy.Resize("30x png")
+	c.Assert(err, qt.IsNil)
+	c.Assert(png.MediaType().Type(), qt.Equals, "image/png")
+	c.Assert(png.Width(), qt.Equals, 30)
+}
+
+func TestImageAutoOrient(t *testing.T) {
+	c := qt.New(t)
+
+	// Stored as 40x20 with a red top half and Exif orientation 6.
+	img := fetchImage(c, "orientation6.jpg")
+	c.Assert(img.Exif().Orientation, qt.Equals, 6)
+	// The dimensions are reported as displayed.
+	c.Assert(img.Width(), qt.Equals, 20)
+	c.Assert(img.Height(), qt.Equals, 40)
+
+	decoder, err := exif.NewDecoder()
+	c.Assert(err, qt.IsNil)
+
+	readExif := func(img resource.Image) *exif.Exif {
+		c.Helper()
+		f, err := img.(resource.ReadSeekCloserResource).ReadSeekCloser()
+		c.Assert(err, qt.IsNil)
+		defer f.Close()
+		x, err := decoder.Decode(f)
+		c.Assert(err, qt.IsNil)
+		return x
+	}
+
+	resized, err := img.Resize("10x")
+	c.Assert(err, qt.IsNil)
+	c.Assert(resized.Width(), qt.Equals, 10)
+	c.Assert(resized.Height(), qt.Equals, 20)
+	c.Assert(resized.RelPermalink(), qt.Contains, "_o6")
+	// Stripped by default.
+	c.Assert(readExif(resized), qt.IsNil)
+
+	decoded, err := resized.DecodeImage()
+	c.Assert(err, qt.IsNil)
+	// Rotated 90 degrees clockwise, so the red top half is now to the right.
+	r, _, b, _ := decoded.At(1, 10).RGBA()
+	c.Assert(b > r, qt.IsTrue)
+	r, _, b, _ = decoded.At(8, 10).RGBA()
+	c.Assert(r > b, qt.IsTrue)
+
+	// Processing a processed image must not rotate it again.
+	resized2, err := resized.Resize("5x")
+	c.Assert(err, qt.IsNil)
+	c.Assert(resized2.Width(), qt.Equals, 5)
+	c.Assert(resized2.Height(), qt.Equals, 10)
+
+	filled, err := img.Fill("10x10 keep")
+	c.Assert(err, qt.IsNil)
+	c.Assert(filled.RelPermalink(), qt.Contains, "_mkeep")
+	x := readExif(filled)
+	c.Assert(x, qt.Not(qt.IsNil))
+	// The image is already rotated.
+	c.Assert(x.Orientation, qt.Equals, 1)
+	c.Assert(x.Tags["GPSLatitudeRef"], qt.Equals, "N")
+
+	filled, err = img.Fill("10x10 keepnogps")
+	c.Assert(err, qt.IsNil)
+	x = readExif(filled)
+	c.Assert(x, qt.Not(qt.IsNil))
+	c.Assert(x.Orientation, qt.Equals, 1)
+	c.Assert(x.Tags["GPSLatitudeRef"], qt.IsNil)
+
+	// The widths larger than the displayed width of 20 are skipped.
+	set, err := img.ImageSet(map[string]interface{}{
+		"widths": []interface{}{10, 30, 40},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(set.Fallback.Variants, qt.HasLen, 1)
+	c.Assert(set.Fallback.Variants[0].Width(), qt.Equals, 10)
+	c.Assert(set.Fallback.Variants[0].Height(), qt.Equals, 20)
+}
+
+func TestImagePlaceholders(t *testing.T) {
+	c := qt.New(t)
+
+	assertPlaceholders := func(img resource.Image, sizeFlag string) {
+		c.Helper()
+		blurHash, err := img.BlurHash()
+		c.Assert(err, qt.IsNil)
+		c.Assert(blurHash, qt.HasLen, 28)
+		c.Assert(blurHash[:1], qt.Equals, sizeFlag)
+
+		placeholder, err := img.Placeholder()
+		c.Assert(err, qt.IsNil)
+		c.Assert(placeholder, qt.Matches, `data:image/png;base64,[A-Za-z0-9+/=]+`)
+
+		colors, err := img.Colors()
+		c.Assert(err, qt.IsNil)
+		c.Assert(len(colors) >= 1 && len(colors) <= 6, qt.IsTrue)
+		for _, color := range colors {
+			c.Assert(color, qt.Matches, `#[0-9a-f]{6}`)
+		}
+	}
+
+	sunset := fetchSunset(c)
+	assertPlaceholders(sunset, "L")
+
+	resized, err := sunset.Resize("x200")
+	c.Assert(err, qt.IsNil)
+	assertPlaceholders(resized, "L")
+
+	// Stored in landscape, but displayed in portrait.
+	assertPlaceholders(fetchImage(c, "orientation6.jpg"), "T")
+
+	assertPlaceholders(fetchImage(c, "giphy.gif"), "L")
+}
+
 func TestImageResize8BitPNG(t *testing.T) {
 	c := qt.New(t)
 
@@ -15,7 +15,10 @@ package images
 
 import (
 	"encoding/hex"
+	"fmt"
+	"image"
 	"image/color"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -46,6 +49,74 @@ func ReplaceColorInPalette(c color.Color, p color.Palette) {
 	p[p.Index(c)] = c
 }
 
+// ColorToHexString returns c as a hex string, e.g. "#ff0000". Any
+// transparency is ignored.
+func ColorToHexString(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
+
+// DominantColors returns up to n of the most common colors in img, the
+// most common first. Similar colors are grouped together, and the color
+// returned for a group is the average of its pixels. Transparent pixels
+// are ignored.
+func DominantColors(img image.Image, n int) []color.Color {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+
+	// Group the colors by the 4 most significant bits of each channel.
+	buckets := make(map[int]*bucket)
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			bu, found := buckets[key]
+			if !found {
+				bu = &bucket{}
+				buckets[key] = bu
+			}
+			bu.count++
+			bu.r += int(c.R)
+			bu.g += int(c.G)
+			bu.b += int(c.B)
+		}
+	}
+
+	keys := make([]int, 0, len(buckets))
+	for k := range buckets {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		bi, bj := buckets[keys[i]], buckets[keys[j]]
+		if bi.count != bj.count {
+			return bi.count > bj.count
+		}
+		return keys[i] < keys[j]
+	})
+
+	var colors []color.Color
+	for _, k := range keys {
+		if len(colors) == n {
+			break
+		}
+		bu := buckets[k]
+		colors = append(colors, color.NRGBA{
+			R: uint8(bu.r / bu.count),
+			G: uint8(bu.g / bu.count),
+			B: uint8(bu.b / bu.count),
+			A: 255,
+		})
+	}
+
+	return colors
+}
+
 func hexStringToColor(s string) (color.Color, error) {
 	s = strings.TrimPrefix(s, "#")
 
@@ -14,6 +14,7 @@
 package images
 
 import (
+	"image"
 	"image/color"
 	"testing"
 
@@ -87,3 +88,42 @@ func TestReplaceColorInPalette(t *testing.T) {
 	c.Assert(palette, qt.HasLen, 2)
 	c.Assert(palette[0], qt.Equals, offWhite)
 }
+
+func TestColorToHexString(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(ColorToHexString(color.White), qt.Equals, "#ffffff")
+	c.Assert(ColorToHexString(color.RGBA{R: 0x42, G: 0x87, B: 0xf5, A: 0xff}), qt.Equals, "#4287f5")
+	c.Assert(ColorToHexString(color.NRGBA{R: 0x42, G: 0x87, B: 0xf5, A: 0x80}), qt.Equals, "#4287f5")
+}
+
+func TestDominantColors(t *testing.T) {
+	c := qt.New(t)
+
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			switch {
+			case x < 6:
+				img.Set(x, y, red)
+			case x < 9:
+				img.Set(x, y, blue)
+			default:
+				// Ignored.
+				img.Set(x, y, color.Transparent)
+			}
+		}
+	}
+	// A slightly different red is grouped with the others.
+	img.Set(0, 0, color.NRGBA{R: 245, A: 255})
+
+	c.Assert(DominantColors(img, 3), qt.DeepEquals, []color.Color{
+		color.NRGBA{R: 254, A: 255},
+		blue,
+	})
+	c.Assert(DominantColors(img, 1), qt.DeepEquals, []color.Color{color.NRGBA{R: 254, A: 255}})
+	c.Assert(DominantColors(image.NewNRGBA(image.Rect(0, 0, 2, 2)), 3), qt.HasLen, 0)
+}
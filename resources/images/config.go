@@ -60,6 +60,7 @@ var (
 	imageFormatsVersions = map[Format]int{
 		PNG:  3, // Fix transparency issue with 32 bit images.
 		WEBP: 2, // Fix transparency issue with 32 bit images.
+		GIF:  1, // Preserve the frames in animated GIFs.
 	}
 
 	// Increment to mark all processed images as stale. Only use when absolutely needed.
@@ -122,6 +123,7 @@ const (
 	defaultResampleFilter = "box"
 	defaultBgColor        = "ffffff"
 	defaultHint           = "photo"
+	defaultMetadata       = MetadataStrip
 )
 
 var defaultImaging = Imaging{
@@ -129,6 +131,8 @@ var defaultImaging = Imaging{
 	BgColor:        defaultBgColor,
 	Hint:           defaultHint,
 	Quality:        defaultJPEGQuality,
+	AutoOrient:     true,
+	Metadata:       defaultMetadata,
 }
 
 func DecodeConfig(m map[string]interface{}) (ImagingConfig, error) {
@@ -205,6 +209,8 @@ func DecodeImageConfig(action, config string, defaults ImagingConfig, sourceForm
 			c.FilterStr = part
 		} else if hint, ok := hints[part]; ok {
 			c.Hint = hint
+		} else if metadataModes[part] {
+			c.Metadata = part
 		} else if part[0] == '#' {
 			c.BgColorStr = part[1:]
 			c.BgColor, err = hexStringToColor(c.BgColorStr)
@@ -311,6 +317,14 @@ type ImageConfig struct {
 	// The rotation will be performed first.
 	Rotate int
 
+	// The Exif orientation of the source image to correct before any other
+	// processing. Set to 0 to not correct the orientation.
+	Orientation int
+
+	// What to do with the Exif metadata when a JPEG image is processed to
+	// JPEG, one of strip, keep or keepnogps.
+	Metadata string
+
 	// Used to fill any transparency.
 	// When set in site config, it's used when converting to a format that does
 	// not support transparency.
@@ -349,6 +363,12 @@ func (i ImageConfig) GetKey(format Format) string {
 	if i.Rotate != 0 {
 		k += "_r" + strconv.Itoa(i.Rotate)
 	}
+	if i.Orientation > 1 {
+		k += "_o" + strconv.Itoa(i.Orientation)
+	}
+	if i.TargetFormat == JPEG && i.Metadata != "" && i.Metadata != MetadataStrip {
+		k += "_m" + i.Metadata
+	}
 	if i.BgColorStr != "" {
 		k += "_bg" + i.BgColorStr
 	}
@@ -413,6 +433,15 @@ type Imaging struct {
 	// Default color used in fill operations (e.g. "fff" for white).
 	BgColor string
 
+	// Whether to rotate and flip images according to their Exif orientation
+	// when resized, cropped etc. Default is true.
+	AutoOrient bool
+
+	// What to do with the Exif metadata when a JPEG image is processed to
+	// JPEG. Valid values are "strip", "keep" and "keepNoGPS".
+	// Default is "strip", i.e. no metadata is kept.
+	Metadata string
+
 	Exif ExifConfig
 }
 
@@ -425,6 +454,11 @@ func (cfg *Imaging) init() error {
 	cfg.Anchor = strings.ToLower(cfg.Anchor)
 	cfg.ResampleFilter = strings.ToLower(cfg.ResampleFilter)
 	cfg.Hint = strings.ToLower(cfg.Hint)
+	cfg.Metadata = strings.ToLower(cfg.Metadata)
+
+	if !metadataModes[cfg.Metadata] {
+		return errors.Errorf("invalid image metadata value %q", cfg.Metadata)
+	}
 
 	return nil
 }
@@ -60,6 +60,11 @@ func TestDecodeConfig(t *testing.T) {
 	})
 	c.Assert(err, qt.Not(qt.IsNil))
 
+	_, err = DecodeConfig(map[string]interface{}{
+		"metadata": "asdf",
+	})
+	c.Assert(err, qt.ErrorMatches, `invalid image metadata value "asdf"`)
+
 	imagingConfig, err = DecodeConfig(map[string]interface{}{
 		"anchor": "Smart",
 	})
@@ -114,6 +119,41 @@ func TestDecodeImageConfig(t *testing.T) {
 	}
 }
 
+func TestDecodeImageConfigMetadata(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := DecodeConfig(map[string]interface{}{})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Cfg.AutoOrient, qt.IsTrue)
+	c.Assert(cfg.Cfg.Metadata, qt.Equals, MetadataStrip)
+
+	conf, err := DecodeImageConfig("resize", "300x", cfg, JPEG)
+	c.Assert(err, qt.IsNil)
+	c.Assert(conf.Metadata, qt.Equals, MetadataStrip)
+	c.Assert(conf.GetKey(JPEG), qt.Equals, "300x0_resize_q75_box")
+
+	conf.Orientation = 6
+	c.Assert(conf.GetKey(JPEG), qt.Equals, "300x0_resize_q75_o6_box")
+
+	conf, err = DecodeImageConfig("resize", "300x keepNoGPS", cfg, JPEG)
+	c.Assert(err, qt.IsNil)
+	c.Assert(conf.Metadata, qt.Equals, MetadataKeepNoGPS)
+	c.Assert(conf.GetKey(JPEG), qt.Equals, "300x0_resize_q75_mkeepnogps_box")
+
+	// Metadata is only kept for JPEG.
+	conf, err = DecodeImageConfig("resize", "300x png keep", cfg, JPEG)
+	c.Assert(err, qt.IsNil)
+	c.Assert(conf.GetKey(JPEG), qt.Equals, "300x0_resize_box")
+
+	cfg, err = DecodeConfig(map[string]interface{}{"metadata": "Keep", "autoOrient": false})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Cfg.AutoOrient, qt.IsFalse)
+	conf, err = DecodeImageConfig("resize", "300x", cfg, JPEG)
+	c.Assert(err, qt.IsNil)
+	c.Assert(conf.Metadata, qt.Equals, MetadataKeep)
+	c.Assert(conf.GetKey(JPEG), qt.Equals, "300x0_resize_q75_mkeep_box")
+}
+
 func newImageConfig(width, height, quality, rotate int, filter, anchor, bgColor string) ImageConfig {
 	var c ImageConfig = GetDefaultImageConfig("resize", ImagingConfig{})
 	c.TargetFormat = PNG
@@ -122,6 +162,7 @@ func newImageConfig(width, height, quality, rotate int, filter, anchor, bgColor
 	c.Height = height
 	c.Quality = quality
 	c.qualitySetForImage = quality != 75
+	c.Metadata = defaultMetadata
 	c.Rotate = rotate
 	c.BgColorStr = bgColor
 	c.BgColor, _ = hexStringToColor(bgColor)
@@ -37,6 +37,10 @@ type Exif struct {
 	Long float64
 	Date time.Time
 	Tags Tags
+
+	// The orientation of the image, 1 to 8, or 0 if not set.
+	// See https://magnushoff.com/articles/jpeg-orientation/
+	Orientation int
 }
 
 type Decoder struct {
@@ -134,12 +138,18 @@ func (d *Decoder) Decode(r io.Reader) (ex *Exif, err error) {
 		lat, long, _ = x.LatLong()
 	}
 
+	// Always decode the orientation, as it's needed to auto orient the image.
+	var orientation int
+	if tag, err := x.Get(_exif.Orientation); err == nil {
+		orientation, _ = tag.Int(0)
+	}
+
 	walker := &exifWalker{x: x, vals: make(map[string]interface{}), includeMatcher: d.includeFieldsRe, excludeMatcher: d.excludeFieldsrRe}
 	if err = x.Walk(walker); err != nil {
 		return
 	}
 
-	ex = &Exif{Lat: lat, Long: long, Date: tm, Tags: walker.vals}
+	ex = &Exif{Lat: lat, Long: long, Date: tm, Tags: walker.vals, Orientation: orientation}
 
 	return
 }
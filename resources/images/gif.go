@@ -0,0 +1,155 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+
+	"github.com/bep/gowebp/libwebp/webpoptions"
+	"github.com/disintegration/gift"
+	"github.com/gohugoio/hugo/resources/images/webp"
+)
+
+// AnimatedGIF is a GIF image with more than one frame. As an image.Image it
+// is the first frame.
+type AnimatedGIF interface {
+	image.Image
+
+	// GIF returns all the frames, with their delays and the loop count.
+	GIF() *gif.GIF
+}
+
+type animatedGIF struct {
+	image.Image
+	g *gif.GIF
+}
+
+func (a *animatedGIF) GIF() *gif.GIF {
+	return a.g
+}
+
+func newAnimatedGIF(g *gif.GIF) *animatedGIF {
+	return &animatedGIF{Image: g.Image[0], g: g}
+}
+
+// DecodeGIF decodes the GIF in r. Animated GIFs are returned as an AnimatedGIF.
+func DecodeGIF(r io.Reader) (image.Image, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(g.Image) == 1 {
+		return g.Image[0], nil
+	}
+
+	return newAnimatedGIF(g), nil
+}
+
+// filterAnimatedGIF applies the filters in g to every frame in src. A frame
+// may only cover parts of the canvas, so each frame is first drawn onto the
+// full canvas, respecting the frame disposal methods.
+func filterAnimatedGIF(src *gif.GIF, g *gift.GIFT) *animatedGIF {
+	canvasBounds := image.Rect(0, 0, src.Config.Width, src.Config.Height)
+	if canvasBounds.Empty() {
+		canvasBounds = src.Image[0].Bounds()
+	}
+	bounds := g.Bounds(canvasBounds)
+
+	dst := &gif.GIF{
+		Image:           make([]*image.Paletted, len(src.Image)),
+		Delay:           make([]int, len(src.Image)),
+		Disposal:        make([]byte, len(src.Image)),
+		LoopCount:       src.LoopCount,
+		BackgroundIndex: src.BackgroundIndex,
+		Config: image.Config{
+			ColorModel: src.Config.ColorModel,
+			Width:      bounds.Dx(),
+			Height:     bounds.Dy(),
+		},
+	}
+	copy(dst.Delay, src.Delay)
+
+	canvas := image.NewNRGBA(canvasBounds)
+
+	for i, frame := range src.Image {
+		var disposal byte
+		if i < len(src.Disposal) {
+			disposal = src.Disposal[i]
+		}
+
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewNRGBA(canvasBounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		filtered := image.NewNRGBA(bounds)
+		g.Draw(filtered, canvas)
+
+		palette := frame.Palette
+		if !filtered.Opaque() && len(palette) < 256 {
+			if _, _, _, a := palette[palette.Index(color.Transparent)].RGBA(); a != 0 {
+				palette = AddColorToPalette(color.Transparent, palette)
+			}
+		}
+
+		paletted := image.NewPaletted(filtered.Bounds(), palette)
+		draw.Draw(paletted, paletted.Bounds(), filtered, filtered.Bounds().Min, draw.Src)
+
+		dst.Image[i] = paletted
+		// Every frame now covers the full canvas, so clear it before the next
+		// frame is drawn to keep transparent areas transparent.
+		dst.Disposal[i] = gif.DisposalBackground
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return newAnimatedGIF(dst)
+}
+
+// encodeAnimatedWebP writes the frames in g to w as an animated WebP image.
+func encodeAnimatedWebP(w io.Writer, g *gif.GIF, o webpoptions.EncodingOptions) error {
+	// Make every frame cover the full canvas.
+	g = filterAnimatedGIF(g, gift.New()).GIF()
+
+	frames := make([]webp.Frame, len(g.Image))
+	for i, img := range g.Image {
+		// GIF delays are in 100ths of a second.
+		frames[i] = webp.Frame{Image: img, Duration: g.Delay[i] * 10}
+	}
+
+	// The GIF loop count is the number of times to repeat the animation,
+	// with -1 for none, while the WebP loop count is the number of times to
+	// play it.
+	loopCount := g.LoopCount
+	if loopCount < 0 {
+		loopCount = 1
+	} else if loopCount > 0 && loopCount < 0xffff {
+		loopCount++
+	}
+
+	return webp.EncodeAnimated(w, frames, loopCount, o)
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/disintegration/gift"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFilterAnimatedGIF(t *testing.T) {
+	c := qt.New(t)
+
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	palette := color.Palette{color.Transparent, red, green, blue}
+
+	src := &gif.GIF{Config: image.Config{ColorModel: palette, Width: 60, Height: 40}}
+	for i, bounds := range []image.Rectangle{
+		image.Rect(0, 0, 60, 40),
+		image.Rect(0, 0, 60, 40),
+		// A partial frame.
+		image.Rect(10, 10, 30, 30),
+	} {
+		frame := image.NewPaletted(bounds, palette)
+		for j := range frame.Pix {
+			frame.Pix[j] = uint8(i + 1)
+		}
+		src.Image = append(src.Image, frame)
+		src.Delay = append(src.Delay, 10*(i+1))
+		src.Disposal = append(src.Disposal, gif.DisposalNone)
+	}
+
+	var buf bytes.Buffer
+	c.Assert(gif.EncodeAll(&buf, src), qt.IsNil)
+
+	decoded, err := DecodeGIF(&buf)
+	c.Assert(err, qt.IsNil)
+	anim, ok := decoded.(AnimatedGIF)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(anim.Bounds(), qt.Equals, image.Rect(0, 0, 60, 40))
+
+	filtered := filterAnimatedGIF(anim.GIF(), gift.New(gift.Resize(30, 0, gift.LinearResampling)))
+	g := filtered.GIF()
+
+	c.Assert(filtered.Bounds(), qt.Equals, image.Rect(0, 0, 30, 20))
+	c.Assert(g.Config.Width, qt.Equals, 30)
+	c.Assert(g.Config.Height, qt.Equals, 20)
+	c.Assert(g.Image, qt.HasLen, 3)
+	c.Assert(g.Delay, qt.DeepEquals, []int{10, 20, 30})
+	c.Assert(g.LoopCount, qt.Equals, anim.GIF().LoopCount)
+
+	for _, frame := range g.Image {
+		c.Assert(frame.Bounds(), qt.Equals, image.Rect(0, 0, 30, 20))
+	}
+
+	c.Assert(g.Image[0].At(0, 0), qt.Equals, red)
+	c.Assert(g.Image[1].At(0, 0), qt.Equals, green)
+	// The partial frame is drawn on top of the previous frame.
+	c.Assert(g.Image[2].At(0, 0), qt.Equals, green)
+	c.Assert(g.Image[2].At(10, 10), qt.Equals, blue)
+
+	buf.Reset()
+	c.Assert(gif.EncodeAll(&buf, g), qt.IsNil)
+
+	single := &gif.GIF{Image: src.Image[:1], Delay: src.Delay[:1]}
+	buf.Reset()
+	c.Assert(gif.EncodeAll(&buf, single), qt.IsNil)
+	decoded, err = DecodeGIF(&buf)
+	c.Assert(err, qt.IsNil)
+	_, ok = decoded.(*image.Paletted)
+	c.Assert(ok, qt.IsTrue)
+}
@@ -14,6 +14,7 @@
 package images
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
@@ -61,8 +62,20 @@ type Image struct {
 }
 
 func (i *Image) EncodeTo(conf ImageConfig, img image.Image, w io.Writer) error {
+	var exif []byte
+	if ei, ok := img.(*exifImage); ok {
+		img, exif = ei.Image, ei.exif
+	}
+
 	switch conf.TargetFormat {
 	case JPEG:
+		if exif != nil {
+			var buf bytes.Buffer
+			if err := i.EncodeTo(conf, img, &buf); err != nil {
+				return err
+			}
+			return writeJPEGWithExif(w, buf.Bytes(), exif)
+		}
 
 		var rgba *image.RGBA
 		quality := conf.Quality
@@ -85,6 +98,9 @@ func (i *Image) EncodeTo(conf ImageConfig, img image.Image, w io.Writer) error {
 		return encoder.Encode(w, img)
 
 	case GIF:
+		if anim, ok := img.(AnimatedGIF); ok {
+			return gif.EncodeAll(w, anim.GIF())
+		}
 		return gif.Encode(w, img, &gif.Options{
 			NumColors: 256,
 		})
@@ -94,14 +110,15 @@ func (i *Image) EncodeTo(conf ImageConfig, img image.Image, w io.Writer) error {
 	case BMP:
 		return bmp.Encode(w, img)
 	case WEBP:
-		return webp.Encode(
-			w,
-			img, webpoptions.EncodingOptions{
-				Quality:        conf.Quality,
-				EncodingPreset: webpoptions.EncodingPreset(conf.Hint),
-				UseSharpYuv:    true,
-			},
-		)
+		o := webpoptions.EncodingOptions{
+			Quality:        conf.Quality,
+			EncodingPreset: webpoptions.EncodingPreset(conf.Hint),
+			UseSharpYuv:    true,
+		}
+		if anim, ok := img.(AnimatedGIF); ok {
+			return encodeAnimatedWebP(w, anim.GIF(), o)
+		}
+		return webp.Encode(w, img, o)
 	default:
 		return errors.New("format not supported")
 	}
@@ -197,6 +214,16 @@ func (p *ImageProcessor) DecodeExif(r io.Reader) (*exif.Exif, error) {
 }
 
 func (p *ImageProcessor) ApplyFiltersFromConfig(src image.Image, conf ImageConfig) (image.Image, error) {
+	if f, found := orientationFilters[conf.Orientation]; found {
+		// Orient the image first, so the dimensions and any smart crop
+		// applies to the image as it's meant to be viewed.
+		var err error
+		src, err = p.Filter(src, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var filters []gift.Filter
 
 	if conf.Rotate != 0 {
@@ -252,6 +279,11 @@ func (p *ImageProcessor) ApplyFiltersFromConfig(src image.Image, conf ImageConfi
 
 func (p *ImageProcessor) Filter(src image.Image, filters ...gift.Filter) (image.Image, error) {
 	g := gift.New(filters...)
+
+	if anim, ok := src.(AnimatedGIF); ok {
+		return filterAnimatedGIF(anim.GIF(), g), nil
+	}
+
 	bounds := g.Bounds(src.Bounds())
 	var dst draw.Image
 	switch src.(type) {
@@ -270,9 +302,10 @@ func (p *ImageProcessor) Filter(src image.Image, filters ...gift.Filter) (image.
 
 func GetDefaultImageConfig(action string, defaults ImagingConfig) ImageConfig {
 	return ImageConfig{
-		Action:  action,
-		Hint:    defaults.Hint,
-		Quality: defaults.Cfg.Quality,
+		Action:   action,
+		Hint:     defaults.Hint,
+		Quality:  defaults.Cfg.Quality,
+		Metadata: defaults.Cfg.Metadata,
 	}
 }
 
@@ -304,6 +337,11 @@ func (f Format) SupportsTransparency() bool {
 	return f != JPEG
 }
 
+// SupportsAnimation reports whether it can hold the frames of an animated GIF.
+func (f Format) SupportsAnimation() bool {
+	return f == GIF || f == WEBP
+}
+
 // DefaultExtension returns the default file extension of this format, starting with a dot.
 // For example: .jpg for JPEG
 func (f Format) DefaultExtension() string {
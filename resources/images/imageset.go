@@ -0,0 +1,159 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// ImageSetConfig configures a responsive image set, e.g.:
+//
+//	{{ $set := $image.ImageSet (dict "widths" (slice 480 800 1200) "formats" (slice "webp" "jpg")) }}
+type ImageSetConfig struct {
+	// The image widths in pixels, e.g. [480, 800, 1200].
+	Widths []int
+
+	// The pixel densities, e.g. [1, 2]. Only used if Widths is not set.
+	Densities []float64
+
+	// The width in pixels of the 1x density image. Default is the source
+	// width divided by the largest density.
+	Width int
+
+	// The target formats, e.g. ["webp", "jpg"]. The last format is the
+	// fallback for browsers without support for the others.
+	// Default is the source format.
+	Formats []string
+
+	// Quality (1-100). Default is the quality in the imaging config.
+	Quality int
+
+	// The value of the sizes attribute, e.g. "(min-width: 800px) 50vw, 100vw".
+	Sizes string
+}
+
+// ImageSetVariant describes one image size in an image set.
+type ImageSetVariant struct {
+	Width int
+
+	// The srcset descriptor, e.g. "480w" or "2x".
+	Descriptor string
+}
+
+// DecodeImageSetConfig decodes and validates the image set options in m.
+func DecodeImageSetConfig(m map[string]interface{}) (ImageSetConfig, error) {
+	var c ImageSetConfig
+
+	if err := mapstructure.WeakDecode(m, &c); err != nil {
+		return c, errors.Wrap(err, "failed to decode image set options")
+	}
+
+	if len(c.Widths) == 0 && len(c.Densities) == 0 {
+		return c, errors.New("image set must have widths or densities")
+	}
+
+	for _, w := range c.Widths {
+		if w <= 0 {
+			return c, errors.Errorf("invalid image set width %d", w)
+		}
+	}
+
+	for _, d := range c.Densities {
+		if d <= 0 {
+			return c, errors.Errorf("invalid image set density %v", d)
+		}
+	}
+
+	if c.Width < 0 {
+		return c, errors.Errorf("invalid image set width %d", c.Width)
+	}
+
+	for i, f := range c.Formats {
+		f = strings.ToLower(strings.TrimPrefix(f, "."))
+		if _, found := ImageFormatFromExt("." + f); !found {
+			return c, errors.Errorf("unsupported image set format %q", f)
+		}
+		c.Formats[i] = f
+	}
+
+	if c.Quality < 0 || c.Quality > 100 {
+		return c, errors.New("quality ranges from 1 to 100 inclusive")
+	}
+
+	return c, nil
+}
+
+// Variants returns the image sizes to create from a source image that is
+// srcWidth pixels wide, sorted by width. Images are never scaled up, so
+// sizes wider than the source are skipped.
+func (c ImageSetConfig) Variants(srcWidth int) []ImageSetVariant {
+	var variants []ImageSetVariant
+
+	if len(c.Widths) > 0 {
+		widths := make([]int, len(c.Widths))
+		copy(widths, c.Widths)
+		sort.Ints(widths)
+		for i, w := range widths {
+			if w > srcWidth || (i > 0 && w == widths[i-1]) {
+				continue
+			}
+			variants = append(variants, ImageSetVariant{Width: w, Descriptor: strconv.Itoa(w) + "w"})
+		}
+		if len(variants) == 0 {
+			variants = append(variants, ImageSetVariant{Width: srcWidth, Descriptor: strconv.Itoa(srcWidth) + "w"})
+		}
+		return variants
+	}
+
+	densities := make([]float64, len(c.Densities))
+	copy(densities, c.Densities)
+	sort.Float64s(densities)
+
+	base := float64(c.Width)
+	if base == 0 {
+		base = float64(srcWidth) / densities[len(densities)-1]
+	}
+
+	for i, d := range densities {
+		w := int(math.Round(base * d))
+		if w < 1 || w > srcWidth || (i > 0 && d == densities[i-1]) {
+			continue
+		}
+		variants = append(variants, ImageSetVariant{Width: w, Descriptor: strconv.FormatFloat(d, 'f', -1, 64) + "x"})
+	}
+	if len(variants) == 0 {
+		variants = append(variants, ImageSetVariant{Width: srcWidth, Descriptor: "1x"})
+	}
+
+	return variants
+}
+
+// Spec returns the Resize spec for an image of the given width in format,
+// e.g. "800x webp q75". An empty format means the source format.
+func (c ImageSetConfig) Spec(width int, format string) string {
+	spec := strconv.Itoa(width) + "x"
+	if format != "" {
+		spec += " " + format
+	}
+	if c.Quality > 0 {
+		spec += " q" + strconv.Itoa(c.Quality)
+	}
+	return spec
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDecodeImageSetConfig(t *testing.T) {
+	c := qt.New(t)
+
+	conf, err := DecodeImageSetConfig(map[string]interface{}{
+		"widths":  []interface{}{800, "480"},
+		"formats": []interface{}{"WebP", ".jpg"},
+		"quality": 80,
+		"sizes":   "100vw",
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(conf.Widths, qt.DeepEquals, []int{800, 480})
+	c.Assert(conf.Formats, qt.DeepEquals, []string{"webp", "jpg"})
+	c.Assert(conf.Quality, qt.Equals, 80)
+	c.Assert(conf.Sizes, qt.Equals, "100vw")
+
+	conf, err = DecodeImageSetConfig(map[string]interface{}{"densities": []interface{}{1, 1.5, 2}})
+	c.Assert(err, qt.IsNil)
+	c.Assert(conf.Densities, qt.DeepEquals, []float64{1, 1.5, 2})
+
+	_, err = DecodeImageSetConfig(nil)
+	c.Assert(err, qt.ErrorMatches, "image set must have widths or densities")
+
+	_, err = DecodeImageSetConfig(map[string]interface{}{"widths": []interface{}{0}})
+	c.Assert(err, qt.ErrorMatches, "invalid image set width 0")
+
+	_, err = DecodeImageSetConfig(map[string]interface{}{"densities": []interface{}{-1}})
+	c.Assert(err, qt.ErrorMatches, "invalid image set density -1")
+
+	_, err = DecodeImageSetConfig(map[string]interface{}{"widths": []interface{}{100}, "formats": []interface{}{"svg"}})
+	c.Assert(err, qt.ErrorMatches, `unsupported image set format "svg"`)
+
+	_, err = DecodeImageSetConfig(map[string]interface{}{"widths": []interface{}{100}, "quality": 101})
+	c.Assert(err, qt.ErrorMatches, "quality ranges from 1 to 100 inclusive")
+}
+
+func TestImageSetConfigVariants(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(ImageSetConfig{Widths: []int{1200, 480, 800, 480}}.Variants(900), qt.DeepEquals, []ImageSetVariant{
+		{Width: 480, Descriptor: "480w"},
+		{Width: 800, Descriptor: "800w"},
+	})
+
+	c.Assert(ImageSetConfig{Widths: []int{1200}}.Variants(900), qt.DeepEquals, []ImageSetVariant{
+		{Width: 900, Descriptor: "900w"},
+	})
+
+	c.Assert(ImageSetConfig{Densities: []float64{2, 1, 1.5}}.Variants(900), qt.DeepEquals, []ImageSetVariant{
+		{Width: 450, Descriptor: "1x"},
+		{Width: 675, Descriptor: "1.5x"},
+		{Width: 900, Descriptor: "2x"},
+	})
+
+	c.Assert(ImageSetConfig{Densities: []float64{1, 2, 3}, Width: 400}.Variants(900), qt.DeepEquals, []ImageSetVariant{
+		{Width: 400, Descriptor: "1x"},
+		{Width: 800, Descriptor: "2x"},
+	})
+
+	c.Assert(ImageSetConfig{Densities: []float64{1}, Width: 1000}.Variants(900), qt.DeepEquals, []ImageSetVariant{
+		{Width: 900, Descriptor: "1x"},
+	})
+}
+
+func TestImageSetConfigSpec(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(ImageSetConfig{}.Spec(800, ""), qt.Equals, "800x")
+	c.Assert(ImageSetConfig{Quality: 75}.Spec(800, "webp"), qt.Equals, "800x webp q75")
+}
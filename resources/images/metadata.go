@@ -0,0 +1,322 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+
+	"github.com/disintegration/gift"
+)
+
+// Metadata modes for processed JPEG images.
+const (
+	// MetadataStrip removes all metadata. This is the default.
+	MetadataStrip = "strip"
+
+	// MetadataKeep keeps the Exif metadata, including the GPS location.
+	MetadataKeep = "keep"
+
+	// MetadataKeepNoGPS keeps the Exif metadata, except the GPS location.
+	MetadataKeepNoGPS = "keepnogps"
+)
+
+var metadataModes = map[string]bool{
+	MetadataStrip:     true,
+	MetadataKeep:      true,
+	MetadataKeepNoGPS: true,
+}
+
+// orientationFilters rotates and flips an image according to its Exif
+// orientation, see https://magnushoff.com/articles/jpeg-orientation/
+var orientationFilters = map[int]gift.Filter{
+	2: gift.FlipHorizontal(),
+	3: gift.Rotate180(),
+	4: gift.FlipVertical(),
+	5: gift.Transpose(),
+	6: gift.Rotate270(),
+	7: gift.Transverse(),
+	8: gift.Rotate90(),
+}
+
+// exifImage is an image with the raw Exif data to write when encoded.
+type exifImage struct {
+	image.Image
+	exif []byte
+}
+
+// WithExif returns img with the raw Exif data to write to the APP1 segment
+// when img is encoded to JPEG.
+func WithExif(img image.Image, exif []byte) image.Image {
+	if len(exif) == 0 {
+		return img
+	}
+	return &exifImage{Image: img, exif: exif}
+}
+
+const (
+	jpegMarkerSOI  = 0xd8
+	jpegMarkerEOI  = 0xd9
+	jpegMarkerSOS  = 0xda
+	jpegMarkerAPP1 = 0xe1
+
+	// The max size of the data in a JPEG segment.
+	jpegMaxSegmentSize = 0xffff - 2
+
+	tiffTagOrientation = 0x0112
+	tiffTagGPSInfo     = 0x8825
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// ReadJPEGExif reads the raw Exif data, starting with the "Exif\0\0"
+// header, from the APP1 segment of the JPEG image in r. It returns nil
+// if there is none.
+func ReadJPEGExif(r io.Reader) ([]byte, error) {
+	br := bufio.NewReader(r)
+
+	var soi [2]byte
+	if _, err := io.ReadFull(br, soi[:]); err != nil {
+		return nil, err
+	}
+	if soi[0] != 0xff || soi[1] != jpegMarkerSOI {
+		return nil, nil
+	}
+
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != 0xff {
+			return nil, nil
+		}
+
+		marker, err := br.ReadByte()
+		for err == nil && marker == 0xff {
+			// Fill bytes.
+			marker, err = br.ReadByte()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case marker == jpegMarkerSOS || marker == jpegMarkerEOI:
+			// The Exif data must come before the image data.
+			return nil, nil
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			// These markers have no data.
+			continue
+		}
+
+		var length [2]byte
+		if _, err := io.ReadFull(br, length[:]); err != nil {
+			return nil, err
+		}
+		size := int(binary.BigEndian.Uint16(length[:])) - 2
+		if size < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, err
+		}
+
+		if marker == jpegMarkerAPP1 && bytes.HasPrefix(data, exifHeader) {
+			return data, nil
+		}
+	}
+}
+
+// writeJPEGWithExif writes the JPEG image in b to w with the raw Exif data
+// in exif in an APP1 segment directly after the start of image marker.
+func writeJPEGWithExif(w io.Writer, b, exif []byte) error {
+	if len(b) < 2 || len(exif) > jpegMaxSegmentSize {
+		_, err := w.Write(b)
+		return err
+	}
+
+	var header [4]byte
+	header[0] = 0xff
+	header[1] = jpegMarkerAPP1
+	binary.BigEndian.PutUint16(header[2:], uint16(len(exif)+2))
+
+	for _, bb := range [][]byte{b[:2], header[:], exif, b[2:]} {
+		if _, err := w.Write(bb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tiffData is the TIFF structure that holds the Exif tags.
+type tiffData struct {
+	b     []byte
+	order binary.ByteOrder
+	ifd0  int
+}
+
+func newTIFFData(exif []byte) (*tiffData, bool) {
+	if !bytes.HasPrefix(exif, exifHeader) {
+		return nil, false
+	}
+	b := exif[len(exifHeader):]
+	if len(b) < 8 {
+		return nil, false
+	}
+
+	t := &tiffData{b: b}
+
+	switch string(b[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, false
+	}
+
+	if t.order.Uint16(b[2:]) != 42 {
+		return nil, false
+	}
+
+	t.ifd0 = int(t.order.Uint32(b[4:]))
+	if _, ok := t.numEntries(t.ifd0); !ok {
+		return nil, false
+	}
+
+	return t, true
+}
+
+// numEntries returns the number of entries in the IFD at offset, and
+// whether the IFD is within bounds.
+func (t *tiffData) numEntries(offset int) (int, bool) {
+	if offset < 8 || offset+2 > len(t.b) {
+		return 0, false
+	}
+	n := int(t.order.Uint16(t.b[offset:]))
+	// The entries are followed by the offset to the next IFD.
+	if offset+2+n*12+4 > len(t.b) {
+		return 0, false
+	}
+	return n, true
+}
+
+// findEntry returns the offset of the entry for tag in the IFD at offset,
+// or -1 if not found.
+func (t *tiffData) findEntry(offset int, tag uint16) int {
+	n, ok := t.numEntries(offset)
+	if !ok {
+		return -1
+	}
+	for i := 0; i < n; i++ {
+		entry := offset + 2 + i*12
+		if t.order.Uint16(t.b[entry:]) == tag {
+			return entry
+		}
+	}
+	return -1
+}
+
+func (t *tiffData) orientation() int {
+	entry := t.findEntry(t.ifd0, tiffTagOrientation)
+	if entry == -1 {
+		return 0
+	}
+	return int(t.order.Uint16(t.b[entry+8:]))
+}
+
+func (t *tiffData) setOrientation(orientation uint16) {
+	if entry := t.findEntry(t.ifd0, tiffTagOrientation); entry != -1 {
+		t.order.PutUint16(t.b[entry+8:], orientation)
+	}
+}
+
+// removeGPS removes the GPS IFD pointer from IFD0 and zeroes the GPS IFD
+// and its values. It returns false if the GPS data could not be removed.
+func (t *tiffData) removeGPS() bool {
+	entry := t.findEntry(t.ifd0, tiffTagGPSInfo)
+	if entry == -1 {
+		return true
+	}
+
+	gps := int(t.order.Uint32(t.b[entry+8:]))
+	n, ok := t.numEntries(gps)
+	if !ok {
+		return false
+	}
+
+	typeSizes := map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+	for i := 0; i < n; i++ {
+		e := gps + 2 + i*12
+		size := typeSizes[t.order.Uint16(t.b[e+2:])] * int(t.order.Uint32(t.b[e+4:]))
+		if size > 4 {
+			offset := int(t.order.Uint32(t.b[e+8:]))
+			if offset < 8 || offset+size > len(t.b) {
+				return false
+			}
+			zero(t.b[offset : offset+size])
+		}
+	}
+	zero(t.b[gps : gps+2+n*12+4])
+
+	// Remove the entry from IFD0 by moving the entries after it and the
+	// next IFD offset one entry back.
+	n0, _ := t.numEntries(t.ifd0)
+	end := t.ifd0 + 2 + n0*12 + 4
+	copy(t.b[entry:], t.b[entry+12:end])
+	zero(t.b[end-12 : end])
+	t.order.PutUint16(t.b[t.ifd0:], uint16(n0-1))
+
+	return true
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// PrepareExif returns a copy of the raw Exif data in exif to write to a
+// processed image with the given metadata mode. If oriented is set, the
+// orientation is reset, as the image is already rotated. It returns nil
+// if no metadata should be kept, or if the data could not be handled.
+func PrepareExif(exif []byte, mode string, oriented bool) []byte {
+	if mode != MetadataKeep && mode != MetadataKeepNoGPS {
+		return nil
+	}
+
+	exif = append([]byte(nil), exif...)
+	t, ok := newTIFFData(exif)
+	if !ok {
+		return nil
+	}
+
+	if oriented {
+		t.setOrientation(1)
+	}
+
+	if mode == MetadataKeepNoGPS && !t.removeGPS() {
+		// Better safe than sorry.
+		return nil
+	}
+
+	return exif
+}
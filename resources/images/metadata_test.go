@@ -0,0 +1,145 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// newTestExif creates raw Exif data with the given orientation and a GPS
+// latitude, in big endian byte order.
+func newTestExif(orientation uint16) []byte {
+	var b bytes.Buffer
+	w := func(v interface{}) {
+		binary.Write(&b, binary.BigEndian, v)
+	}
+
+	b.Write(exifHeader)
+	b.WriteString("MM")
+	w(uint16(42))
+	w(uint32(8))
+
+	// IFD0 at 8: Orientation and the GPS IFD pointer.
+	w(uint16(2))
+	w([]uint16{tiffTagOrientation, 3})
+	w(uint32(1))
+	w([]uint16{orientation, 0})
+	w([]uint16{tiffTagGPSInfo, 4})
+	w(uint32(1))
+	w(uint32(38))
+	w(uint32(0))
+
+	// GPS IFD at 38: GPSLatitudeRef and GPSLatitude.
+	w(uint16(2))
+	w([]uint16{1, 2})
+	w(uint32(2))
+	b.WriteString("N\x00\x00\x00")
+	w([]uint16{2, 5})
+	w(uint32(3))
+	w(uint32(68))
+	w(uint32(0))
+
+	// GPSLatitude values at 68.
+	w([]uint32{36, 1, 35, 1, 50, 1})
+
+	return b.Bytes()
+}
+
+func TestJPEGExif(t *testing.T) {
+	c := qt.New(t)
+
+	exif := newTestExif(6)
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	var buf bytes.Buffer
+	c.Assert(jpeg.Encode(&buf, img, nil), qt.IsNil)
+
+	noExif, err := ReadJPEGExif(bytes.NewReader(buf.Bytes()))
+	c.Assert(err, qt.IsNil)
+	c.Assert(noExif, qt.IsNil)
+
+	var withExif bytes.Buffer
+	c.Assert(writeJPEGWithExif(&withExif, buf.Bytes(), exif), qt.IsNil)
+
+	got, err := ReadJPEGExif(bytes.NewReader(withExif.Bytes()))
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, exif)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(withExif.Bytes()))
+	c.Assert(err, qt.IsNil)
+	c.Assert(decoded.Bounds(), qt.Equals, img.Bounds())
+}
+
+func TestPrepareExif(t *testing.T) {
+	c := qt.New(t)
+
+	exif := newTestExif(6)
+
+	c.Assert(PrepareExif(exif, MetadataStrip, false), qt.IsNil)
+
+	kept := PrepareExif(exif, MetadataKeep, false)
+	c.Assert(kept, qt.DeepEquals, exif)
+
+	kept = PrepareExif(exif, MetadataKeep, true)
+	td, ok := newTIFFData(kept)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(td.orientation(), qt.Equals, 1)
+	c.Assert(td.findEntry(td.ifd0, tiffTagGPSInfo), qt.Not(qt.Equals), -1)
+
+	// The source is not modified.
+	td, _ = newTIFFData(exif)
+	c.Assert(td.orientation(), qt.Equals, 6)
+
+	noGPS := PrepareExif(exif, MetadataKeepNoGPS, true)
+	c.Assert(noGPS, qt.HasLen, len(exif))
+	td, ok = newTIFFData(noGPS)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(td.orientation(), qt.Equals, 1)
+	c.Assert(td.findEntry(td.ifd0, tiffTagGPSInfo), qt.Equals, -1)
+	n, _ := td.numEntries(td.ifd0)
+	c.Assert(n, qt.Equals, 1)
+	// The GPS IFD and the latitude values are zeroed.
+	c.Assert(noGPS[len(exifHeader)+38:], qt.DeepEquals, make([]byte, len(noGPS)-len(exifHeader)-38))
+
+	c.Assert(PrepareExif([]byte("Exif\x00\x00XX"), MetadataKeep, false), qt.IsNil)
+}
+
+func TestOrientationFilters(t *testing.T) {
+	c := qt.New(t)
+
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+
+	// A 4x2 image with a red top row, stored as it would be for orientation 6.
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		src.Set(x, 0, red)
+		src.Set(x, 1, blue)
+	}
+
+	p := &ImageProcessor{}
+	dst, err := p.Filter(src, orientationFilters[6])
+	c.Assert(err, qt.IsNil)
+	c.Assert(dst.Bounds(), qt.Equals, image.Rect(0, 0, 2, 4))
+	// Rotated 90 degrees clockwise.
+	c.Assert(dst.At(0, 0), qt.Equals, blue)
+	c.Assert(dst.At(1, 0), qt.Equals, red)
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"math"
+	"strings"
+
+	"github.com/disintegration/gift"
+	"github.com/pkg/errors"
+)
+
+const (
+	// The max width or height of the inline placeholder image.
+	placeholderSize = 16
+
+	// The max width or height of the image used to calculate the BlurHash
+	// and the colors. This is plenty for the low frequencies in a BlurHash.
+	placeholderSampleSize = 64
+
+	// The max number of dominant colors.
+	placeholderNumColors = 6
+)
+
+// Placeholders holds low-quality placeholders for an image, to show while
+// the image loads.
+type Placeholders struct {
+	// A BlurHash, see https://blurha.sh
+	BlurHash string
+
+	// A tiny version of the image as a base64 encoded PNG data URL.
+	Placeholder string
+
+	// The dominant colors as hex strings, e.g. "#ff0000", the most
+	// dominant first.
+	Colors []string
+}
+
+// CreatePlaceholders creates the placeholders for src. The image is first
+// oriented according to the given Exif orientation, if set.
+func (p *ImageProcessor) CreatePlaceholders(src image.Image, orientation int) (*Placeholders, error) {
+	if anim, ok := src.(AnimatedGIF); ok {
+		// Use the first frame only.
+		src = anim.GIF().Image[0]
+	}
+
+	var filters []gift.Filter
+	if f, found := orientationFilters[orientation]; found {
+		filters = append(filters, f)
+	}
+
+	sample, err := p.Filter(src, append(filters, resizeToFit(src, orientation, placeholderSampleSize))...)
+	if err != nil {
+		return nil, err
+	}
+
+	small, err := p.Filter(sample, resizeToFit(sample, 0, placeholderSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, small); err != nil {
+		return nil, err
+	}
+
+	xComponents, yComponents := 4, 3
+	if b := sample.Bounds(); b.Dy() > b.Dx() {
+		xComponents, yComponents = 3, 4
+	}
+
+	blurHash, err := EncodeBlurHash(sample, xComponents, yComponents)
+	if err != nil {
+		return nil, err
+	}
+
+	var colors []string
+	for _, c := range DominantColors(sample, placeholderNumColors) {
+		colors = append(colors, ColorToHexString(c))
+	}
+
+	return &Placeholders{
+		BlurHash:    blurHash,
+		Placeholder: "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+		Colors:      colors,
+	}, nil
+}
+
+// resizeToFit returns a filter that downscales src, after the given
+// orientation is applied, to fit inside a square with the given size.
+func resizeToFit(src image.Image, orientation, size int) gift.Filter {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if orientation >= 5 {
+		// Rotated 90 or 270 degrees.
+		w, h = h, w
+	}
+
+	if w <= size && h <= size {
+		return gift.Resize(w, h, gift.BoxResampling)
+	}
+
+	if w > h {
+		return gift.Resize(size, 0, gift.BoxResampling)
+	}
+	return gift.Resize(0, size, gift.BoxResampling)
+}
+
+const blurHashCharacters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// EncodeBlurHash encodes img to a BlurHash with the given number of
+// components in each direction, see https://github.com/woltapp/blurhash
+func EncodeBlurHash(img image.Image, xComponents, yComponents int) (string, error) {
+	if xComponents < 1 || xComponents > 9 || yComponents < 1 || yComponents > 9 {
+		return "", errors.New("BlurHash components must be between 1 and 9")
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return "", errors.New("cannot create a BlurHash for an empty image")
+	}
+
+	// Convert the image to linear RGB once.
+	pixels := make([][3]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			pixels[y*width+x] = [3]float64{
+				sRGBToLinear(r >> 8),
+				sRGBToLinear(g >> 8),
+				sRGBToLinear(b >> 8),
+			}
+		}
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1.0
+			}
+
+			var factor [3]float64
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+					p := pixels[y*width+x]
+					factor[0] += basis * p[0]
+					factor[1] += basis * p[1]
+					factor[2] += basis * p[2]
+				}
+			}
+
+			scale := normalisation / float64(width*height)
+			factors = append(factors, [3]float64{factor[0] * scale, factor[1] * scale, factor[2] * scale})
+		}
+	}
+
+	dc, ac := factors[0], factors[1:]
+
+	var sb strings.Builder
+	encodeBase83(&sb, (xComponents-1)+(yComponents-1)*9, 1)
+
+	maximumValue := 1.0
+	if len(ac) > 0 {
+		var actualMaximumValue float64
+		for _, f := range ac {
+			for _, v := range f {
+				actualMaximumValue = math.Max(actualMaximumValue, math.Abs(v))
+			}
+		}
+		quantisedMaximumValue := int(math.Max(0, math.Min(82, math.Floor(actualMaximumValue*166-0.5))))
+		maximumValue = float64(quantisedMaximumValue+1) / 166
+		encodeBase83(&sb, quantisedMaximumValue, 1)
+	} else {
+		encodeBase83(&sb, 0, 1)
+	}
+
+	encodeBase83(&sb, linearTosRGB(dc[0])<<16+linearTosRGB(dc[1])<<8+linearTosRGB(dc[2]), 4)
+
+	for _, f := range ac {
+		quant := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maximumValue, 0.5)*9+9.5))))
+		}
+		encodeBase83(&sb, quant(f[0])*19*19+quant(f[1])*19+quant(f[2]), 2)
+	}
+
+	return sb.String(), nil
+}
+
+func encodeBase83(sb *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		sb.WriteByte(blurHashCharacters[digit])
+	}
+}
+
+func sRGBToLinear(v uint32) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+func linearTosRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
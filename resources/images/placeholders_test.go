@@ -0,0 +1,115 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestEncodeBlurHash(t *testing.T) {
+	c := qt.New(t)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+	for i := range img.Pix {
+		if i%4 == 0 || i%4 == 3 {
+			img.Pix[i] = 255
+		}
+	}
+
+	// The size flag, the max AC value and the red DC component.
+	hash, err := EncodeBlurHash(img, 1, 1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(hash, qt.Equals, "00TI:j")
+
+	hash, err = EncodeBlurHash(img, 4, 3)
+	c.Assert(err, qt.IsNil)
+	c.Assert(hash, qt.HasLen, 28)
+	c.Assert(hash[:1], qt.Equals, "L")
+	c.Assert(hash[2:6], qt.Equals, "TI:j")
+	solid := hash
+
+	// Make the left half blue.
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			img.Set(x, y, color.NRGBA{B: 255, A: 255})
+		}
+	}
+	hash, err = EncodeBlurHash(img, 4, 3)
+	c.Assert(err, qt.IsNil)
+	c.Assert(hash, qt.HasLen, 28)
+	c.Assert(hash[:1], qt.Equals, "L")
+	c.Assert(hash, qt.Not(qt.Equals), solid)
+
+	_, err = EncodeBlurHash(img, 10, 3)
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = EncodeBlurHash(image.NewNRGBA(image.Rectangle{}), 4, 3)
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestCreatePlaceholders(t *testing.T) {
+	c := qt.New(t)
+
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+
+	// A 200x100 image with a red top half.
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			if y < 50 {
+				img.Set(x, y, red)
+			} else {
+				img.Set(x, y, blue)
+			}
+		}
+	}
+
+	decodePlaceholder := func(s string) image.Image {
+		c.Helper()
+		const prefix = "data:image/png;base64,"
+		c.Assert(strings.HasPrefix(s, prefix), qt.IsTrue)
+		b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, prefix))
+		c.Assert(err, qt.IsNil)
+		decoded, err := png.Decode(bytes.NewReader(b))
+		c.Assert(err, qt.IsNil)
+		return decoded
+	}
+
+	p := &ImageProcessor{}
+
+	placeholders, err := p.CreatePlaceholders(img, 0)
+	c.Assert(err, qt.IsNil)
+	c.Assert(placeholders.BlurHash, qt.HasLen, 28)
+	c.Assert(placeholders.Colors, qt.DeepEquals, []string{"#0000ff", "#ff0000"})
+	c.Assert(decodePlaceholder(placeholders.Placeholder).Bounds(), qt.Equals, image.Rect(0, 0, 16, 8))
+
+	// Rotated 90 degrees clockwise.
+	placeholders, err = p.CreatePlaceholders(img, 6)
+	c.Assert(err, qt.IsNil)
+	c.Assert(placeholders.BlurHash[:1], qt.Equals, "T")
+	c.Assert(decodePlaceholder(placeholders.Placeholder).Bounds(), qt.Equals, image.Rect(0, 0, 8, 16))
+
+	// Small images are not upscaled.
+	placeholders, err = p.CreatePlaceholders(image.NewNRGBA(image.Rect(0, 0, 4, 2)), 0)
+	c.Assert(err, qt.IsNil)
+	c.Assert(decodePlaceholder(placeholders.Placeholder).Bounds(), qt.Equals, image.Rect(0, 0, 4, 2))
+}
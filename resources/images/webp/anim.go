@@ -0,0 +1,195 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+
+	"github.com/bep/gowebp/libwebp/webpoptions"
+	"github.com/pkg/errors"
+)
+
+const (
+	vp8xFlagAnimation = 0x02
+	vp8xFlagAlpha     = 0x10
+
+	// Replace the canvas area with the frame instead of alpha-blending it.
+	anmfFlagNoBlend = 0x02
+
+	maxUint24 = 1<<24 - 1
+)
+
+// Frame is a frame in an animated WebP image.
+type Frame struct {
+	Image image.Image
+
+	// The time to show the frame in milliseconds.
+	Duration int
+}
+
+// EncodeAnimated writes the frames to w as an animated WebP image with the
+// given options. The frames must all cover the same bounds. The loop count is
+// the number of times to play the animation, 0 is forever.
+func EncodeAnimated(w io.Writer, frames []Frame, loopCount int, o webpoptions.EncodingOptions) error {
+	return encodeAnimated(w, frames, loopCount, func(w io.Writer, m image.Image) error {
+		return Encode(w, m, o)
+	})
+}
+
+// encodeAnimated encodes every frame as a still image and assembles the image
+// data in an animated WebP container, see
+// https://developers.google.com/speed/webp/docs/riff_container
+func encodeAnimated(w io.Writer, frames []Frame, loopCount int, encode func(w io.Writer, m image.Image) error) error {
+	if len(frames) == 0 {
+		return errors.New("no frames to encode")
+	}
+
+	bounds := frames[0].Image.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > maxUint24 || height > maxUint24 {
+		return errors.Errorf("invalid animation size %dx%d", width, height)
+	}
+
+	if loopCount < 0 || loopCount > 0xffff {
+		return errors.Errorf("invalid loop count %d", loopCount)
+	}
+
+	var (
+		flags  byte = vp8xFlagAnimation
+		chunks bytes.Buffer
+		buf    bytes.Buffer
+	)
+
+	for i, frame := range frames {
+		if frame.Image.Bounds() != bounds {
+			return errors.Errorf("frame %d: bounds %s differ from %s", i, frame.Image.Bounds(), bounds)
+		}
+
+		if op, ok := frame.Image.(interface{ Opaque() bool }); !ok || !op.Opaque() {
+			flags |= vp8xFlagAlpha
+		}
+
+		buf.Reset()
+		if err := encode(&buf, frame.Image); err != nil {
+			return err
+		}
+
+		data, err := imageChunks(buf.Bytes())
+		if err != nil {
+			return errors.Wrapf(err, "frame %d", i)
+		}
+
+		duration := frame.Duration
+		if duration < 0 {
+			duration = 0
+		} else if duration > maxUint24 {
+			duration = maxUint24
+		}
+
+		// The frame offset, which is always 0,0, followed by the size and
+		// the duration.
+		header := make([]byte, 16, 16+len(data))
+		putUint24(header[6:], width-1)
+		putUint24(header[9:], height-1)
+		putUint24(header[12:], duration)
+		header[15] = anmfFlagNoBlend
+
+		writeChunk(&chunks, "ANMF", append(header, data...))
+	}
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = flags
+	putUint24(vp8x[4:], width-1)
+	putUint24(vp8x[7:], height-1)
+
+	// The background color, which is transparent, and the loop count.
+	anim := make([]byte, 6)
+	binary.LittleEndian.PutUint16(anim[4:], uint16(loopCount))
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	writeChunk(&body, "VP8X", vp8x)
+	writeChunk(&body, "ANIM", anim)
+	body.Write(chunks.Bytes())
+
+	var riff [8]byte
+	copy(riff[:], "RIFF")
+	binary.LittleEndian.PutUint32(riff[4:], uint32(body.Len()))
+
+	if _, err := w.Write(riff[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+// imageChunks returns the ALPH and image data chunks, including their
+// headers, in the still WebP image b.
+func imageChunks(b []byte) ([]byte, error) {
+	if len(b) < 12 || string(b[:4]) != "RIFF" || string(b[8:12]) != "WEBP" {
+		return nil, errors.New("invalid WebP image")
+	}
+
+	var (
+		data     []byte
+		hasImage bool
+	)
+
+	for pos := 12; pos+8 <= len(b); {
+		fourCC := string(b[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(b[pos+4:]))
+		end := pos + 8 + size
+		if size < 0 || end > len(b) {
+			return nil, errors.Errorf("invalid WebP chunk %q", fourCC)
+		}
+
+		switch fourCC {
+		case "ALPH", "VP8 ", "VP8L":
+			data = append(data, b[pos:end]...)
+			if size%2 == 1 {
+				data = append(data, 0)
+			}
+			hasImage = hasImage || fourCC != "ALPH"
+		}
+
+		// Chunks are padded to an even size.
+		pos = end + size%2
+	}
+
+	if !hasImage {
+		return nil, errors.New("no image data in WebP image")
+	}
+
+	return data, nil
+}
+
+func writeChunk(w *bytes.Buffer, fourCC string, payload []byte) {
+	var header [8]byte
+	copy(header[:], fourCC)
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(payload)))
+	w.Write(header[:])
+	w.Write(payload)
+	if len(payload)%2 == 1 {
+		w.WriteByte(0)
+	}
+}
+
+func putUint24(b []byte, v int) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type testChunk struct {
+	fourCC  string
+	payload []byte
+}
+
+func readTestChunks(c *qt.C, b []byte) []testChunk {
+	c.Helper()
+	var chunks []testChunk
+	for len(b) > 0 {
+		c.Assert(len(b) >= 8, qt.IsTrue)
+		size := int(binary.LittleEndian.Uint32(b[4:]))
+		chunks = append(chunks, testChunk{fourCC: string(b[:4]), payload: b[8 : 8+size]})
+		b = b[8+size+size%2:]
+	}
+	return chunks
+}
+
+func TestEncodeAnimated(t *testing.T) {
+	c := qt.New(t)
+
+	// A fake encoder writing a lossless still image for opaque images and a
+	// lossy image with an alpha channel for the others.
+	encode := func(w io.Writer, m image.Image) error {
+		var body bytes.Buffer
+		body.WriteString("WEBP")
+		if m.(*image.NRGBA).Opaque() {
+			writeChunk(&body, "VP8L", []byte{1, 2, 3})
+		} else {
+			writeChunk(&body, "VP8X", make([]byte, 10))
+			writeChunk(&body, "ALPH", []byte{4})
+			writeChunk(&body, "VP8 ", []byte{5, 6})
+		}
+		var riff [8]byte
+		copy(riff[:], "RIFF")
+		binary.LittleEndian.PutUint32(riff[4:], uint32(body.Len()))
+		w.Write(riff[:])
+		_, err := w.Write(body.Bytes())
+		return err
+	}
+
+	opaque := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i := 3; i < len(opaque.Pix); i += 4 {
+		opaque.Pix[i] = 0xff
+	}
+	transparent := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	transparent.Set(0, 0, color.NRGBA{R: 0xff, A: 0xff})
+
+	var buf bytes.Buffer
+	c.Assert(encodeAnimated(&buf, []Frame{{Image: opaque, Duration: 100}, {Image: transparent, Duration: 250}}, 2, encode), qt.IsNil)
+
+	b := buf.Bytes()
+	c.Assert(string(b[:4]), qt.Equals, "RIFF")
+	c.Assert(int(binary.LittleEndian.Uint32(b[4:])), qt.Equals, len(b)-8)
+	c.Assert(string(b[8:12]), qt.Equals, "WEBP")
+
+	chunks := readTestChunks(c, b[12:])
+	c.Assert(chunks, qt.HasLen, 4)
+	c.Assert(chunks[0].fourCC, qt.Equals, "VP8X")
+	c.Assert(chunks[0].payload, qt.DeepEquals, []byte{vp8xFlagAnimation | vp8xFlagAlpha, 0, 0, 0, 2, 0, 0, 1, 0, 0})
+	c.Assert(chunks[1].fourCC, qt.Equals, "ANIM")
+	c.Assert(chunks[1].payload, qt.DeepEquals, []byte{0, 0, 0, 0, 2, 0})
+
+	for i, expect := range []struct {
+		duration byte
+		fourCCs  []string
+	}{
+		{100, []string{"VP8L"}},
+		{250, []string{"ALPH", "VP8 "}},
+	} {
+		anmf := chunks[2+i]
+		c.Assert(anmf.fourCC, qt.Equals, "ANMF")
+		c.Assert(anmf.payload[:16], qt.DeepEquals, []byte{0, 0, 0, 0, 0, 0, 2, 0, 0, 1, 0, 0, expect.duration, 0, 0, anmfFlagNoBlend})
+		var fourCCs []string
+		for _, chunk := range readTestChunks(c, anmf.payload[16:]) {
+			fourCCs = append(fourCCs, chunk.fourCC)
+		}
+		c.Assert(fourCCs, qt.DeepEquals, expect.fourCCs)
+	}
+
+	err := encodeAnimated(&buf, []Frame{{Image: opaque}, {Image: image.NewNRGBA(image.Rect(0, 0, 1, 1))}}, 0, encode)
+	c.Assert(err, qt.ErrorMatches, "frame 1: bounds .* differ from .*")
+
+	_, err = imageChunks([]byte("RIFF\x04\x00\x00\x00WEBP"))
+	c.Assert(err, qt.ErrorMatches, "no image data in WebP image")
+}
@@ -64,6 +64,9 @@ type TargetPathDescriptor struct {
 	// Used to create paginator links.
 	Addends string
 
+	// The pagination config of the page, used to create paginator links.
+	Pagination PaginationConfig
+
 	// The expanded permalink if defined for the section, ready to use.
 	ExpandedPermalink string
 
@@ -43,6 +43,28 @@ type PageGenealogist interface {
 	// Template example:
 	// {{ $related := .RegularPages.RelatedTo ( keyVals "tags" "hugo", "rocks")  ( keyVals "date" .Date ) }}
 	RelatedTo(args ...types.KeyValues) (Pages, error)
+
+	// Template example:
+	// {{ range .RegularPages.RelatedFragments . }}{{ .RelPermalink }}#{{ .Fragment.ID }}{{ end }}
+	RelatedFragments(doc related.Document, indices ...interface{}) ([]RelatedFragment, error)
+}
+
+// RelatedFragment is a page found by RelatedFragments and the fragments in
+// it, e.g. headings, that matched.
+type RelatedFragment struct {
+	Page
+
+	// The matching fragments, best match first. Only indices of type
+	// fragments match fragments, so this may be empty.
+	Fragments []related.Fragment
+}
+
+// Fragment returns the best matching fragment, if any.
+func (r RelatedFragment) Fragment() related.Fragment {
+	if len(r.Fragments) == 0 {
+		return related.Fragment{}
+	}
+	return r.Fragments[0]
 }
 
 // Related searches all the configured indices with the search keywords from the
@@ -89,6 +111,43 @@ func (p Pages) RelatedTo(args ...types.KeyValues) (Pages, error) {
 	return p.search(args...)
 }
 
+// RelatedFragments searches the given indices, or all the configured indices
+// if none given, with the search keywords from the supplied document, and
+// returns the matching pages with the fragments in them that matched.
+func (p Pages) RelatedFragments(doc related.Document, indices ...interface{}) ([]RelatedFragment, error) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+
+	indicesStr, err := cast.ToStringSliceE(indices)
+	if err != nil {
+		return nil, err
+	}
+
+	searchIndex, err := p.invertedIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := searchIndex.SearchDocMatches(doc, indicesStr...)
+	if err != nil {
+		return nil, err
+	}
+
+	page, isPage := doc.(Page)
+
+	var result []RelatedFragment
+	for _, match := range matches {
+		mp := match.Doc.(Page)
+		if isPage && page.Eq(mp) {
+			continue
+		}
+		result = append(result, RelatedFragment{Page: mp, Fragments: match.Fragments})
+	}
+
+	return result, nil
+}
+
 func (p Pages) search(args ...types.KeyValues) (Pages, error) {
 	return p.withInvertedIndex(func(idx *related.InvertedIndex) ([]related.Document, error) {
 		return idx.SearchKeyValues(args...)
@@ -106,14 +165,7 @@ func (p Pages) withInvertedIndex(search func(idx *related.InvertedIndex) ([]rela
 		return nil, nil
 	}
 
-	d, ok := p[0].(InternalDependencies)
-	if !ok {
-		return nil, errors.Errorf("invalid type %T in related search", p[0])
-	}
-
-	cache := d.GetRelatedDocsHandler()
-
-	searchIndex, err := cache.getOrCreateIndex(p)
+	searchIndex, err := p.invertedIndex()
 	if err != nil {
 		return nil, err
 	}
@@ -134,6 +186,15 @@ func (p Pages) withInvertedIndex(search func(idx *related.InvertedIndex) ([]rela
 	return nil, nil
 }
 
+func (p Pages) invertedIndex() (*related.InvertedIndex, error) {
+	d, ok := p[0].(InternalDependencies)
+	if !ok {
+		return nil, errors.Errorf("invalid type %T in related search", p[0])
+	}
+
+	return d.GetRelatedDocsHandler().getOrCreateIndex(p)
+}
+
 type cachedPostingList struct {
 	p Pages
 
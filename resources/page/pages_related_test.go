@@ -70,6 +70,14 @@ func TestRelated(t *testing.T) {
 	c.Assert(result[0].Title(), qt.Equals, "Page 2")
 	c.Assert(result[1].Title(), qt.Equals, "Page 3")
 
+	fragments, err := pages.RelatedFragments(pages[0], "keywords")
+	c.Assert(err, qt.IsNil)
+	c.Assert(len(fragments), qt.Equals, 2)
+	c.Assert(fragments[0].Title(), qt.Equals, "Page 2")
+	c.Assert(fragments[1].Title(), qt.Equals, "Page 3")
+	c.Assert(fragments[0].Fragments, qt.HasLen, 0)
+	c.Assert(fragments[0].Fragment().ID, qt.Equals, "")
+
 	result, err = pages.RelatedTo(types.NewKeyValuesStrings("keywords", "bep", "rocks"))
 	c.Assert(err, qt.IsNil)
 	c.Assert(len(result), qt.Equals, 2)
@@ -18,9 +18,13 @@ import (
 	"fmt"
 	"html/template"
 	"math"
+	"path"
 	"reflect"
+	"strconv"
+	"strings"
 
-	"github.com/gohugoio/hugo/config"
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/mitchellh/mapstructure"
 
 	"github.com/spf13/cast"
 )
@@ -248,9 +252,114 @@ func splitPageGroups(pageGroups PagesGroup, size int) []paginatedElement {
 	return split
 }
 
-func ResolvePagerSize(cfg config.Provider, options ...interface{}) (int, error) {
+/*
+PaginationConfig configures the pagination of a page. The site config
+paginate and paginatePath are the defaults, which can be overridden in the
+front matter of a page, or for a section or taxonomy with cascade:
+
+	cascade:
+	  pagination:
+	    pagerSize: 50
+	    path: /:section/p:number/
+	    firstPageOwnURL: true
+*/
+type PaginationConfig struct {
+	// The number of elements in each pager.
+	PagerSize int
+
+	// The path to the pagers, e.g. "page" for /posts/page/2/.
+	// It may be a pattern with the pager number, e.g. "p:number"
+	// for /posts/p2/. A pattern starting with a slash is relative to the
+	// site root rather than to the page, and may also contain :section,
+	// the first section of the page, and :sections, all of its sections.
+	Path string
+
+	// Render the first pager at its path, e.g. /posts/page/1/, and link to it,
+	// rather than redirecting that path to the page.
+	FirstPageOwnURL bool
+}
+
+// paginationConfigKeys are the lower cased keys in PaginationConfig.
+var paginationConfigKeys = map[string]bool{
+	"pagersize":       true,
+	"path":            true,
+	"firstpageownurl": true,
+}
+
+// ToPaginationConfigMap returns v as a map if it has the shape of a
+// pagination config, i.e. a non-empty map with only PaginationConfig keys.
+// The pagination front matter key may also be a param used in templates.
+func ToPaginationConfigMap(v interface{}) (map[string]interface{}, bool) {
+	m, err := maps.ToStringMapE(v)
+	if err != nil || len(m) == 0 {
+		return nil, false
+	}
+	for k := range m {
+		if !paginationConfigKeys[strings.ToLower(k)] {
+			return nil, false
+		}
+	}
+	return m, true
+}
+
+// DecodePaginationConfig creates a pagination config from m, using defaults
+// for any missing values.
+func DecodePaginationConfig(defaults PaginationConfig, m map[string]interface{}) (PaginationConfig, error) {
+	c := defaults
+	if err := mapstructure.WeakDecode(m, &c); err != nil {
+		return c, err
+	}
+
+	if c.PagerSize <= 0 {
+		return c, errors.New("pagination pagerSize must be a positive integer")
+	}
+
+	c.Path = strings.TrimSpace(c.Path)
+	if strings.Trim(c.Path, "/") == "" {
+		return c, errors.New("pagination path must be set")
+	}
+
+	return c, nil
+}
+
+// ForPager returns the descriptor for the pager with the given number.
+func (d TargetPathDescriptor) ForPager(number int) TargetPathDescriptor {
+	pattern := d.Pagination.Path
+	if pattern == "" {
+		pattern = strings.Trim(d.PathSpec.PaginatePath, "/")
+	}
+	if !strings.Contains(pattern, ":number") {
+		pattern = path.Join(pattern, ":number")
+	}
+
+	var section string
+	if len(d.Sections) > 0 {
+		section = d.Sections[0]
+	}
+
+	rel := strings.NewReplacer(
+		":number", strconv.Itoa(number),
+		":sections", path.Join(d.Sections...),
+		":section", section,
+	).Replace(pattern)
+	rel = path.Clean("/"+rel) + "/"
+
+	if strings.HasPrefix(pattern, "/") && d.Kind != KindHome {
+		d.URL = rel
+		d.Addends = ""
+		d.ForcePrefix = true
+	} else {
+		d.Addends = rel
+	}
+
+	return d
+}
+
+// ResolvePagerSize returns the pager size given in options, if any, else
+// the one in the pagination config.
+func ResolvePagerSize(cfg PaginationConfig, options ...interface{}) (int, error) {
 	if len(options) == 0 {
-		return cfg.GetInt("paginate"), nil
+		return cfg.PagerSize, nil
 	}
 
 	if len(options) > 1 {
@@ -385,10 +494,8 @@ func newPaginator(elements []paginatedElement, total, size int, urlFactory pagin
 func newPaginationURLFactory(d TargetPathDescriptor) paginationURLFactory {
 	return func(pageNumber int) string {
 		pathDescriptor := d
-		var rel string
-		if pageNumber > 1 {
-			rel = fmt.Sprintf("/%s/%d/", d.PathSpec.PaginatePath, pageNumber)
-			pathDescriptor.Addends = rel
+		if pageNumber > 1 || d.Pagination.FirstPageOwnURL {
+			pathDescriptor = d.ForPager(pageNumber)
 		}
 
 		return CreateTargetPaths(pathDescriptor).RelPermalink(d.PathSpec)
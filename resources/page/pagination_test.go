@@ -219,6 +219,26 @@ func TestPaginationURLFactory(t *testing.T) {
 					TargetPathDescriptor{Kind: KindHome, Type: output.JSONFormat},
 					"http://example.com/", 42, "/zoo/42/index.json", "/zoo/42.json",
 				},
+				{
+					"Section with path pattern",
+					TargetPathDescriptor{Kind: KindSection, Type: output.HTMLFormat, Sections: []string{"news"}, Pagination: PaginationConfig{Path: "page-:number"}},
+					"http://example.com/", 3, "/news/page-3/", "/news/page-3.html",
+				},
+				{
+					"Section with root relative path pattern",
+					TargetPathDescriptor{Kind: KindSection, Type: output.HTMLFormat, Sections: []string{"news", "sports"}, Pagination: PaginationConfig{Path: "/archive/:sections/p:number/"}},
+					"http://example.com/", 3, "/archive/news/sports/p3/", "/archive/news/sports/p3/",
+				},
+				{
+					"Section first page with own URL",
+					TargetPathDescriptor{Kind: KindSection, Type: output.HTMLFormat, Sections: []string{"news"}, Pagination: PaginationConfig{Path: "/:section/p:number/", FirstPageOwnURL: true}},
+					"http://example.com/", 1, "/news/p1/", "/news/p1/",
+				},
+				{
+					"Section first page",
+					TargetPathDescriptor{Kind: KindSection, Type: output.HTMLFormat, Sections: []string{"news"}, Pagination: PaginationConfig{Path: "/:section/p:number/"}},
+					"http://example.com/", 1, "/news/", "/news.html",
+				},
 			}
 
 			for _, test := range tests {
@@ -245,6 +265,44 @@ func TestPaginationURLFactory(t *testing.T) {
 	}
 }
 
+func TestDecodePaginationConfig(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+
+	defaults := PaginationConfig{PagerSize: 10, Path: "page"}
+
+	cfg, err := DecodePaginationConfig(defaults, map[string]interface{}{"pagersize": "50", "firstpageownurl": true})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg, qt.Equals, PaginationConfig{PagerSize: 50, Path: "page", FirstPageOwnURL: true})
+
+	cfg, err = DecodePaginationConfig(defaults, map[string]interface{}{"path": "/:section/p:number/"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.PagerSize, qt.Equals, 10)
+	c.Assert(cfg.Path, qt.Equals, "/:section/p:number/")
+
+	_, err = DecodePaginationConfig(defaults, map[string]interface{}{"pagersize": 0})
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = DecodePaginationConfig(defaults, map[string]interface{}{"path": "/"})
+	c.Assert(err, qt.Not(qt.IsNil))
+
+	m, ok := ToPaginationConfigMap(map[string]interface{}{"pagerSize": 5, "path": "p"})
+	c.Assert(ok, qt.Equals, true)
+	c.Assert(m, qt.HasLen, 2)
+	_, ok = ToPaginationConfigMap(map[string]interface{}{"pagerSize": 5, "style": "dots"})
+	c.Assert(ok, qt.Equals, false)
+	_, ok = ToPaginationConfigMap(map[string]interface{}{})
+	c.Assert(ok, qt.Equals, false)
+	_, ok = ToPaginationConfigMap("numbered")
+	c.Assert(ok, qt.Equals, false)
+
+	size, err := ResolvePagerSize(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(size, qt.Equals, 10)
+	size, err = ResolvePagerSize(cfg, 5)
+	c.Assert(err, qt.IsNil)
+	c.Assert(size, qt.Equals, 5)
+}
+
 func TestProbablyEqualPageLists(t *testing.T) {
 	t.Parallel()
 	fivePages := createTestPages(5)
@@ -24,18 +24,26 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 
+	"github.com/gohugoio/hugo/common/maps"
 	"github.com/gohugoio/hugo/helpers"
 )
 
-// PermalinkExpander holds permalin mappings per section.
+// PermalinkExpander holds permalink mappings per section, and per taxonomy
+// for taxonomy and term pages.
 type PermalinkExpander struct {
 	// knownPermalinkAttributes maps :tags in a permalink specification to a
 	// function which, given a page and the tag, returns the resulting string
 	// to be used to replace that tag.
 	knownPermalinkAttributes map[string]pageToPermaAttribute
 
-	expanders map[string]func(Page) (string, error)
+	// Maps page kind and section to the expander. The expanders for
+	// the kind "" apply to regular pages and terms.
+	expanders map[string]map[string]func(Page) (string, error)
+
+	// The configured taxonomies, plural names, e.g. "tags".
+	taxonomies map[string]bool
 
 	ps *helpers.PathSpec
 }
@@ -58,6 +66,23 @@ func (p PermalinkExpander) callback(attr string) (pageToPermaAttribute, bool) {
 		}, true
 	}
 
+	if strings.HasPrefix(attr, "params.") {
+		return p.pageToPermalinkParam, true
+	}
+
+	// The terms of a taxonomy, e.g. tags[0] for the first tag.
+	taxonomy := attr
+	if i := strings.Index(attr, "["); i != -1 {
+		taxonomy = attr[:i]
+	}
+	if p.taxonomies[taxonomy] {
+		fn := p.toSliceFunc(strings.TrimPrefix(attr, taxonomy))
+		toTerms := p.pageToPermalinkTerms
+		return func(p Page, s string) (string, error) {
+			return toTerms(p, taxonomy, fn)
+		}, true
+	}
+
 	// Make sure this comes after all the other checks.
 	if referenceTime.Format(attr) != attr {
 		return p.pageToPermalinkDate, true
@@ -69,7 +94,11 @@ func (p PermalinkExpander) callback(attr string) (pageToPermaAttribute, bool) {
 // NewPermalinkExpander creates a new PermalinkExpander configured by the given
 // PathSpec.
 func NewPermalinkExpander(ps *helpers.PathSpec) (PermalinkExpander, error) {
-	p := PermalinkExpander{ps: ps}
+	p := PermalinkExpander{ps: ps, taxonomies: make(map[string]bool)}
+
+	for _, plural := range ps.Cfg.GetStringMapString("taxonomies") {
+		p.taxonomies[plural] = true
+	}
 
 	p.knownPermalinkAttributes = map[string]pageToPermaAttribute{
 		"year":        p.pageToPermalinkDate,
@@ -84,27 +113,58 @@ func NewPermalinkExpander(ps *helpers.PathSpec) (PermalinkExpander, error) {
 		"title":       p.pageToPermalinkTitle,
 		"slug":        p.pageToPermalinkSlugElseTitle,
 		"filename":    p.pageToPermalinkFilename,
-	}
 
-	patterns := ps.Cfg.GetStringMapString("permalinks")
-	if patterns == nil {
-		return p, nil
+		"contentbasename": p.pageToPermalinkContentBaseName,
+		"contentdir":      p.pageToPermalinkContentDir,
 	}
 
-	e, err := p.parse(patterns)
-	if err != nil {
-		return p, err
+	// Patterns per section, or per kind and section in the maps
+	// permalinks.taxonomy and permalinks.term.
+	patterns := make(map[string]map[string]string)
+	for k, v := range ps.Cfg.GetStringMap("permalinks") {
+		if pattern, ok := v.(string); ok {
+			if patterns[""] == nil {
+				patterns[""] = make(map[string]string)
+			}
+			patterns[""][k] = pattern
+			continue
+		}
+
+		kind := strings.ToLower(k)
+		if kind != KindTaxonomy && kind != KindTerm {
+			return p, errors.Errorf("permalinks: %q must be a string; only %q and %q can be maps", k, KindTaxonomy, KindTerm)
+		}
+
+		m, err := maps.ToStringMapStringE(v)
+		if err != nil {
+			return p, errors.Wrapf(err, "permalinks: invalid %q config", kind)
+		}
+		patterns[kind] = m
 	}
 
-	p.expanders = e
+	p.expanders = make(map[string]map[string]func(Page) (string, error))
+	for kind, kindPatterns := range patterns {
+		e, err := p.parse(kindPatterns)
+		if err != nil {
+			return p, err
+		}
+		p.expanders[kind] = e
+	}
 
 	return p, nil
 }
 
 // Expand expands the path in p according to the rules defined for the given key.
 // If no rules are found for the given key, an empty string is returned.
+// Taxonomy pages are only expanded with the rules in permalinks.taxonomy,
+// term pages with the rules in permalinks.term, if any, else with the rules
+// per section.
 func (l PermalinkExpander) Expand(key string, p Page) (string, error) {
-	expand, found := l.expanders[key]
+	expand, found := l.expanders[p.Kind()][key]
+
+	if !found && p.Kind() != KindTaxonomy {
+		expand, found = l.expanders[""][key]
+	}
 
 	if !found {
 		return "", nil
@@ -175,7 +235,7 @@ func (l PermalinkExpander) parse(patterns map[string]string) (map[string]func(Pa
 // can return a string to go in that position in the page (or an error)
 type pageToPermaAttribute func(Page, string) (string, error)
 
-var attributeRegexp = regexp.MustCompile(`:\w+(\[.+\])?`)
+var attributeRegexp = regexp.MustCompile(`:(?:params\.)?\w+(\[[^\]]+\])?`)
 
 // validate determines if a PathPattern is well-formed
 func (l PermalinkExpander) validate(pp string) bool {
@@ -266,6 +326,71 @@ func (l PermalinkExpander) pageToPermalinkSlugElseTitle(p Page, a string) (strin
 	return l.pageToPermalinkTitle(p, a)
 }
 
+// pageToPermalinkContentBaseName returns the URL-safe form of the content
+// file name without extension and language, or the directory name for
+// page bundles.
+func (l PermalinkExpander) pageToPermalinkContentBaseName(p Page, _ string) (string, error) {
+	if p.File().IsZero() {
+		return "", nil
+	}
+	return l.ps.URLize(p.File().ContentBaseName()), nil
+}
+
+// pageToPermalinkContentDir returns the URL-safe form of the directory of the
+// content file, relative to the content dir, or of the directory holding the
+// bundle for page bundles.
+func (l PermalinkExpander) pageToPermalinkContentDir(p Page, _ string) (string, error) {
+	if p.File().IsZero() {
+		return "", nil
+	}
+
+	dir := p.File().Dir()
+	if name := p.File().ContentBaseName(); name != p.File().TranslationBaseName() {
+		dir = strings.TrimSuffix(dir, name+helpers.FilePathSeparator)
+	}
+
+	return l.ps.URLize(filepath.ToSlash(strings.Trim(dir, helpers.FilePathSeparator))), nil
+}
+
+// pageToPermalinkParam returns the URL-safe form of the page param in
+// params.<name>. Site params are not used, a missing page param gives an
+// empty string.
+func (l PermalinkExpander) pageToPermalinkParam(p Page, attr string) (string, error) {
+	v, err := maps.GetNestedParam(strings.TrimPrefix(attr, "params."), ".", p.Params())
+	if err != nil || v == nil {
+		return "", err
+	}
+
+	s, err := cast.ToStringE(v)
+	if err != nil {
+		return "", errors.Errorf("param %q: %s", attr, err)
+	}
+
+	return l.ps.URLize(s), nil
+}
+
+// pageToPermalinkTerms returns the terms of the given taxonomy in the page
+// front matter, sliced by slice, joined with slashes.
+func (l PermalinkExpander) pageToPermalinkTerms(p Page, taxonomy string, slice func(s []string) []string) (string, error) {
+	// The page params are stored with lower case keys.
+	v := p.Params()[strings.ToLower(taxonomy)]
+	if v == nil {
+		return "", nil
+	}
+
+	terms, err := cast.ToStringSliceE(v)
+	if err != nil {
+		return "", errors.Errorf("taxonomy %q: %s", taxonomy, err)
+	}
+
+	var sanitized []string
+	for _, term := range slice(terms) {
+		sanitized = append(sanitized, l.ps.MakePathSanitized(term))
+	}
+
+	return path.Join(sanitized...), nil
+}
+
 func (l PermalinkExpander) pageToPermalinkSection(p Page, _ string) (string, error) {
 	return p.Section(), nil
 }
@@ -339,10 +464,11 @@ func (l PermalinkExpander) toSliceFunc(cut string) func(s []string) []string {
 	if !strings.Contains(opsStr, ":") {
 		toN := toNFunc(opts[0], true)
 		return func(s []string) []string {
-			if len(s) == 0 {
+			n := toN(s)
+			if n < 0 || n >= len(s) {
 				return nil
 			}
-			v := s[toN(s)]
+			v := s[n]
 			if v == "" {
 				return nil
 			}
@@ -20,6 +20,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/resources/resource"
+
 	qt "github.com/frankban/quicktest"
 )
 
@@ -156,6 +159,109 @@ func TestPermalinkExpansionConcurrent(t *testing.T) {
 	wg.Wait()
 }
 
+func TestPermalinkExpansionParamsAndTerms(t *testing.T) {
+	t.Parallel()
+
+	c := qt.New(t)
+
+	page := newTestPageWithFile("/docs/guides/deploy/index.md")
+	page.params["product"] = "Hugo Server"
+	page.params["tags"] = []string{"Go Lang", "Hosting"}
+	page.params["blogtags"] = []string{"Release Notes"}
+	page.currentSection = &testPage{sectionEntries: []string{"docs", "guides"}}
+
+	leaf := newTestPageWithFile("/docs/intro.md")
+
+	ps := newTestPathSpec()
+	ps.Cfg.Set("taxonomies", map[string]string{"tag": "tags", "category": "categories", "blogtag": "blogTags"})
+	ps.Cfg.Set("permalinks", map[string]string{
+		"params":     "/:params.product/:slug/",
+		"paramonly":  "/p/:params.product/",
+		"camelcase":  "/:blogTags/",
+		"terms":      "/:tags[0]/:tags[1:]/:categories/",
+		"content":    "/:contentdir/:contentbasename/",
+		"sections":   "/:sections[1:]/:tags[last]/",
+		"outofrange": "/:tags[5]/",
+	})
+
+	expander, err := NewPermalinkExpander(ps)
+	c.Assert(err, qt.IsNil)
+
+	expand := func(key string, p Page) string {
+		expanded, err := expander.Expand(key, p)
+		c.Assert(err, qt.IsNil)
+		return expanded
+	}
+
+	page.slug = "The Slug"
+	c.Assert(expand("params", page), qt.Equals, "/hugo-server/the-slug/")
+	c.Assert(expand("terms", page), qt.Equals, "/go-lang/hosting//")
+	c.Assert(expand("content", page), qt.Equals, "/docs/guides/deploy/")
+	c.Assert(expand("content", leaf), qt.Equals, "/docs/intro/")
+	c.Assert(expand("sections", page), qt.Equals, "/guides/hosting/")
+	c.Assert(expand("outofrange", page), qt.Equals, "//")
+	c.Assert(expand("camelcase", page), qt.Equals, "/release-notes/")
+
+	// Site params are not used for missing page params.
+	noProduct := testPageWithSiteParams{newTestPageWithFile("/docs/faq.md")}
+	v, err := noProduct.Param("product")
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.Equals, "Site Product")
+	c.Assert(expand("paramonly", noProduct), qt.Equals, "/p//")
+
+	ps.Cfg.Set("permalinks", map[string]string{"posts": "/:params/"})
+	_, err = NewPermalinkExpander(ps)
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestPermalinkExpansionKinds(t *testing.T) {
+	t.Parallel()
+
+	c := qt.New(t)
+
+	ps := newTestPathSpec()
+	ps.Cfg.Set("permalinks", map[string]interface{}{
+		"tags":       "/t/:slug/",
+		"categories": "/c/:slug/",
+		"taxonomy": map[string]interface{}{
+			"tags": "/tag/",
+		},
+		"term": map[string]interface{}{
+			"tags": "/tag/:slug/",
+		},
+	})
+
+	expander, err := NewPermalinkExpander(ps)
+	c.Assert(err, qt.IsNil)
+
+	newPage := func(kind, title string) *testPage {
+		p := newTestPage()
+		p.kind = kind
+		p.title = title
+		return p
+	}
+
+	expand := func(key string, p Page) string {
+		expanded, err := expander.Expand(key, p)
+		c.Assert(err, qt.IsNil)
+		return expanded
+	}
+
+	c.Assert(expand("tags", newPage(KindTaxonomy, "Tags")), qt.Equals, "/tag/")
+	c.Assert(expand("tags", newPage(KindTerm, "Go")), qt.Equals, "/tag/go/")
+	c.Assert(expand("tags", newPage(KindPage, "Go")), qt.Equals, "/t/go/")
+	c.Assert(expand("categories", newPage(KindTaxonomy, "Categories")), qt.Equals, "")
+	c.Assert(expand("categories", newPage(KindTerm, "News")), qt.Equals, "/c/news/")
+
+	ps.Cfg.Set("permalinks", map[string]interface{}{
+		"page": map[string]interface{}{
+			"posts": "/:slug/",
+		},
+	})
+	_, err = NewPermalinkExpander(ps)
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
 func TestPermalinkExpansionSliceSyntax(t *testing.T) {
 	t.Parallel()
 
@@ -226,3 +332,12 @@ func BenchmarkPermalinkExpand(b *testing.B) {
 
 	}
 }
+
+// testPageWithSiteParams is a page where Param falls back to site params.
+type testPageWithSiteParams struct {
+	*testPage
+}
+
+func (p testPageWithSiteParams) Param(key interface{}) (interface{}, error) {
+	return resource.Param(p, maps.Params{"product": "Site Product"}, key)
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import "strings"
+
+// ImageSet is a set of responsive image variants, ready to use in the
+// srcset and sizes attributes of an img element, or in a picture element.
+type ImageSet struct {
+	// All the images in the set, grouped by format and sorted by width.
+	Variants []ImageVariant
+
+	// One source per format except the fallback, for the source
+	// elements in a picture element.
+	Sources []ImageSource
+
+	// The images in the fallback (last) format, for the img element.
+	Fallback ImageSource
+
+	// The value of the sizes attribute.
+	Sizes string
+}
+
+// Srcset returns the srcset attribute value for the img element.
+func (s *ImageSet) Srcset() string {
+	return s.Fallback.Srcset
+}
+
+// Image returns the largest image in the fallback format, to use in
+// the src attribute of the img element.
+func (s *ImageSet) Image() Image {
+	return s.Fallback.Image()
+}
+
+// ImageSource is the set of images in one format.
+type ImageSource struct {
+	// The MIME type, e.g. "image/webp".
+	Type string
+
+	// The srcset attribute value, e.g. "/a_480.webp 480w, /a_800.webp 800w".
+	Srcset string
+
+	// The images in this format, sorted by width.
+	Variants []ImageVariant
+}
+
+// NewImageSource creates a new ImageSource from the given variants,
+// all in the image format with the MIME type typ.
+func NewImageSource(typ string, variants []ImageVariant) ImageSource {
+	srcset := make([]string, len(variants))
+	for i, v := range variants {
+		srcset[i] = v.RelPermalink() + " " + v.Descriptor
+	}
+	return ImageSource{
+		Type:     typ,
+		Srcset:   strings.Join(srcset, ", "),
+		Variants: variants,
+	}
+}
+
+// Image returns the largest image in s.
+func (s ImageSource) Image() Image {
+	if len(s.Variants) == 0 {
+		return nil
+	}
+	return s.Variants[len(s.Variants)-1].Image
+}
+
+// ImageVariant is a processed image in an ImageSet.
+type ImageVariant struct {
+	Image
+
+	// The srcset descriptor, e.g. "480w" or "2x".
+	Descriptor string
+}
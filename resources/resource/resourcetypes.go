@@ -67,8 +67,14 @@ type ImageOps interface {
 	Fit(spec string) (Image, error)
 	Resize(spec string) (Image, error)
 	Filter(filters ...interface{}) (Image, error)
+	ImageSet(options map[string]interface{}) (*ImageSet, error)
 	Exif() *exif.Exif
 
+	// Low-quality placeholders to show while the image loads.
+	BlurHash() (string, error)
+	Placeholder() (string, error)
+	Colors() ([]string, error)
+
 	// Internal
 	DecodeImage() (image.Image, error)
 }
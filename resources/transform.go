@@ -192,6 +192,10 @@ func (r *resourceAdapter) Filter(filters ...interface{}) (resource.Image, error)
 	return r.getImageOps().Filter(filters...)
 }
 
+func (r *resourceAdapter) ImageSet(options map[string]interface{}) (*resource.ImageSet, error) {
+	return r.getImageOps().ImageSet(options)
+}
+
 func (r *resourceAdapter) Height() int {
 	return r.getImageOps().Height()
 }
@@ -200,6 +204,18 @@ func (r *resourceAdapter) Exif() *exif.Exif {
 	return r.getImageOps().Exif()
 }
 
+func (r *resourceAdapter) BlurHash() (string, error) {
+	return r.getImageOps().BlurHash()
+}
+
+func (r *resourceAdapter) Placeholder() (string, error) {
+	return r.getImageOps().Placeholder()
+}
+
+func (r *resourceAdapter) Colors() ([]string, error) {
+	return r.getImageOps().Colors()
+}
+
 func (r *resourceAdapter) Key() string {
 	r.init(false, false)
 	return r.target.(resource.Identifier).Key()
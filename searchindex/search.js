@@ -0,0 +1,126 @@
+// Queries a search index built by Hugo's SearchIndex output format.
+//
+// Usage:
+//
+//   const index = await HugoSearch.load('/searchindex.json');
+//   const results = await index.search('hosting a site', 10);
+//   // [{ title, url, summary, score }, ...]
+//
+// Shards are fetched when first needed. The tokenizer and stemmer must match
+// the ones in github.com/gohugoio/hugo/searchindex.
+(function (global) {
+  'use strict';
+
+  // 32 bit FNV-1a of the UTF-8 bytes of s.
+  function fnv32a(s) {
+    let h = 0x811c9dc5;
+    for (const b of new TextEncoder().encode(s)) {
+      h ^= b;
+      h = Math.imul(h, 0x01000193) >>> 0;
+    }
+    return h >>> 0;
+  }
+
+  function hasAnySuffix(s, suffixes) {
+    return suffixes.some((suffix) => s.endsWith(suffix));
+  }
+
+  function undouble(w) {
+    const n = w.length;
+    if (n >= 2 && w[n - 1] === w[n - 2] && 'bdfgmnprt'.includes(w[n - 1])) {
+      return w.slice(0, n - 1);
+    }
+    return w;
+  }
+
+  function stemEnglish(w) {
+    const n = new TextEncoder().encode(w).length;
+    if (n <= 3) {
+      return w;
+    }
+    if (w.endsWith('ies') && n > 4) {
+      return w.slice(0, -3) + 'y';
+    }
+    if (w.endsWith('sses')) {
+      return w.slice(0, -2);
+    }
+    if (w.endsWith('ing') && n > 5) {
+      return undouble(w.slice(0, -3));
+    }
+    if (w.endsWith('ed') && n > 4) {
+      return undouble(w.slice(0, -2));
+    }
+    if (w.endsWith('ly') && n > 4) {
+      return w.slice(0, -2);
+    }
+    if (w.endsWith('es') && hasAnySuffix(w.slice(0, -2), ['s', 'x', 'z', 'ch', 'sh'])) {
+      return w.slice(0, -2);
+    }
+    if (w.endsWith('s') && !hasAnySuffix(w, ['ss', 'us', 'is'])) {
+      return w.slice(0, -1);
+    }
+    return w;
+  }
+
+  const stemmers = { en: stemEnglish };
+
+  function tokenize(s, stopWords, stem) {
+    return s
+      .toLowerCase()
+      .split(/[^\p{L}\p{N}]+/u)
+      .filter((w) => Array.from(w).length >= 2 && !stopWords.has(w))
+      .map(stem);
+  }
+
+  async function fetchJSON(url) {
+    const res = await fetch(url);
+    if (!res.ok) {
+      throw new Error(`failed to fetch ${url}: ${res.status}`);
+    }
+    return res.json();
+  }
+
+  async function load(url) {
+    const base = new URL(url, document.baseURI);
+    const manifest = await fetchJSON(base);
+    const stopWords = new Set(manifest.stopWords || []);
+    const stem = stemmers[manifest.stemmer] || ((w) => w);
+    const shards = new Map();
+
+    function shard(n) {
+      if (!shards.has(n)) {
+        shards.set(n, fetchJSON(new URL(`${manifest.shardPath}${n}.json`, base)));
+      }
+      return shards.get(n);
+    }
+
+    async function search(query, limit) {
+      const terms = Array.from(new Set(tokenize(query, stopWords, stem)));
+      const scores = new Map();
+      const hits = new Map();
+
+      await Promise.all(
+        terms.map(async (term) => {
+          const postings = (await shard(fnv32a(term) % manifest.shards))[term] || [];
+          for (const [doc, score] of postings) {
+            scores.set(doc, (scores.get(doc) || 0) + score);
+            hits.set(doc, (hits.get(doc) || 0) + 1);
+          }
+        })
+      );
+
+      // Documents matching more of the terms first.
+      return Array.from(scores.keys())
+        .sort((a, b) => hits.get(b) - hits.get(a) || scores.get(b) - scores.get(a))
+        .slice(0, limit || 10)
+        .map((doc) => {
+          const [title, url, summary] = manifest.docs[doc];
+          return { title, url, summary, score: scores.get(doc) };
+        });
+    }
+
+    return { lang: manifest.lang, search };
+  }
+
+  global.HugoSearch = { load };
+})(this);
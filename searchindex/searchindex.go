@@ -0,0 +1,297 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package searchindex builds full-text search indexes to be queried in the browser.
+package searchindex
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gohugoio/hugo/common/maps"
+	"github.com/gohugoio/hugo/common/text"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Version is the version of the index format, stored in the manifest.
+const Version = 1
+
+// Runtime is the JavaScript used to query the index in the browser.
+//
+//go:embed search.js
+var Runtime []byte
+
+// BM25 parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// DefaultConfig is the default search index config.
+var DefaultConfig = Config{
+	Weights: Weights{
+		Title:   10,
+		Summary: 4,
+		Tags:    6,
+		Content: 1,
+	},
+	ShardSize:     2000,
+	SummaryLength: 200,
+}
+
+/*
+Config configures the search index.
+
+An example site config.toml:
+
+	[searchIndex]
+	shardSize = 1000
+	stopWords = ["hugo"]
+	[searchIndex.weights]
+	title = 20
+	content = 1
+*/
+type Config struct {
+	// The weight of a match in each field.
+	Weights Weights
+
+	// The approximate number of terms in each shard of the index.
+	ShardSize int
+
+	// The maximum number of characters of the page summary to store in the
+	// index for display in search results. Set to 0 to store no summary.
+	SummaryLength int
+
+	// Replaces the built-in stop words for the language, if set.
+	StopWords []string
+}
+
+// Weights holds the weight of a match in each field of a document.
+type Weights struct {
+	Title   float64
+	Summary float64
+	Tags    float64
+	Content float64
+}
+
+// DecodeConfig creates a search index config from m, using DefaultConfig
+// for any missing values.
+func DecodeConfig(m maps.Params) (Config, error) {
+	c := DefaultConfig
+	if m == nil {
+		return c, nil
+	}
+
+	if err := mapstructure.WeakDecode(m, &c); err != nil {
+		return c, err
+	}
+
+	if c.ShardSize <= 0 {
+		return c, errors.New("searchIndex shardSize must be > 0")
+	}
+
+	w := c.Weights
+	if w.Title < 0 || w.Summary < 0 || w.Tags < 0 || w.Content < 0 {
+		return c, errors.New("searchIndex weights must be >= 0")
+	}
+
+	return c, nil
+}
+
+// Document is a document to index.
+type Document struct {
+	Title   string
+	URL     string
+	Summary string
+	Tags    []string
+	Content string
+}
+
+// Index is a full-text search index.
+type Index struct {
+	cfg       Config
+	lang      string
+	tokenizer *text.Tokenizer
+
+	docs [][3]string
+
+	// Term to the weighted term frequency in each document.
+	terms   map[string]map[int]float64
+	lengths []float64
+}
+
+// New creates a new search index for the given language code, e.g. "en".
+func New(cfg Config, lang string) *Index {
+	return &Index{
+		cfg:       cfg,
+		lang:      lang,
+		tokenizer: text.NewTokenizer(lang, cfg.StopWords),
+		terms:     make(map[string]map[int]float64),
+	}
+}
+
+// Add adds doc to the index.
+func (idx *Index) Add(doc Document) {
+	id := len(idx.docs)
+	idx.docs = append(idx.docs, [3]string{doc.Title, doc.URL, truncate(doc.Summary, idx.cfg.SummaryLength)})
+
+	var length float64
+	add := func(s string, weight float64) {
+		if weight == 0 {
+			return
+		}
+		for _, term := range idx.tokenizer.Tokens(s) {
+			tf, found := idx.terms[term]
+			if !found {
+				tf = make(map[int]float64)
+				idx.terms[term] = tf
+			}
+			tf[id] += weight
+			length += weight
+		}
+	}
+
+	w := idx.cfg.Weights
+	add(doc.Title, w.Title)
+	add(doc.Summary, w.Summary)
+	add(strings.Join(doc.Tags, " "), w.Tags)
+	add(doc.Content, w.Content)
+
+	idx.lengths = append(idx.lengths, length)
+}
+
+// manifest is the entry point of the index in the browser.
+type manifest struct {
+	Version int    `json:"version"`
+	Lang    string `json:"lang"`
+
+	// The stemmer and stop words used, to be applied to the query.
+	Stemmer   string   `json:"stemmer"`
+	StopWords []string `json:"stopWords"`
+
+	// The number of shards and their path relative to the manifest.
+	// A term is found in shard fnv32a(term) % shards.
+	Shards    int    `json:"shards"`
+	ShardPath string `json:"shardPath"`
+
+	// Title, URL and summary of the documents.
+	Docs [][3]string `json:"docs"`
+}
+
+// posting is a document number and its score for a term.
+type posting [2]float64
+
+// Encode encodes the index to a JSON manifest and a list of JSON shards,
+// to be published as shardPath + "<shard number>.json" relative to the
+// manifest.
+func (idx *Index) Encode(shardPath string) ([]byte, [][]byte, error) {
+	numShards := (len(idx.terms) + idx.cfg.ShardSize - 1) / idx.cfg.ShardSize
+	if numShards == 0 {
+		numShards = 1
+	}
+
+	var avgLength float64
+	for _, l := range idx.lengths {
+		avgLength += l
+	}
+	if len(idx.lengths) > 0 {
+		avgLength /= float64(len(idx.lengths))
+	}
+
+	shards := make([]map[string][]posting, numShards)
+	for i := range shards {
+		shards[i] = make(map[string][]posting)
+	}
+
+	n := float64(len(idx.docs))
+	for term, tfs := range idx.terms {
+		df := float64(len(tfs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		postings := make([]posting, 0, len(tfs))
+		for id, tf := range tfs {
+			norm := 1.0
+			if avgLength > 0 {
+				norm = 1 - b + b*idx.lengths[id]/avgLength
+			}
+			score := idf * tf * (k1 + 1) / (tf + k1*norm)
+			postings = append(postings, posting{float64(id), math.Round(score*1000) / 1000})
+		}
+		sort.Slice(postings, func(i, j int) bool {
+			if postings[i][1] != postings[j][1] {
+				return postings[i][1] > postings[j][1]
+			}
+			return postings[i][0] < postings[j][0]
+		})
+
+		shards[ShardFor(term, numShards)][term] = postings
+	}
+
+	m := manifest{
+		Version:   Version,
+		Lang:      idx.lang,
+		Stemmer:   idx.tokenizer.Stemmer(),
+		StopWords: idx.tokenizer.StopWords(),
+		Shards:    numShards,
+		ShardPath: shardPath,
+		Docs:      idx.docs,
+	}
+	if m.Docs == nil {
+		m.Docs = [][3]string{}
+	}
+
+	mb, err := json.Marshal(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encoded := make([][]byte, numShards)
+	for i, shard := range shards {
+		encoded[i], err = json.Marshal(shard)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return mb, encoded, nil
+}
+
+// ShardFor returns the shard holding term.
+func ShardFor(term string, numShards int) int {
+	h := fnv.New32a()
+	h.Write([]byte(term))
+	return int(h.Sum32() % uint32(numShards))
+}
+
+// truncate truncates s to at most max characters, on a word boundary if possible.
+func truncate(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	r := []rune(s)[:max]
+	s = string(r)
+	if i := strings.LastIndex(s, " "); i > 0 {
+		s = s[:i]
+	}
+	return s + "…"
+}
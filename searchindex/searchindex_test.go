@@ -0,0 +1,105 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searchindex
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/common/maps"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := DecodeConfig(nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg, qt.DeepEquals, DefaultConfig)
+
+	cfg, err = DecodeConfig(maps.Params{
+		"shardsize": 10,
+		"stopwords": []interface{}{"Hugo"},
+		"weights": maps.Params{
+			"title": 20,
+		},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.ShardSize, qt.Equals, 10)
+	c.Assert(cfg.StopWords, qt.DeepEquals, []string{"Hugo"})
+	c.Assert(cfg.Weights.Title, qt.Equals, 20.0)
+	c.Assert(cfg.Weights.Content, qt.Equals, 1.0)
+	c.Assert(cfg.SummaryLength, qt.Equals, 200)
+
+	_, err = DecodeConfig(maps.Params{"shardsize": 0})
+	c.Assert(err, qt.Not(qt.IsNil))
+	_, err = DecodeConfig(maps.Params{"weights": maps.Params{"tags": -1}})
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestIndex(t *testing.T) {
+	c := qt.New(t)
+
+	cfg := DefaultConfig
+	cfg.ShardSize = 3
+	cfg.SummaryLength = 20
+
+	idx := New(cfg, "en")
+	idx.Add(Document{Title: "Hosting", URL: "/hosting/", Summary: "How to host your site on a server.", Content: "Hosting is easy."})
+	idx.Add(Document{Title: "Themes", URL: "/themes/", Tags: []string{"hosting"}, Content: "Pick a theme for your site."})
+	idx.Add(Document{Title: "Templates", URL: "/templates/", Content: "Templates render pages."})
+
+	mb, shards, err := idx.Encode("searchindex/")
+	c.Assert(err, qt.IsNil)
+
+	var m manifest
+	c.Assert(json.Unmarshal(mb, &m), qt.IsNil)
+	c.Assert(m.Version, qt.Equals, Version)
+	c.Assert(m.Lang, qt.Equals, "en")
+	c.Assert(m.Stemmer, qt.Equals, "en")
+	c.Assert(m.ShardPath, qt.Equals, "searchindex/")
+	c.Assert(m.Docs, qt.DeepEquals, [][3]string{
+		{"Hosting", "/hosting/", "How to host your…"},
+		{"Themes", "/themes/", ""},
+		{"Templates", "/templates/", ""},
+	})
+	c.Assert(len(shards), qt.Equals, m.Shards)
+	c.Assert(m.Shards > 1, qt.IsTrue)
+
+	lookup := func(term string) []posting {
+		var shard map[string][]posting
+		c.Assert(json.Unmarshal(shards[ShardFor(term, m.Shards)], &shard), qt.IsNil)
+		return shard[term]
+	}
+
+	host := lookup("host")
+	c.Assert(host, qt.HasLen, 2)
+	c.Assert(host[0][0], qt.Equals, 0.0)
+	c.Assert(host[1][0], qt.Equals, 1.0)
+	c.Assert(host[0][1] > host[1][1], qt.IsTrue)
+	c.Assert(lookup("render"), qt.HasLen, 1)
+	c.Assert(lookup("the"), qt.HasLen, 0)
+
+	// Empty index.
+	mb, shards, err = New(DefaultConfig, "en").Encode("searchindex/")
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(mb), qt.Contains, `"shards":1,"shardPath":"searchindex/","docs":[]`)
+	c.Assert(shards, qt.HasLen, 1)
+	c.Assert(string(shards[0]), qt.Equals, "{}")
+}
+
+func TestRuntime(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(string(Runtime), qt.Contains, "HugoSearch")
+}
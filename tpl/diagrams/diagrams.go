@@ -15,15 +15,67 @@ package diagrams
 
 import (
 	"bytes"
+	"fmt"
 	"html/template"
 	"io"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/bep/goat"
 	"github.com/gohugoio/hugo/deps"
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/dot"
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/mermaid"
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/svg"
 	"github.com/spf13/cast"
 )
 
+// Engine renders diagrams in a given diagram language to SVG.
+type Engine interface {
+	// Render renders the diagram source in src.
+	Render(src string) (SVGDiagram, error)
+
+	// CanRender reports whether the diagram in src is supported by the engine.
+	CanRender(src string) bool
+}
+
+var (
+	enginesMu sync.RWMutex
+	engines   = make(map[string]Engine)
+)
+
+// RegisterEngine registers e as the diagram engine for the given name,
+// replacing any existing engine with that name.
+func RegisterEngine(name string, e Engine) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	engines[strings.ToLower(name)] = e
+}
+
+func getEngine(name string) (Engine, bool) {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	e, found := engines[strings.ToLower(name)]
+	return e, found
+}
+
+func engineNames() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	var names []string
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterEngine("goat", goatEngine{})
+	RegisterEngine("mermaid", svgEngine{render: mermaid.Render, canRender: mermaid.Supported})
+	RegisterEngine("dot", svgEngine{render: dot.Render})
+}
+
 type SVGDiagram interface {
 	// Wrapped returns the diagram as an SVG, including the <svg> container.
 	Wrapped() template.HTML
@@ -59,6 +111,57 @@ func (d goatDiagram) Height() int {
 	return d.d.Height
 }
 
+type goatEngine struct{}
+
+func (goatEngine) Render(src string) (SVGDiagram, error) {
+	return goatDiagram{d: goat.BuildSVG(strings.NewReader(src))}, nil
+}
+
+func (goatEngine) CanRender(src string) bool {
+	return true
+}
+
+type svgDiagram struct {
+	d svg.Diagram
+}
+
+func (d svgDiagram) Inner() template.HTML {
+	return template.HTML(d.d.Inner)
+}
+
+func (d svgDiagram) Wrapped() template.HTML {
+	return template.HTML(d.d.String())
+}
+
+func (d svgDiagram) Width() int {
+	return d.d.Width
+}
+
+func (d svgDiagram) Height() int {
+	return d.d.Height
+}
+
+// svgEngine is an Engine backed by one of the internal renderers.
+type svgEngine struct {
+	render    func(src string) (svg.Diagram, error)
+	canRender func(src string) bool
+}
+
+func (e svgEngine) Render(src string) (SVGDiagram, error) {
+	d, err := e.render(src)
+	if err != nil {
+		return nil, err
+	}
+	return svgDiagram{d: d}, nil
+}
+
+func (e svgEngine) CanRender(src string) bool {
+	if e.canRender == nil {
+		return true
+	}
+	return e.canRender(src)
+}
+
 type Diagrams struct {
 	d *deps.Deps
 }
@@ -79,3 +182,48 @@ func (d *Diagrams) Goat(v interface{}) SVGDiagram {
 		d: goat.BuildSVG(r),
 	}
 }
+
+// Mermaid renders the Mermaid diagram in v to SVG.
+// Flowcharts and sequence diagrams are supported.
+func (d *Diagrams) Mermaid(v interface{}) (SVGDiagram, error) {
+	return d.Render("mermaid", v)
+}
+
+// Dot renders the Graphviz DOT graph in v to SVG.
+func (d *Diagrams) Dot(v interface{}) (SVGDiagram, error) {
+	return d.Render("dot", v)
+}
+
+// Render renders the diagram in v to SVG using the named engine, e.g. goat, mermaid or dot.
+func (d *Diagrams) Render(engine string, v interface{}) (SVGDiagram, error) {
+	e, found := getEngine(engine)
+	if !found {
+		return nil, fmt.Errorf("diagrams: unknown engine %q, available engines are %s", engine, strings.Join(engineNames(), ", "))
+	}
+	src, err := toString(v)
+	if err != nil {
+		return nil, err
+	}
+	return e.Render(src)
+}
+
+// CanRender reports whether the named engine exists and supports the diagram in v.
+func (d *Diagrams) CanRender(engine string, v interface{}) bool {
+	e, found := getEngine(engine)
+	if !found {
+		return false
+	}
+	src, err := toString(v)
+	if err != nil {
+		return false
+	}
+	return e.CanRender(src)
+}
+
+func toString(v interface{}) (string, error) {
+	if r, ok := v.(io.Reader); ok {
+		b, err := io.ReadAll(r)
+		return string(b), err
+	}
+	return cast.ToStringE(v)
+}
@@ -0,0 +1,172 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagrams_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gohugoio/hugo/hugolib"
+)
+
+func TestDiagramCodeBlocks(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+-- layouts/_default/single.html --
+{{ .Content }}
+-- layouts/_default/_markup/render-codeblock-mermaid.html --
+{{ if diagrams.CanRender "mermaid" .Inner }}
+<div class="mermaid-diagram {{ .Attributes.class }}">{{ (diagrams.Render "mermaid" .Inner).Wrapped }}</div>
+{{ else }}
+<pre class="mermaid {{ .Attributes.class }}">{{ .Inner }}</pre>
+{{ end }}
+-- layouts/_default/_markup/render-codeblock-dot.html --
+<div class="dot-diagram {{ .Attributes.class }}">{{ (diagrams.Dot .Inner).Wrapped }}</div>
+-- content/p1.md --
+---
+title: "p1"
+---
+
+§§§mermaid
+graph LR
+  A[Start] -->|go| B{Done?}
+§§§
+
+§§§mermaid
+sequenceDiagram
+  Alice->>Bob: Hello Bob
+§§§
+
+§§§mermaid
+pie
+  "Dogs" : 386
+§§§
+
+§§§dot { class="big" }
+digraph { a -> b [label="edge"] }
+§§§
+`
+
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html",
+		"<div class=\"mermaid-diagram \"><svg",
+		">Start</text>",
+		">go</text>",
+		">Hello Bob</text>",
+		"<pre class=\"mermaid \">pie",
+		"<div class=\"dot-diagram big\"><svg",
+		">edge</text>",
+	)
+}
+
+// Without user provided render hooks, the built-in hooks render the
+// diagrams Hugo supports.
+func TestDiagramCodeBlocksDefaultHooks(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+-- layouts/_default/single.html --
+{{ .Content }}
+-- content/p1.md --
+---
+title: "p1"
+---
+
+§§§mermaid { class="big" width="300" }
+graph LR
+  A[Start] --> B
+§§§
+
+§§§mermaid
+pie
+  "Dogs" : 386
+§§§
+
+§§§dot
+digraph { a -> b [label="edge"] }
+§§§
+`
+
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/p1/index.html",
+		`<div class="mermaid-diagram svg-container big">`,
+		`width="300"`,
+		">Start</text>",
+		`<pre class="mermaid ">pie`,
+		`<div class="dot-diagram svg-container ">`,
+		">edge</text>",
+	)
+}
+
+func TestDiagramsFuncs(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+-- layouts/index.html --
+{{ $d := diagrams.Dot "digraph { a -> b }" }}
+Dot: {{ $d.Width }}x{{ $d.Height }}|{{ substr $d.Wrapped 0 4 }}|
+{{ $m := diagrams.Render "mermaid" "graph TD\nA-->B" }}
+Mermaid: {{ gt $m.Width 0 }}|
+CanRender: {{ diagrams.CanRender "mermaid" "pie" }}|{{ diagrams.CanRender "dot" "graph {}" }}|{{ diagrams.CanRender "foo" "" }}|
+`
+
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).Build()
+
+	b.AssertFileContent("public/index.html",
+		"|<svg|",
+		"Mermaid: true|",
+		"CanRender: false|true|false|",
+	)
+}
+
+func TestDiagramsRenderError(t *testing.T) {
+	t.Parallel()
+
+	files := `
+-- config.toml --
+-- layouts/index.html --
+{{ diagrams.Render "mermaid" "graph TD\nA-->" }}
+`
+
+	b, err := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{
+			T:           t,
+			TxtarString: files,
+		},
+	).BuildE()
+
+	b.Assert(err, qt.Not(qt.IsNil))
+	b.Assert(err.Error(), qt.Contains, "mermaid: line 2: expected node id")
+}
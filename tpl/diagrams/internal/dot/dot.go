@@ -0,0 +1,497 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dot renders graphs in the Graphviz DOT language as SVG.
+//
+// The full DOT grammar is supported, but only the most common attributes
+// are used: rankdir for graphs; label, shape, style, color, fillcolor and
+// fontcolor for nodes; label, style, color, dir, arrowhead and arrowtail for edges.
+// Subgraphs and clusters are parsed, but not drawn.
+package dot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/graph"
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/svg"
+)
+
+// Render parses the DOT graph in src and renders it as SVG.
+func Render(src string) (svg.Diagram, error) {
+	g, err := Parse(src)
+	if err != nil {
+		return svg.Diagram{}, err
+	}
+	return g.Render(), nil
+}
+
+// Parse parses the DOT graph in src.
+func Parse(src string) (*graph.Graph, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{
+		toks:  toks,
+		g:     graph.New(),
+		attrs: make(map[*graph.Node]map[string]string),
+	}
+	if err := p.parseGraph(); err != nil {
+		return nil, err
+	}
+	return p.g, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+
+	g        *graph.Graph
+	directed bool
+
+	// The explicitly set attributes of each node.
+	attrs map[*graph.Node]map[string]string
+}
+
+// scope holds the default attributes and the nodes of a (sub)graph.
+type scope struct {
+	node  map[string]string
+	edge  map[string]string
+	nodes []*graph.Node
+	seen  map[*graph.Node]bool
+}
+
+func newScope(parent *scope) *scope {
+	s := &scope{
+		node: make(map[string]string),
+		edge: make(map[string]string),
+		seen: make(map[*graph.Node]bool),
+	}
+	if parent != nil {
+		for k, v := range parent.node {
+			s.node[k] = v
+		}
+		for k, v := range parent.edge {
+			s.edge[k] = v
+		}
+	}
+	return s
+}
+
+func (s *scope) add(n *graph.Node) {
+	if !s.seen[n] {
+		s.seen[n] = true
+		s.nodes = append(s.nodes, n)
+	}
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.errorf(t, "expected %s, got %s", what, t)
+	}
+	return t, nil
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return fmt.Errorf("dot: line %d: %s", t.line, fmt.Sprintf(format, args...))
+}
+
+// isKeyword reports whether t is the given (case insensitive) keyword.
+func isKeyword(t token, kw string) bool {
+	return t.kind == tID && !t.quoted && strings.EqualFold(t.val, kw)
+}
+
+func (p *parser) parseGraph() error {
+	if isKeyword(p.peek(), "strict") {
+		p.next()
+	}
+	t := p.next()
+	switch {
+	case isKeyword(t, "digraph"):
+		p.directed = true
+	case isKeyword(t, "graph"):
+	default:
+		return p.errorf(t, "expected graph or digraph, got %s", t)
+	}
+	if p.peek().kind == tID {
+		// The graph name.
+		p.next()
+	}
+	if _, err := p.expect(tLBrace, "{"); err != nil {
+		return err
+	}
+	if _, err := p.parseStmts(newScope(nil)); err != nil {
+		return err
+	}
+	if t := p.peek(); t.kind != tEOF {
+		return p.errorf(t, "unexpected %s after the graph", t)
+	}
+	return nil
+}
+
+// parseStmts parses statements until the closing brace.
+func (p *parser) parseStmts(s *scope) (*scope, error) {
+	for {
+		t := p.peek()
+		switch t.kind {
+		case tEOF:
+			return nil, p.errorf(t, "missing }")
+		case tRBrace:
+			p.next()
+			return s, nil
+		case tSemi, tComma:
+			p.next()
+			continue
+		}
+		if err := p.parseStmt(s); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseStmt(s *scope) error {
+	t := p.peek()
+
+	if t.kind == tID && !t.quoted {
+		switch strings.ToLower(t.val) {
+		case "graph", "node", "edge":
+			p.next()
+			attrs, err := p.parseAttrLists()
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(t.val) {
+			case "graph":
+				p.setGraphAttrs(attrs)
+			case "node":
+				for k, v := range attrs {
+					s.node[k] = v
+				}
+			case "edge":
+				for k, v := range attrs {
+					s.edge[k] = v
+				}
+			}
+			return nil
+		}
+	}
+
+	if t.kind == tID && p.toks[p.pos+1].kind == tEq {
+		// A graph attribute, e.g. rankdir=LR.
+		p.next()
+		p.next()
+		v, err := p.expect(tID, "attribute value")
+		if err != nil {
+			return err
+		}
+		p.setGraphAttrs(map[string]string{strings.ToLower(t.val): v.val})
+		return nil
+	}
+
+	operands := [][]*graph.Node{}
+	first, single, err := p.parseOperand(s)
+	if err != nil {
+		return err
+	}
+	operands = append(operands, first)
+
+	for p.peek().kind == tEdgeOp {
+		op := p.next()
+		if (op.val == "->") != p.directed {
+			return p.errorf(op, "edge operator %s not allowed in this graph", op.val)
+		}
+		nodes, _, err := p.parseOperand(s)
+		if err != nil {
+			return err
+		}
+		operands = append(operands, nodes)
+	}
+
+	attrs, err := p.parseAttrLists()
+	if err != nil {
+		return err
+	}
+
+	if len(operands) == 1 {
+		if single != nil {
+			p.setNodeAttrs(single, attrs)
+		}
+		return nil
+	}
+
+	edgeAttrs := make(map[string]string)
+	for k, v := range s.edge {
+		edgeAttrs[k] = v
+	}
+	for k, v := range attrs {
+		edgeAttrs[k] = v
+	}
+
+	for i := 1; i < len(operands); i++ {
+		for _, from := range operands[i-1] {
+			for _, to := range operands[i] {
+				p.addEdge(from, to, edgeAttrs)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseOperand parses a node id or a subgraph and returns its nodes.
+// If the operand is a node id, that node is also returned as single.
+func (p *parser) parseOperand(s *scope) (nodes []*graph.Node, single *graph.Node, err error) {
+	t := p.peek()
+
+	if isKeyword(t, "subgraph") || t.kind == tLBrace {
+		if t.kind != tLBrace {
+			p.next()
+			if p.peek().kind == tID {
+				p.next()
+			}
+			if _, err := p.expect(tLBrace, "{"); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			p.next()
+		}
+		sub, err := p.parseStmts(newScope(s))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, n := range sub.nodes {
+			s.add(n)
+		}
+		return sub.nodes, nil, nil
+	}
+
+	id, err := p.expect(tID, "node id")
+	if err != nil {
+		return nil, nil, err
+	}
+	// Ports are parsed, but not used.
+	for i := 0; i < 2 && p.peek().kind == tColon; i++ {
+		p.next()
+		if _, err := p.expect(tID, "port"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	n := p.g.Lookup(id.val)
+	if n == nil {
+		n = p.g.Node(id.val)
+		p.attrs[n] = make(map[string]string)
+		p.setNodeAttrs(n, s.node)
+	}
+	s.add(n)
+
+	return []*graph.Node{n}, n, nil
+}
+
+// parseAttrLists parses zero or more attribute lists, e.g. [a=b, c=d][e=f].
+func (p *parser) parseAttrLists() (map[string]string, error) {
+	attrs := make(map[string]string)
+	for p.peek().kind == tLBracket {
+		p.next()
+		for {
+			t := p.next()
+			switch t.kind {
+			case tRBracket:
+			case tSemi, tComma:
+				continue
+			case tID:
+				if _, err := p.expect(tEq, "="); err != nil {
+					return nil, err
+				}
+				v, err := p.expect(tID, "attribute value")
+				if err != nil {
+					return nil, err
+				}
+				attrs[strings.ToLower(t.val)] = v.val
+				continue
+			default:
+				return nil, p.errorf(t, "unexpected %s in attribute list", t)
+			}
+			break
+		}
+	}
+	return attrs, nil
+}
+
+func (p *parser) setGraphAttrs(attrs map[string]string) {
+	if v, ok := attrs["rankdir"]; ok {
+		switch strings.ToUpper(v) {
+		case "LR":
+			p.g.Direction = graph.LeftRight
+		case "RL":
+			p.g.Direction = graph.RightLeft
+		case "BT":
+			p.g.Direction = graph.BottomUp
+		default:
+			p.g.Direction = graph.TopDown
+		}
+	}
+}
+
+func (p *parser) setNodeAttrs(n *graph.Node, attrs map[string]string) {
+	for k, v := range attrs {
+		p.attrs[n][k] = v
+	}
+	a := p.attrs[n]
+
+	n.Label = n.ID
+	if v, ok := a["label"]; ok {
+		n.Label = label(v, n.ID)
+	}
+
+	n.Shape = graph.Ellipse
+	switch strings.ToLower(a["shape"]) {
+	case "box", "rect", "rectangle", "square", "component", "note", "tab", "folder", "box3d":
+		n.Shape = graph.Rect
+	case "circle":
+		n.Shape = graph.Circle
+	case "doublecircle":
+		n.Shape = graph.DoubleCircle
+	case "diamond":
+		n.Shape = graph.Diamond
+	case "hexagon", "octagon":
+		n.Shape = graph.Hexagon
+	case "parallelogram":
+		n.Shape = graph.Parallelogram
+	case "cylinder":
+		n.Shape = graph.Cylinder
+	case "plaintext", "plain", "none":
+		n.Shape = graph.Plain
+	case "point":
+		n.Shape = graph.Point
+	case "cds", "rarrow", "larrow":
+		n.Shape = graph.Flag
+	}
+
+	n.Style = graph.Solid
+	n.Fill = ""
+	for _, style := range strings.Split(a["style"], ",") {
+		switch strings.TrimSpace(strings.ToLower(style)) {
+		case "rounded":
+			if n.Shape == graph.Rect {
+				n.Shape = graph.RoundedRect
+			}
+		case "dashed":
+			n.Style = graph.Dashed
+		case "dotted":
+			n.Style = graph.Dotted
+		case "bold":
+			n.Style = graph.Thick
+		case "invis":
+			n.Style = graph.Invisible
+		case "filled":
+			n.Fill = "lightgrey"
+			if c := a["color"]; c != "" {
+				n.Fill = c
+			}
+		}
+	}
+	if v := a["fillcolor"]; v != "" {
+		n.Fill = v
+	}
+	n.Stroke = a["color"]
+	n.FontColor = a["fontcolor"]
+}
+
+func (p *parser) addEdge(from, to *graph.Node, attrs map[string]string) {
+	e := p.g.AddEdge(from, to)
+
+	if v, ok := attrs["label"]; ok {
+		e.Label = label(v, "")
+	}
+	e.Color = attrs["color"]
+
+	switch strings.ToLower(attrs["style"]) {
+	case "dashed":
+		e.Style = graph.Dashed
+	case "dotted":
+		e.Style = graph.Dotted
+	case "bold":
+		e.Style = graph.Thick
+	case "invis":
+		e.Style = graph.Invisible
+	}
+
+	dir := "forward"
+	if !p.directed {
+		dir = "none"
+	}
+	if v := attrs["dir"]; v != "" {
+		dir = strings.ToLower(v)
+	}
+	e.Head, e.Tail = graph.ArrowNone, graph.ArrowNone
+	if dir == "forward" || dir == "both" {
+		e.Head = arrow(attrs["arrowhead"])
+	}
+	if dir == "back" || dir == "both" {
+		e.Tail = arrow(attrs["arrowtail"])
+	}
+}
+
+func arrow(s string) graph.Arrow {
+	switch strings.ToLower(s) {
+	case "none":
+		return graph.ArrowNone
+	case "vee", "open":
+		return graph.ArrowOpen
+	case "dot", "odot":
+		return graph.ArrowCircle
+	case "tee":
+		return graph.ArrowCross
+	}
+	return graph.ArrowNormal
+}
+
+// label resolves the escape sequences in a DOT label.
+func label(s, nodeID string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'l', 'r':
+			b.WriteByte('\n')
+		case 'N':
+			b.WriteString(nodeID)
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
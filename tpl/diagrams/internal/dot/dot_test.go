@@ -0,0 +1,153 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dot
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/graph"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestParse(t *testing.T) {
+	c := qt.New(t)
+
+	g, err := Parse(`
+// A comment.
+digraph G {
+	rankdir=LR;
+	node [shape=box, style=rounded];
+	start [label="Start\nhere", shape=ellipse];
+	start -> parse -> check;
+	check -> parse [label="no", style=dashed];
+	check -> { a b } [color=red];
+	d [shape=diamond label=<Is <b>it</b><br/>ok?>];
+	/* Another comment. */
+	e [style="filled,dashed", fillcolor="#eee"];
+}
+`)
+	c.Assert(err, qt.IsNil)
+	c.Assert(g.Direction, qt.Equals, graph.LeftRight)
+
+	var ids []string
+	for _, n := range g.Nodes() {
+		ids = append(ids, n.ID)
+	}
+	c.Assert(ids, qt.DeepEquals, []string{"start", "parse", "check", "a", "b", "d", "e"})
+
+	start := g.Lookup("start")
+	c.Assert(start.Label, qt.Equals, "Start\nhere")
+	c.Assert(start.Shape, qt.Equals, graph.Ellipse)
+	c.Assert(g.Lookup("parse").Shape, qt.Equals, graph.RoundedRect)
+	c.Assert(g.Lookup("d").Shape, qt.Equals, graph.Diamond)
+	c.Assert(g.Lookup("d").Label, qt.Equals, "Is it\nok?")
+	c.Assert(g.Lookup("e").Fill, qt.Equals, "#eee")
+	c.Assert(g.Lookup("e").Style, qt.Equals, graph.Dashed)
+
+	edges := g.Edges()
+	c.Assert(edges, qt.HasLen, 5)
+	c.Assert(edges[2].Label, qt.Equals, "no")
+	c.Assert(edges[2].Style, qt.Equals, graph.Dashed)
+	c.Assert(edges[3].To.ID, qt.Equals, "a")
+	c.Assert(edges[3].Color, qt.Equals, "red")
+	c.Assert(edges[4].To.ID, qt.Equals, "b")
+}
+
+func TestParseUndirected(t *testing.T) {
+	c := qt.New(t)
+
+	g, err := Parse(`graph { a -- b -- c }`)
+	c.Assert(err, qt.IsNil)
+	c.Assert(g.Edges(), qt.HasLen, 2)
+	c.Assert(g.Edges()[0].Head, qt.Equals, graph.ArrowNone)
+}
+
+func TestParseErrors(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		src    string
+		expect string
+	}{
+		{`digraph { a -> }`, "dot: line 1: expected node id"},
+		{`graph { a -> b }`, "edge operator -> not allowed"},
+		{"digraph {\n a -> b\n", "dot: line 3: missing }"},
+		{`digraph { a [label="foo }`, "unterminated string"},
+		{`flowchart { a }`, "expected graph or digraph"},
+	} {
+		_, err := Parse(test.src)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(test.src))
+		c.Assert(err.Error(), qt.Contains, test.expect)
+	}
+}
+
+func TestRender(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := Render(`digraph { a -> b [label="go"]; b -> a; a -> a }`)
+	c.Assert(err, qt.IsNil)
+	c.Assert(d.Width > 0 && d.Height > 0, qt.IsTrue)
+	s := d.String()
+	c.Assert(strings.HasPrefix(s, "<svg"), qt.IsTrue)
+	c.Assert(s, qt.Contains, ">go</text>")
+	c.Assert(strings.Count(s, "class=\"edge\""), qt.Equals, 3)
+}
+
+// TestRenderGolden compares the rendered SVG of the diagrams in testdata with
+// the golden files next to them.
+func TestRenderGolden(t *testing.T) {
+	c := qt.New(t)
+
+	// Set to true to write the golden files, then check them visually.
+	devMode := false
+
+	files, err := filepath.Glob(filepath.Join("testdata", "*.dot"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(files, qt.Not(qt.HasLen), 0)
+
+	for _, filename := range files {
+		src, err := ioutil.ReadFile(filename)
+		c.Assert(err, qt.IsNil)
+
+		d, err := Render(string(src))
+		c.Assert(err, qt.IsNil, qt.Commentf(filename))
+		got := d.String()
+
+		// The SVG must be well formed.
+		dec := xml.NewDecoder(strings.NewReader(got))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			c.Assert(err, qt.IsNil, qt.Commentf(filename))
+		}
+
+		goldenFilename := strings.TrimSuffix(filename, ".dot") + ".svg"
+		if devMode {
+			c.Assert(ioutil.WriteFile(goldenFilename, []byte(got), 0644), qt.IsNil)
+			continue
+		}
+
+		golden, err := ioutil.ReadFile(goldenFilename)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.Equals, string(golden), qt.Commentf(filename))
+	}
+}
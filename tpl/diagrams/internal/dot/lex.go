@@ -0,0 +1,210 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tEOF tokenKind = iota
+	tID
+	tLBrace
+	tRBrace
+	tLBracket
+	tRBracket
+	tEq
+	tSemi
+	tComma
+	tColon
+	tEdgeOp
+)
+
+type token struct {
+	kind   tokenKind
+	val    string
+	quoted bool
+	line   int
+}
+
+func (t token) String() string {
+	if t.kind == tEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+var punctuation = map[byte]tokenKind{
+	'{': tLBrace,
+	'}': tRBrace,
+	'[': tLBracket,
+	']': tRBracket,
+	'=': tEq,
+	';': tSemi,
+	',': tComma,
+	':': tColon,
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	atLineStart := true
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		if c == '\n' {
+			line++
+			i++
+			atLineStart = true
+			continue
+		}
+		if c == ' ' || c == '\t' || c == '\r' {
+			i++
+			continue
+		}
+
+		if c == '#' && atLineStart {
+			// Preprocessor output line.
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+		}
+		atLineStart = false
+
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("dot: line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case strings.HasPrefix(src[i:], "->") || strings.HasPrefix(src[i:], "--"):
+			toks = append(toks, token{kind: tEdgeOp, val: src[i : i+2], line: line})
+			i += 2
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					switch src[j+1] {
+					case '"':
+						b.WriteByte('"')
+						j++
+						continue
+					case '\n':
+						// Line continuation.
+						line++
+						j++
+						continue
+					}
+				}
+				if src[j] == '\n' {
+					line++
+				}
+				b.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("dot: line %d: unterminated string", line)
+			}
+			toks = append(toks, token{kind: tID, val: b.String(), quoted: true, line: line})
+			i = j + 1
+		case c == '<':
+			depth := 0
+			j := i
+			for ; j < len(src); j++ {
+				if src[j] == '<' {
+					depth++
+				} else if src[j] == '>' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				} else if src[j] == '\n' {
+					line++
+				}
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("dot: line %d: unterminated HTML string", line)
+			}
+			toks = append(toks, token{kind: tID, val: htmlToText(src[i+1 : j]), quoted: true, line: line})
+			i = j + 1
+		default:
+			if kind, ok := punctuation[c]; ok {
+				toks = append(toks, token{kind: kind, val: string(c), line: line})
+				i++
+				continue
+			}
+			j := i
+			if isNumeralStart(src[i:]) {
+				for j < len(src) && (src[j] == '-' && j == i || src[j] == '.' || (src[j] >= '0' && src[j] <= '9')) {
+					j++
+				}
+			} else {
+				for j < len(src) {
+					r, w := utf8.DecodeRuneInString(src[j:])
+					if !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || r >= 0x80) {
+						break
+					}
+					j += w
+				}
+			}
+			if j == i {
+				return nil, fmt.Errorf("dot: line %d: unexpected character %q", line, src[i:i+1])
+			}
+			toks = append(toks, token{kind: tID, val: src[i:j], line: line})
+			i = j
+		}
+	}
+
+	return append(toks, token{kind: tEOF, line: line}), nil
+}
+
+func isNumeralStart(s string) bool {
+	if s[0] == '-' {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
+	}
+	if s[0] == '.' {
+		return len(s) > 1 && s[1] >= '0' && s[1] <= '9'
+	}
+	return s[0] >= '0' && s[0] <= '9'
+}
+
+var (
+	htmlBreakRe        = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlTagRe          = regexp.MustCompile(`<[^>]*>`)
+	htmlEntityReplacer = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&amp;", "&", "&nbsp;", " ")
+)
+
+// htmlToText converts a DOT HTML label to plain text.
+func htmlToText(s string) string {
+	s = htmlBreakRe.ReplaceAllString(s, `\n`)
+	s = htmlTagRe.ReplaceAllString(s, "")
+	return strings.TrimSpace(htmlEntityReplacer.Replace(s))
+}
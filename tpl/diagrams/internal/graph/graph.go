@@ -0,0 +1,164 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graph lays out and renders directed graphs, e.g. flowcharts, as SVG.
+//
+// The layout is a simplified layered (Sugiyama style) layout: cycles are broken,
+// nodes are assigned to ranks, edges spanning more than one rank are split with
+// dummy nodes, the nodes within each rank are ordered to reduce crossings and
+// are finally given coordinates.
+package graph
+
+import (
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/svg"
+)
+
+// Direction is the direction of the graph, i.e. the direction of the ranks.
+type Direction int
+
+const (
+	TopDown Direction = iota
+	BottomUp
+	LeftRight
+	RightLeft
+)
+
+// Shape is the shape of a node.
+type Shape int
+
+const (
+	Rect Shape = iota
+	RoundedRect
+	Stadium
+	Circle
+	DoubleCircle
+	Diamond
+	Hexagon
+	Ellipse
+	Flag
+	Cylinder
+	Parallelogram
+	Plain
+	Point
+)
+
+// LineStyle is the line style of an edge or a node border.
+type LineStyle int
+
+const (
+	Solid LineStyle = iota
+	Dashed
+	Dotted
+	Thick
+	Invisible
+)
+
+// Arrow is the arrow type at an end of an edge.
+type Arrow int
+
+const (
+	ArrowNone Arrow = iota
+	ArrowNormal
+	ArrowOpen
+	ArrowCross
+	ArrowCircle
+)
+
+// Node is a node in the graph.
+type Node struct {
+	ID    string
+	Label string
+	Shape Shape
+
+	// Colors. Empty means the default.
+	Fill      string
+	Stroke    string
+	FontColor string
+
+	Style LineStyle
+
+	index int
+
+	// Layout.
+	w, h  float64
+	x, y  float64
+	rank  int
+	order int
+	dummy bool
+}
+
+// Edge is an edge in the graph.
+type Edge struct {
+	From  *Node
+	To    *Node
+	Label string
+	Style LineStyle
+	Color string
+
+	// The arrow heads at the From (Tail) and To (Head) ends.
+	Head Arrow
+	Tail Arrow
+
+	// Layout.
+	reversed bool
+	dummies  []*Node
+	points   []svg.Point
+}
+
+// Graph is a graph.
+type Graph struct {
+	Direction Direction
+
+	nodes []*Node
+	byID  map[string]*Node
+	edges []*Edge
+}
+
+// New creates a new, empty graph.
+func New() *Graph {
+	return &Graph{byID: make(map[string]*Node)}
+}
+
+// Node returns the node with the given id, creating it if not found.
+// A new node's label is its id.
+func (g *Graph) Node(id string) *Node {
+	if n, found := g.byID[id]; found {
+		return n
+	}
+	n := &Node{ID: id, Label: id, index: len(g.nodes)}
+	g.nodes = append(g.nodes, n)
+	g.byID[id] = n
+	return n
+}
+
+// Lookup returns the node with the given id, or nil if not found.
+func (g *Graph) Lookup(id string) *Node {
+	return g.byID[id]
+}
+
+// Nodes returns the nodes in the order they were created.
+func (g *Graph) Nodes() []*Node {
+	return g.nodes
+}
+
+// Edges returns the edges in the order they were added.
+func (g *Graph) Edges() []*Edge {
+	return g.edges
+}
+
+// AddEdge adds an edge with an arrow head from from to to.
+func (g *Graph) AddEdge(from, to *Node) *Edge {
+	e := &Edge{From: from, To: to, Head: ArrowNormal}
+	g.edges = append(g.edges, e)
+	return e
+}
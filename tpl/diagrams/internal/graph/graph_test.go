@@ -0,0 +1,210 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/svg"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestLayout(t *testing.T) {
+	c := qt.New(t)
+
+	g := New()
+	a, b, cc, d := g.Node("a"), g.Node("b"), g.Node("c"), g.Node("d")
+	g.AddEdge(a, b)
+	g.AddEdge(a, cc)
+	g.AddEdge(b, d)
+	g.AddEdge(cc, d)
+	g.AddEdge(a, d)
+	// A cycle.
+	g.AddEdge(d, a)
+
+	g.layout()
+
+	c.Assert(a.rank, qt.Equals, 0)
+	c.Assert(b.rank, qt.Equals, 1)
+	c.Assert(cc.rank, qt.Equals, 1)
+	c.Assert(d.rank, qt.Equals, 2)
+
+	c.Assert(a.y < b.y, qt.IsTrue)
+	c.Assert(b.y < d.y, qt.IsTrue)
+	c.Assert(b.y, qt.Equals, cc.y)
+
+	// Nodes in the same rank must not overlap.
+	left, right := b, cc
+	if left.x > right.x {
+		left, right = right, left
+	}
+	c.Assert(right.x-left.x >= (left.w+right.w)/2, qt.IsTrue)
+
+	for _, e := range g.Edges() {
+		c.Assert(len(e.points) >= 2, qt.IsTrue)
+	}
+	// The a -> d edge spans two ranks.
+	c.Assert(g.Edges()[4].dummies, qt.HasLen, 1)
+}
+
+func TestLayoutDirection(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		dir    Direction
+		expect func(a, b *Node) bool
+	}{
+		{TopDown, func(a, b *Node) bool { return a.y < b.y }},
+		{BottomUp, func(a, b *Node) bool { return a.y > b.y }},
+		{LeftRight, func(a, b *Node) bool { return a.x < b.x }},
+		{RightLeft, func(a, b *Node) bool { return a.x > b.x }},
+	} {
+		g := New()
+		g.Direction = test.dir
+		a, b := g.Node("a"), g.Node("b")
+		g.AddEdge(a, b)
+		g.layout()
+		c.Assert(test.expect(a, b), qt.IsTrue, qt.Commentf("direction %d", test.dir))
+	}
+}
+
+func TestRender(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(New().Render().Inner, qt.Equals, "")
+
+	g := New()
+	a := g.Node("a")
+	a.Label = "A <&>"
+	a.Shape = Diamond
+	e := g.AddEdge(a, g.Node("b"))
+	e.Label = "label"
+	d := g.Render()
+
+	c.Assert(d.Width > 0 && d.Height > 0, qt.IsTrue)
+	c.Assert(d.Inner, qt.Contains, "<polygon")
+	c.Assert(d.Inner, qt.Contains, ">A &lt;&amp;&gt;</text>")
+	c.Assert(d.Inner, qt.Contains, ">label</text>")
+}
+
+// TestLayoutInvariants checks the geometry of the layout of some typical
+// graphs in all directions.
+func TestLayoutInvariants(t *testing.T) {
+	c := qt.New(t)
+
+	graphs := map[string][][2]string{
+		"chain":    {{"a", "b"}, {"b", "c"}, {"c", "d"}},
+		"diamond":  {{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}},
+		"fan-out":  {{"a", "b"}, {"a", "c"}, {"a", "d"}, {"a", "e"}, {"a", "f"}},
+		"long":     {{"a", "b"}, {"b", "c"}, {"c", "d"}, {"a", "d"}, {"b", "d"}},
+		"cycle":    {{"a", "b"}, {"b", "c"}, {"c", "a"}, {"c", "d"}},
+		"self":     {{"a", "a"}, {"a", "b"}},
+		"parallel": {{"a", "b"}, {"a", "b"}, {"b", "a"}},
+		"crossing": {{"a", "c"}, {"a", "d"}, {"b", "c"}, {"b", "d"}, {"c", "e"}, {"d", "f"}},
+	}
+
+	shapes := []Shape{Rect, RoundedRect, Stadium, Circle, Diamond, Hexagon, Ellipse, Cylinder}
+
+	const eps = 0.01
+
+	for name, edges := range graphs {
+		for _, dir := range []Direction{TopDown, BottomUp, LeftRight, RightLeft} {
+			g := New()
+			g.Direction = dir
+			for i, e := range edges {
+				from, to := g.Node(e[0]), g.Node(e[1])
+				from.Shape = shapes[from.index%len(shapes)]
+				to.Shape = shapes[to.index%len(shapes)]
+				if i%2 == 0 {
+					from.Label = "A longer label for " + from.ID
+				}
+				g.AddEdge(from, to)
+			}
+
+			d := g.Render()
+			comment := qt.Commentf("%s, direction %d", name, dir)
+
+			nodes := g.Nodes()
+			for i, a := range nodes {
+				// The label fits in the node.
+				c.Assert(a.w >= svg.TextWidth(a.Label), qt.IsTrue, comment)
+				c.Assert(a.h >= svg.TextHeight(a.Label), qt.IsTrue, comment)
+
+				// Nodes do not overlap.
+				for _, b := range nodes[i+1:] {
+					overlapX := math.Abs(a.x-b.x) < (a.w+b.w)/2-eps
+					overlapY := math.Abs(a.y-b.y) < (a.h+b.h)/2-eps
+					c.Assert(overlapX && overlapY, qt.IsFalse, qt.Commentf("%s and %s overlap in %s, direction %d", a.ID, b.ID, name, dir))
+				}
+			}
+
+			minX, minY := math.MaxFloat64, math.MaxFloat64
+			maxX, maxY := -math.MaxFloat64, -math.MaxFloat64
+			for _, n := range nodes {
+				minX, minY = math.Min(minX, n.x-n.w/2), math.Min(minY, n.y-n.h/2)
+				maxX, maxY = math.Max(maxX, n.x+n.w/2), math.Max(maxY, n.y+n.h/2)
+			}
+			// The diagram is large enough for all nodes.
+			c.Assert(float64(d.Width) >= maxX-minX+2*margin, qt.IsTrue, comment)
+			c.Assert(float64(d.Height) >= maxY-minY+2*margin, qt.IsTrue, comment)
+
+			for _, e := range g.Edges() {
+				c.Assert(len(e.points) >= 2, qt.IsTrue, comment)
+				if e.From == e.To {
+					continue
+				}
+				first, last := e.points[0], e.points[len(e.points)-1]
+
+				// The edge starts and ends on the borders of its nodes.
+				c.Assert(onBorder(e.From, first, eps), qt.IsTrue, qt.Commentf("%s -> %s start %v in %s, direction %d", e.From.ID, e.To.ID, first, name, dir))
+				c.Assert(onBorder(e.To, last, eps), qt.IsTrue, qt.Commentf("%s -> %s end %v in %s, direction %d", e.From.ID, e.To.ID, last, name, dir))
+
+				// Edges not broken to remove cycles point in the direction of the graph.
+				if !e.reversed {
+					var along float64
+					switch dir {
+					case TopDown:
+						along = e.To.y - e.From.y
+					case BottomUp:
+						along = e.From.y - e.To.y
+					case LeftRight:
+						along = e.To.x - e.From.x
+					case RightLeft:
+						along = e.From.x - e.To.x
+					}
+					c.Assert(along > 0, qt.IsTrue, qt.Commentf("%s -> %s in %s, direction %d", e.From.ID, e.To.ID, name, dir))
+				}
+			}
+		}
+	}
+}
+
+// onBorder reports whether p is on the border of n's shape, i.e. inside its
+// bounding box but not strictly inside a slightly smaller box.
+func onBorder(n *Node, p svg.Point, eps float64) bool {
+	dx, dy := math.Abs(p.X-n.x), math.Abs(p.Y-n.y)
+	hw, hh := n.w/2, n.h/2
+	if dx > hw+eps || dy > hh+eps {
+		return false
+	}
+	switch n.Shape {
+	case Circle, DoubleCircle, Ellipse, Point:
+		return math.Abs((dx*dx)/(hw*hw)+(dy*dy)/(hh*hh)-1) < eps
+	case Diamond:
+		return math.Abs(dx/hw+dy/hh-1) < eps
+	}
+	return math.Abs(dx-hw) < eps || math.Abs(dy-hh) < eps
+}
@@ -0,0 +1,603 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/svg"
+)
+
+const (
+	paddingX   = 15.0
+	paddingY   = 10.0
+	nodeSep    = 30.0
+	rankSep    = 45.0
+	dummySize  = 10.0
+	orderIters = 12
+	coordIters = 8
+)
+
+// layoutEdge is an edge between two nodes in adjacent ranks.
+type layoutEdge struct {
+	from, to *Node
+}
+
+type layout struct {
+	g *Graph
+
+	ranks [][]*Node
+	// Edges between adjacent ranks, including the dummy nodes.
+	preds map[*Node][]*Node
+	succs map[*Node][]*Node
+}
+
+// horizontal reports whether the ranks are laid out from left to right (or the reverse).
+func (g *Graph) horizontal() bool {
+	return g.Direction == LeftRight || g.Direction == RightLeft
+}
+
+// along returns the size of n along the rank axis.
+func (l *layout) along(n *Node) float64 {
+	if l.g.horizontal() {
+		return n.w
+	}
+	return n.h
+}
+
+// across returns the size of n across the rank axis.
+func (l *layout) across(n *Node) float64 {
+	if l.g.horizontal() {
+		return n.h
+	}
+	return n.w
+}
+
+func (g *Graph) layout() {
+	for _, n := range g.nodes {
+		n.w, n.h = nodeSize(n)
+	}
+
+	l := &layout{
+		g:     g,
+		preds: make(map[*Node][]*Node),
+		succs: make(map[*Node][]*Node),
+	}
+
+	l.breakCycles()
+	l.assignRanks()
+	l.addDummies()
+	l.orderRanks()
+	l.assignCoordinates()
+	l.routeEdges()
+}
+
+// breakCycles reverses the back edges found in a depth first search,
+// making the graph acyclic.
+func (l *layout) breakCycles() {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make(map[*Node]int)
+	out := make(map[*Node][]*Edge)
+	for _, e := range l.g.edges {
+		out[e.From] = append(out[e.From], e)
+	}
+
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		state[n] = onStack
+		for _, e := range out[n] {
+			if e.From == e.To {
+				continue
+			}
+			switch state[e.To] {
+			case unvisited:
+				visit(e.To)
+			case onStack:
+				e.reversed = true
+			}
+		}
+		state[n] = done
+	}
+
+	for _, n := range l.g.nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+}
+
+// ends returns the ends of e in layout direction, i.e. after any reversal.
+func (e *Edge) ends() (*Node, *Node) {
+	if e.reversed {
+		return e.To, e.From
+	}
+	return e.From, e.To
+}
+
+// assignRanks assigns each node to the rank given by the longest path from a source,
+// then moves sources down as close as possible to their successors.
+func (l *layout) assignRanks() {
+	indegree := make(map[*Node]int)
+	out := make(map[*Node][]*Node)
+	in := make(map[*Node][]*Node)
+	for _, e := range l.g.edges {
+		if e.From == e.To {
+			continue
+		}
+		from, to := e.ends()
+		out[from] = append(out[from], to)
+		in[to] = append(in[to], from)
+		indegree[to]++
+	}
+
+	var queue []*Node
+	for _, n := range l.g.nodes {
+		n.rank = 0
+		if indegree[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+
+	var topo []*Node
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		topo = append(topo, n)
+		for _, m := range out[n] {
+			if n.rank+1 > m.rank {
+				m.rank = n.rank + 1
+			}
+			indegree[m]--
+			if indegree[m] == 0 {
+				queue = append(queue, m)
+			}
+		}
+	}
+
+	// Tighten the sources.
+	for i := len(topo) - 1; i >= 0; i-- {
+		n := topo[i]
+		if len(in[n]) > 0 || len(out[n]) == 0 {
+			continue
+		}
+		min := math.MaxInt32
+		for _, m := range out[n] {
+			if m.rank < min {
+				min = m.rank
+			}
+		}
+		n.rank = min - 1
+	}
+
+	maxRank := 0
+	for _, n := range l.g.nodes {
+		if n.rank > maxRank {
+			maxRank = n.rank
+		}
+	}
+	l.ranks = make([][]*Node, maxRank+1)
+	for _, n := range l.g.nodes {
+		l.ranks[n.rank] = append(l.ranks[n.rank], n)
+	}
+}
+
+// addDummies splits edges spanning more than one rank into a chain of dummy nodes.
+func (l *layout) addDummies() {
+	seen := make(map[layoutEdge]bool)
+	link := func(from, to *Node) {
+		le := layoutEdge{from, to}
+		if seen[le] {
+			return
+		}
+		seen[le] = true
+		l.succs[from] = append(l.succs[from], to)
+		l.preds[to] = append(l.preds[to], from)
+	}
+
+	for _, e := range l.g.edges {
+		if e.From == e.To {
+			continue
+		}
+		from, to := e.ends()
+		prev := from
+		for r := from.rank + 1; r < to.rank; r++ {
+			d := &Node{dummy: true, rank: r, w: dummySize, h: dummySize}
+			l.ranks[r] = append(l.ranks[r], d)
+			e.dummies = append(e.dummies, d)
+			link(prev, d)
+			prev = d
+		}
+		link(prev, to)
+	}
+
+	for _, rank := range l.ranks {
+		for i, n := range rank {
+			n.order = i
+		}
+	}
+}
+
+// orderRanks orders the nodes within each rank to reduce edge crossings,
+// using the barycenter heuristic.
+func (l *layout) orderRanks() {
+	best := l.saveOrder()
+	bestCrossings := l.crossings()
+
+	for i := 0; i < orderIters && bestCrossings > 0; i++ {
+		if i%2 == 0 {
+			for r := 1; r < len(l.ranks); r++ {
+				l.sortRank(r, l.preds)
+			}
+		} else {
+			for r := len(l.ranks) - 2; r >= 0; r-- {
+				l.sortRank(r, l.succs)
+			}
+		}
+		if c := l.crossings(); c < bestCrossings {
+			bestCrossings = c
+			best = l.saveOrder()
+		}
+	}
+
+	l.restoreOrder(best)
+}
+
+func (l *layout) sortRank(r int, neighbours map[*Node][]*Node) {
+	rank := l.ranks[r]
+	keys := make(map[*Node]float64, len(rank))
+	for _, n := range rank {
+		nn := neighbours[n]
+		if len(nn) == 0 {
+			keys[n] = float64(n.order)
+			continue
+		}
+		var sum float64
+		for _, m := range nn {
+			sum += float64(m.order)
+		}
+		keys[n] = sum / float64(len(nn))
+	}
+	sort.SliceStable(rank, func(i, j int) bool {
+		return keys[rank[i]] < keys[rank[j]]
+	})
+	for i, n := range rank {
+		n.order = i
+	}
+}
+
+func (l *layout) crossings() int {
+	var count int
+	for r := 0; r < len(l.ranks)-1; r++ {
+		var edges []layoutEdge
+		for _, n := range l.ranks[r] {
+			for _, m := range l.succs[n] {
+				edges = append(edges, layoutEdge{n, m})
+			}
+		}
+		for i := 0; i < len(edges); i++ {
+			for j := i + 1; j < len(edges); j++ {
+				a, b := edges[i], edges[j]
+				if (a.from.order-b.from.order)*(a.to.order-b.to.order) < 0 {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+func (l *layout) saveOrder() [][]*Node {
+	saved := make([][]*Node, len(l.ranks))
+	for i, rank := range l.ranks {
+		saved[i] = append([]*Node(nil), rank...)
+	}
+	return saved
+}
+
+func (l *layout) restoreOrder(saved [][]*Node) {
+	l.ranks = saved
+	for _, rank := range l.ranks {
+		for i, n := range rank {
+			n.order = i
+		}
+	}
+}
+
+// assignCoordinates assigns the node centers.
+func (l *layout) assignCoordinates() {
+	// Positions along the rank axis.
+	sep := rankSep + l.maxLabelAlong()
+	alongPos := make([]float64, len(l.ranks))
+	var pos float64
+	for r, rank := range l.ranks {
+		var max float64
+		for _, n := range rank {
+			if s := l.along(n); s > max {
+				max = s
+			}
+		}
+		alongPos[r] = pos + max/2
+		pos += max + sep
+	}
+
+	// Positions across the rank axis, initially packed.
+	acrossPos := make(map[*Node]float64)
+	for _, rank := range l.ranks {
+		var p float64
+		for i, n := range rank {
+			if i > 0 {
+				p += l.gap(rank[i-1], n)
+			}
+			acrossPos[n] = p
+		}
+	}
+
+	// Move the nodes towards their neighbours.
+	for i := 0; i < coordIters; i++ {
+		if i%2 == 0 {
+			for r := 1; r < len(l.ranks); r++ {
+				l.align(l.ranks[r], l.preds, acrossPos)
+			}
+		} else {
+			for r := len(l.ranks) - 2; r >= 0; r-- {
+				l.align(l.ranks[r], l.succs, acrossPos)
+			}
+		}
+	}
+
+	// Normalize.
+	min := math.MaxFloat64
+	for n, p := range acrossPos {
+		if v := p - l.across(n)/2; v < min {
+			min = v
+		}
+	}
+	var maxAlong float64
+	for _, rank := range l.ranks {
+		for _, n := range rank {
+			if v := alongPos[n.rank] + l.along(n)/2; v > maxAlong {
+				maxAlong = v
+			}
+		}
+	}
+
+	for _, rank := range l.ranks {
+		for _, n := range rank {
+			a, c := alongPos[n.rank], acrossPos[n]-min
+			switch l.g.Direction {
+			case TopDown:
+				n.x, n.y = c, a
+			case BottomUp:
+				n.x, n.y = c, maxAlong-a
+			case LeftRight:
+				n.x, n.y = a, c
+			case RightLeft:
+				n.x, n.y = maxAlong-a, c
+			}
+		}
+	}
+}
+
+// maxLabelAlong returns the extra space needed between ranks to fit the edge labels.
+func (l *layout) maxLabelAlong() float64 {
+	var max float64
+	for _, e := range l.g.edges {
+		if e.Label == "" {
+			continue
+		}
+		var s float64
+		if l.g.horizontal() {
+			s = svg.TextWidth(e.Label) + 10
+		} else {
+			s = svg.TextHeight(e.Label)
+		}
+		if s > max {
+			max = s
+		}
+	}
+	return max
+}
+
+// gap returns the minimum distance between the centers of the adjacent nodes a and b.
+func (l *layout) gap(a, b *Node) float64 {
+	sep := nodeSep
+	if a.dummy || b.dummy {
+		sep = nodeSep / 2
+	}
+	return (l.across(a)+l.across(b))/2 + sep
+}
+
+// align moves the nodes in rank towards the average position of their
+// neighbours, keeping the order and the minimum gaps.
+func (l *layout) align(rank []*Node, neighbours map[*Node][]*Node, pos map[*Node]float64) {
+	if len(rank) == 0 {
+		return
+	}
+	desired := make([]float64, len(rank))
+	for i, n := range rank {
+		nn := neighbours[n]
+		if len(nn) == 0 {
+			desired[i] = pos[n]
+			continue
+		}
+		var sum float64
+		for _, m := range nn {
+			sum += pos[m]
+		}
+		desired[i] = sum / float64(len(nn))
+	}
+
+	// Two feasible solutions, one pushing right and one pushing left.
+	// Their average is also feasible.
+	right := make([]float64, len(rank))
+	left := make([]float64, len(rank))
+	for i := range rank {
+		right[i] = desired[i]
+		if i > 0 {
+			right[i] = math.Max(right[i], right[i-1]+l.gap(rank[i-1], rank[i]))
+		}
+	}
+	for i := len(rank) - 1; i >= 0; i-- {
+		left[i] = desired[i]
+		if i < len(rank)-1 {
+			left[i] = math.Min(left[i], left[i+1]-l.gap(rank[i], rank[i+1]))
+		}
+	}
+	for i, n := range rank {
+		pos[n] = (left[i] + right[i]) / 2
+	}
+}
+
+// routeEdges computes the points of the edges.
+func (l *layout) routeEdges() {
+	// Edges between the same two nodes, in any direction, are bent apart.
+	type pair struct{ a, b *Node }
+	parallel := make(map[pair][]*Edge)
+	for _, e := range l.g.edges {
+		if e.From != e.To && len(e.dummies) == 0 {
+			from, to := e.ends()
+			parallel[pair{from, to}] = append(parallel[pair{from, to}], e)
+		}
+	}
+
+	for _, e := range l.g.edges {
+		if e.From == e.To {
+			e.points = selfLoop(e.From, l.g.horizontal())
+			continue
+		}
+		from, to := e.ends()
+		points := []svg.Point{{X: from.x, Y: from.y}}
+		for _, d := range e.dummies {
+			points = append(points, svg.Point{X: d.x, Y: d.y})
+		}
+		if edges := parallel[pair{from, to}]; len(edges) > 1 {
+			for i, pe := range edges {
+				if pe != e {
+					continue
+				}
+				if offset := (float64(i) - float64(len(edges)-1)/2) * 25; offset != 0 {
+					dx, dy := to.x-from.x, to.y-from.y
+					d := math.Hypot(dx, dy)
+					points = append(points, svg.Point{
+						X: (from.x+to.x)/2 - dy/d*offset,
+						Y: (from.y+to.y)/2 + dx/d*offset,
+					})
+				}
+			}
+		}
+		points = append(points, svg.Point{X: to.x, Y: to.y})
+		if e.reversed {
+			for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+				points[i], points[j] = points[j], points[i]
+			}
+		}
+		n := len(points)
+		points[0] = clip(e.From, points[1])
+		points[n-1] = clip(e.To, points[n-2])
+		e.points = points
+	}
+}
+
+func selfLoop(n *Node, horizontal bool) []svg.Point {
+	if horizontal {
+		top := n.y + n.h/2
+		return []svg.Point{
+			{X: n.x - n.w/4, Y: top},
+			{X: n.x - n.w/4, Y: top + 20},
+			{X: n.x + n.w/4, Y: top + 20},
+			{X: n.x + n.w/4, Y: top},
+		}
+	}
+	right := n.x + n.w/2
+	return []svg.Point{
+		{X: right, Y: n.y - n.h/4},
+		{X: right + 20, Y: n.y - n.h/4},
+		{X: right + 20, Y: n.y + n.h/4},
+		{X: right, Y: n.y + n.h/4},
+	}
+}
+
+// clip returns the point where the line from the center of n to p crosses
+// the border of n.
+func clip(n *Node, p svg.Point) svg.Point {
+	dx, dy := p.X-n.x, p.Y-n.y
+	if dx == 0 && dy == 0 {
+		return p
+	}
+	hw, hh := n.w/2, n.h/2
+
+	var t float64
+	switch n.Shape {
+	case Circle, DoubleCircle, Ellipse, Point:
+		t = 1 / math.Sqrt((dx*dx)/(hw*hw)+(dy*dy)/(hh*hh))
+	case Diamond:
+		t = 1 / (math.Abs(dx)/hw + math.Abs(dy)/hh)
+	default:
+		t = math.Min(
+			safeDiv(hw, math.Abs(dx)),
+			safeDiv(hh, math.Abs(dy)),
+		)
+	}
+	if t > 1 {
+		t = 1
+	}
+	return svg.Point{X: n.x + dx*t, Y: n.y + dy*t}
+}
+
+func safeDiv(a, b float64) float64 {
+	if b == 0 {
+		return math.MaxFloat64
+	}
+	return a / b
+}
+
+// nodeSize returns the width and height of n.
+func nodeSize(n *Node) (float64, float64) {
+	tw, th := svg.TextWidth(n.Label), svg.TextHeight(n.Label)
+	bw, bh := tw+2*paddingX, th+2*paddingY
+
+	switch n.Shape {
+	case Point:
+		return 8, 8
+	case Plain:
+		return tw + 4, th + 4
+	case Stadium:
+		return tw + bh, bh
+	case Circle:
+		d := math.Max(tw+2*paddingY, bh)
+		return d, d
+	case DoubleCircle:
+		d := math.Max(tw+2*paddingY, bh) + 8
+		return d, d
+	case Diamond:
+		// A square diamond with the text box inscribed.
+		d := bw + bh
+		return d, d
+	case Hexagon:
+		return bw + bh/2, bh
+	case Ellipse:
+		return bw * math.Sqrt2, bh * math.Sqrt2
+	case Flag, Parallelogram:
+		return bw + 20, bh
+	case Cylinder:
+		return bw, bh + 10
+	default:
+		return bw, bh
+	}
+}
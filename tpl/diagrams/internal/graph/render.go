@@ -0,0 +1,231 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/svg"
+)
+
+const margin = 10.0
+
+// Render lays out the graph and renders it as SVG.
+func (g *Graph) Render() svg.Diagram {
+	if len(g.nodes) == 0 {
+		return svg.Diagram{}
+	}
+
+	g.layout()
+
+	// Compute the bounding box.
+	minX, minY := math.MaxFloat64, math.MaxFloat64
+	maxX, maxY := -math.MaxFloat64, -math.MaxFloat64
+	extend := func(x, y float64) {
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	for _, n := range g.nodes {
+		extend(n.x-n.w/2, n.y-n.h/2)
+		extend(n.x+n.w/2, n.y+n.h/2)
+	}
+	for _, e := range g.edges {
+		for _, p := range e.points {
+			extend(p.X, p.Y)
+		}
+		if e.Label != "" {
+			p := labelPosition(e.points)
+			w, h := svg.TextWidth(e.Label)/2+4, svg.TextHeight(e.Label)/2
+			extend(p.X-w, p.Y-h)
+			extend(p.X+w, p.Y+h)
+		}
+	}
+
+	dx, dy := margin-minX, margin-minY
+
+	var b svg.Builder
+	b.Open("g", "transform", fmt.Sprintf("translate(%s,%s)", svg.FormatFloat(dx), svg.FormatFloat(dy)))
+
+	for _, e := range g.edges {
+		renderEdge(&b, e)
+	}
+	for _, n := range g.nodes {
+		renderNode(&b, n)
+	}
+	for _, e := range g.edges {
+		renderEdgeLabel(&b, e)
+	}
+
+	b.Close("g")
+
+	return svg.Diagram{
+		Inner:  b.String(),
+		Width:  int(math.Ceil(maxX - minX + 2*margin)),
+		Height: int(math.Ceil(maxY - minY + 2*margin)),
+	}
+}
+
+func lineStyleAttrs(s LineStyle) []interface{} {
+	switch s {
+	case Dashed:
+		return []interface{}{"stroke-dasharray", "6 4"}
+	case Dotted:
+		return []interface{}{"stroke-dasharray", "2 3"}
+	case Thick:
+		return []interface{}{"stroke-width", 3}
+	}
+	return nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func renderNode(b *svg.Builder, n *Node) {
+	if n.Style == Invisible {
+		return
+	}
+	fill := orDefault(n.Fill, svg.Fill)
+	stroke := orDefault(n.Stroke, svg.Stroke)
+	extra := append([]interface{}{"class", "node"}, lineStyleAttrs(n.Style)...)
+	attrs := append([]interface{}{"fill", fill, "stroke", stroke}, extra...)
+
+	x0, y0 := n.x-n.w/2, n.y-n.h/2
+	x1, y1 := n.x+n.w/2, n.y+n.h/2
+	labelY := n.y
+
+	switch n.Shape {
+	case Plain:
+	case Point:
+		b.Element("circle", "cx", n.x, "cy", n.y, "r", n.w/2, "class", "node", "fill", stroke, "stroke", stroke)
+		return
+	case RoundedRect:
+		b.Element("rect", append([]interface{}{"x", x0, "y", y0, "width", n.w, "height", n.h, "rx", 5}, attrs...)...)
+	case Stadium:
+		b.Element("rect", append([]interface{}{"x", x0, "y", y0, "width", n.w, "height", n.h, "rx", n.h / 2}, attrs...)...)
+	case Circle:
+		b.Element("circle", append([]interface{}{"cx", n.x, "cy", n.y, "r", n.w / 2}, attrs...)...)
+	case DoubleCircle:
+		b.Element("circle", append([]interface{}{"cx", n.x, "cy", n.y, "r", n.w / 2}, attrs...)...)
+		b.Element("circle", append([]interface{}{"cx", n.x, "cy", n.y, "r", n.w/2 - 4}, attrs...)...)
+	case Ellipse:
+		b.Element("ellipse", append([]interface{}{"cx", n.x, "cy", n.y, "rx", n.w / 2, "ry", n.h / 2}, attrs...)...)
+	case Diamond:
+		b.Polygon([]svg.Point{{X: n.x, Y: y0}, {X: x1, Y: n.y}, {X: n.x, Y: y1}, {X: x0, Y: n.y}}, fill, stroke, extra...)
+	case Hexagon:
+		inset := n.h / 4
+		b.Polygon([]svg.Point{
+			{X: x0 + inset, Y: y0}, {X: x1 - inset, Y: y0}, {X: x1, Y: n.y},
+			{X: x1 - inset, Y: y1}, {X: x0 + inset, Y: y1}, {X: x0, Y: n.y},
+		}, fill, stroke, extra...)
+	case Flag:
+		b.Polygon([]svg.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0 + 15, Y: n.y}}, fill, stroke, extra...)
+	case Parallelogram:
+		b.Polygon([]svg.Point{{X: x0 + 10, Y: y0}, {X: x1, Y: y0}, {X: x1 - 10, Y: y1}, {X: x0, Y: y1}}, fill, stroke, extra...)
+	case Cylinder:
+		const ry = 5.0
+		rx := n.w / 2
+		d := fmt.Sprintf("M%s,%s a%s,%s 0 0,0 %s,0 a%s,%s 0 0,0 %s,0 l0,%s a%s,%s 0 0,0 %s,0 l0,%s",
+			svg.FormatFloat(x0), svg.FormatFloat(y0+ry),
+			svg.FormatFloat(rx), svg.FormatFloat(ry), svg.FormatFloat(n.w),
+			svg.FormatFloat(rx), svg.FormatFloat(ry), svg.FormatFloat(-n.w),
+			svg.FormatFloat(n.h-2*ry),
+			svg.FormatFloat(rx), svg.FormatFloat(ry), svg.FormatFloat(n.w),
+			svg.FormatFloat(-(n.h - 2*ry)),
+		)
+		b.Element("path", append([]interface{}{"d", d}, attrs...)...)
+		labelY += ry / 2
+	default:
+		b.Element("rect", append([]interface{}{"x", x0, "y", y0, "width", n.w, "height", n.h}, attrs...)...)
+	}
+
+	b.Text(n.x, labelY, n.Label, n.FontColor)
+}
+
+func renderEdge(b *svg.Builder, e *Edge) {
+	if e.Style == Invisible || len(e.points) < 2 {
+		return
+	}
+	color := orDefault(e.Color, svg.Stroke)
+	width := 1.5
+	if e.Style == Thick {
+		width = 3
+	}
+
+	attrs := []interface{}{"class", "edge", "stroke-width", width}
+	switch e.Style {
+	case Dashed:
+		attrs = append(attrs, "stroke-dasharray", "6 4")
+	case Dotted:
+		attrs = append(attrs, "stroke-dasharray", "2 3")
+	}
+
+	b.Polyline(e.points, color, attrs...)
+
+	n := len(e.points)
+	renderArrow(b, e.Head, e.points[n-2], e.points[n-1], color)
+	renderArrow(b, e.Tail, e.points[1], e.points[0], color)
+}
+
+func renderArrow(b *svg.Builder, a Arrow, from, to svg.Point, color string) {
+	switch a {
+	case ArrowNormal:
+		b.ArrowHead(from, to, color)
+	case ArrowOpen:
+		b.OpenArrowHead(from, to, color)
+	case ArrowCross:
+		b.Cross(to, color)
+	case ArrowCircle:
+		b.Element("circle", "cx", to.X, "cy", to.Y, "r", 4, "fill", svg.Fill, "stroke", color)
+	}
+}
+
+func renderEdgeLabel(b *svg.Builder, e *Edge) {
+	if e.Label == "" || e.Style == Invisible {
+		return
+	}
+	p := labelPosition(e.points)
+	w, h := svg.TextWidth(e.Label)+8, svg.TextHeight(e.Label)
+	b.Element("rect", "x", p.X-w/2, "y", p.Y-h/2, "width", w, "height", h, "fill", svg.Fill, "fill-opacity", 0.85)
+	b.Text(p.X, p.Y, e.Label, "", "class", "edge-label")
+}
+
+// labelPosition returns the middle point of the polyline.
+func labelPosition(points []svg.Point) svg.Point {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		total += distance(points[i-1], points[i])
+	}
+	half := total / 2
+	for i := 1; i < len(points); i++ {
+		d := distance(points[i-1], points[i])
+		if half <= d && d > 0 {
+			t := half / d
+			return svg.Point{
+				X: points[i-1].X + (points[i].X-points[i-1].X)*t,
+				Y: points[i-1].Y + (points[i].Y-points[i-1].Y)*t,
+			}
+		}
+		half -= d
+	}
+	return points[len(points)-1]
+}
+
+func distance(a, b svg.Point) float64 {
+	return math.Hypot(b.X-a.X, b.Y-a.Y)
+}
@@ -0,0 +1,443 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mermaid
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/graph"
+)
+
+var directions = map[string]graph.Direction{
+	"TB": graph.TopDown,
+	"TD": graph.TopDown,
+	"BT": graph.BottomUp,
+	"LR": graph.LeftRight,
+	"RL": graph.RightLeft,
+}
+
+// nodeShapes maps the node shape delimiters to shapes.
+// Longer openers must come before their prefixes.
+var nodeShapes = []struct {
+	open   string
+	closes []string
+	shape  graph.Shape
+}{
+	{"(((", []string{")))"}, graph.DoubleCircle},
+	{"((", []string{"))"}, graph.Circle},
+	{"([", []string{"])"}, graph.Stadium},
+	{"(", []string{")"}, graph.RoundedRect},
+	{"[[", []string{"]]"}, graph.Rect},
+	{"[(", []string{")]"}, graph.Cylinder},
+	{"[/", []string{"/]", `\]`}, graph.Parallelogram},
+	{`[\`, []string{`\]`, "/]"}, graph.Parallelogram},
+	{"[", []string{"]"}, graph.Rect},
+	{"{{", []string{"}}"}, graph.Hexagon},
+	{"{", []string{"}"}, graph.Diamond},
+	{">", []string{"]"}, graph.Flag},
+}
+
+type flowParser struct {
+	g *graph.Graph
+
+	classDefs map[string]string
+	classes   map[*graph.Node][]string
+}
+
+func parseFlowchart(lines []line) (*graph.Graph, error) {
+	p := &flowParser{
+		g:         graph.New(),
+		classDefs: make(map[string]string),
+		classes:   make(map[*graph.Node][]string),
+	}
+
+	header := lines[0]
+	stmts := splitStatements(header.text)
+	fields := strings.Fields(stmts[0])
+	if len(fields) > 1 {
+		dir, found := directions[fields[1]]
+		if !found {
+			return nil, header.errorf("unknown direction %q", fields[1])
+		}
+		p.g.Direction = dir
+	}
+
+	for i, l := range lines {
+		stmts := splitStatements(l.text)
+		if i == 0 {
+			stmts = stmts[1:]
+		}
+		for _, stmt := range stmts {
+			if err := p.parseStatement(l, stmt); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for n, classes := range p.classes {
+		for _, class := range classes {
+			applyStyle(n, p.classDefs[class])
+		}
+	}
+
+	return p.g, nil
+}
+
+// splitStatements splits s on semicolons outside of quotes and node text.
+func splitStatements(s string) []string {
+	var (
+		stmts   []string
+		depth   int
+		inQuote bool
+		start   int
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '[' || c == '(' || c == '{':
+			depth++
+		case c == ']' || c == ')' || c == '}':
+			depth--
+		case c == ';' && depth <= 0:
+			stmts = append(stmts, s[start:i])
+			start = i + 1
+		}
+	}
+	stmts = append(stmts, s[start:])
+
+	// Drop empty statements, but keep the header statement in place.
+	filtered := []string{stmts[0]}
+	for _, stmt := range stmts[1:] {
+		if strings.TrimSpace(stmt) != "" {
+			filtered = append(filtered, strings.TrimSpace(stmt))
+		}
+	}
+	return filtered
+}
+
+func (p *flowParser) parseStatement(l line, stmt string) error {
+	fields := strings.Fields(stmt)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	switch fields[0] {
+	case "subgraph", "end", "direction", "click", "linkStyle":
+		return nil
+	case "style":
+		if len(fields) < 3 {
+			return l.errorf("invalid style statement")
+		}
+		if n := p.g.Lookup(fields[1]); n != nil {
+			applyStyle(n, strings.Join(fields[2:], " "))
+		}
+		return nil
+	case "classDef":
+		if len(fields) < 3 {
+			return l.errorf("invalid classDef statement")
+		}
+		for _, name := range strings.Split(fields[1], ",") {
+			p.classDefs[name] = strings.Join(fields[2:], " ")
+		}
+		return nil
+	case "class":
+		if len(fields) < 3 {
+			return l.errorf("invalid class statement")
+		}
+		for _, id := range strings.Split(fields[1], ",") {
+			n := p.g.Node(id)
+			p.classes[n] = append(p.classes[n], fields[2])
+		}
+		return nil
+	}
+	if strings.HasPrefix(fields[0], "accTitle") || strings.HasPrefix(fields[0], "accDescr") {
+		return nil
+	}
+
+	sc := &scanner{l: l, s: stmt}
+	from, err := p.parseNodeGroup(sc)
+	if err != nil {
+		return err
+	}
+	for {
+		sc.skipSpace()
+		if sc.eof() {
+			return nil
+		}
+		link, err := p.parseLink(sc)
+		if err != nil {
+			return err
+		}
+		to, err := p.parseNodeGroup(sc)
+		if err != nil {
+			return err
+		}
+		for _, a := range from {
+			for _, b := range to {
+				e := p.g.AddEdge(a, b)
+				e.Label, e.Style, e.Head, e.Tail = link.Label, link.Style, link.Head, link.Tail
+			}
+		}
+		from = to
+	}
+}
+
+func (p *flowParser) parseNodeGroup(sc *scanner) ([]*graph.Node, error) {
+	var nodes []*graph.Node
+	for {
+		sc.skipSpace()
+		n, err := p.parseNode(sc)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+		sc.skipSpace()
+		if !sc.consume("&") {
+			return nodes, nil
+		}
+	}
+}
+
+func (p *flowParser) parseNode(sc *scanner) (*graph.Node, error) {
+	start := sc.pos
+	for !sc.eof() {
+		r, w := utf8.DecodeRuneInString(sc.rest())
+		if !isIDRune(r) {
+			break
+		}
+		sc.pos += w
+	}
+	if sc.pos == start {
+		return nil, sc.errorf("expected node id")
+	}
+	n := p.g.Node(sc.s[start:sc.pos])
+
+	for _, ns := range nodeShapes {
+		if !sc.consume(ns.open) {
+			continue
+		}
+		text, err := sc.readText(ns.closes)
+		if err != nil {
+			return nil, err
+		}
+		n.Label = cleanText(text)
+		n.Shape = ns.shape
+		break
+	}
+
+	if sc.consume(":::") {
+		start := sc.pos
+		for !sc.eof() {
+			r, w := utf8.DecodeRuneInString(sc.rest())
+			if !isIDRune(r) && r != '-' {
+				break
+			}
+			sc.pos += w
+		}
+		p.classes[n] = append(p.classes[n], sc.s[start:sc.pos])
+	}
+
+	return n, nil
+}
+
+// parseLink parses a link, e.g. -->, -.->, ==>, --o, <-->, -->|text| or -- text -->.
+func (p *flowParser) parseLink(sc *scanner) (*graph.Edge, error) {
+	e := &graph.Edge{}
+	rest := sc.rest()
+	if len(rest) > 1 && strings.ContainsRune("<xo", rune(rest[0])) && strings.ContainsRune("-=.", rune(rest[1])) {
+		e.Tail = linkArrow(rest[0])
+		sc.pos++
+	}
+
+	run, head := sc.readLinkLine()
+	if run == "" {
+		return nil, sc.errorf("expected link")
+	}
+
+	if head == 0 && (run == "--" || run == "==" || run == "-.") {
+		// Link with the text in the middle, e.g. -- text -->.
+		closer := run
+		if run == "-." {
+			closer = ".-"
+		}
+		i := strings.Index(sc.rest(), closer)
+		if i == -1 {
+			return nil, sc.errorf("unterminated link text")
+		}
+		e.Label = cleanText(sc.rest()[:i])
+		sc.pos += i
+		run, head = sc.readLinkLine()
+	}
+
+	switch {
+	case strings.Contains(run, "~"):
+		e.Style = graph.Invisible
+	case strings.Contains(run, "="):
+		e.Style = graph.Thick
+	case strings.Contains(run, "."):
+		e.Style = graph.Dotted
+	}
+	e.Head = linkArrow(head)
+
+	sc.skipSpace()
+	if sc.consume("|") {
+		i := strings.Index(sc.rest(), "|")
+		if i == -1 {
+			return nil, sc.errorf("unterminated link text")
+		}
+		e.Label = cleanText(sc.rest()[:i])
+		sc.pos += i + 1
+	}
+
+	return e, nil
+}
+
+func linkArrow(c byte) graph.Arrow {
+	switch c {
+	case '>', '<':
+		return graph.ArrowNormal
+	case 'x':
+		return graph.ArrowCross
+	case 'o':
+		return graph.ArrowCircle
+	}
+	return graph.ArrowNone
+}
+
+// applyStyle applies the CSS style declarations in css, e.g. "fill:#f9f,stroke:#333", to n.
+func applyStyle(n *graph.Node, css string) {
+	for _, decl := range strings.FieldsFunc(css, func(r rune) bool { return r == ',' || r == ';' }) {
+		kv := strings.SplitN(decl, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v := strings.TrimSpace(kv[1])
+		switch strings.TrimSpace(kv[0]) {
+		case "fill":
+			n.Fill = v
+		case "stroke":
+			n.Stroke = v
+		case "color":
+			n.FontColor = v
+		case "stroke-dasharray":
+			n.Style = graph.Dashed
+		}
+	}
+}
+
+func isIDRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// scanner scans a flowchart statement.
+type scanner struct {
+	l   line
+	s   string
+	pos int
+}
+
+func (sc *scanner) eof() bool {
+	return sc.pos >= len(sc.s)
+}
+
+func (sc *scanner) rest() string {
+	return sc.s[sc.pos:]
+}
+
+func (sc *scanner) skipSpace() {
+	for !sc.eof() && (sc.s[sc.pos] == ' ' || sc.s[sc.pos] == '\t') {
+		sc.pos++
+	}
+}
+
+// consume advances past prefix if the remaining input starts with it.
+func (sc *scanner) consume(prefix string) bool {
+	if strings.HasPrefix(sc.rest(), prefix) {
+		sc.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+// readText reads the node text up to and including the first of the given closing delimiters.
+func (sc *scanner) readText(closes []string) (string, error) {
+	rest := sc.rest()
+	if strings.HasPrefix(strings.TrimSpace(rest), `"`) {
+		open := strings.Index(rest, `"`)
+		end := strings.Index(rest[open+1:], `"`)
+		if end == -1 {
+			return "", sc.errorf("unterminated string")
+		}
+		text := rest[open+1 : open+1+end]
+		sc.pos += open + end + 2
+		sc.skipSpace()
+		for _, c := range closes {
+			if sc.consume(c) {
+				return text, nil
+			}
+		}
+		return "", sc.errorf("expected %q", closes[0])
+	}
+
+	best := -1
+	var closer string
+	for _, c := range closes {
+		if i := strings.Index(rest, c); i != -1 && (best == -1 || i < best) {
+			best, closer = i, c
+		}
+	}
+	if best == -1 {
+		return "", sc.errorf("expected %q", closes[0])
+	}
+	sc.pos += best + len(closer)
+	return rest[:best], nil
+}
+
+// readLinkLine reads the line part of a link, e.g. --- or -.-, and any arrow head.
+func (sc *scanner) readLinkLine() (string, byte) {
+	start := sc.pos
+	for !sc.eof() && strings.ContainsRune("-=.~", rune(sc.s[sc.pos])) {
+		sc.pos++
+	}
+	run := sc.s[start:sc.pos]
+	if run == "" || sc.eof() {
+		return run, 0
+	}
+	switch c := sc.s[sc.pos]; c {
+	case '>':
+		sc.pos++
+		return run, c
+	case 'x', 'o':
+		if sc.pos+1 == len(sc.s) {
+			break
+		}
+		if r, _ := utf8.DecodeRuneInString(sc.s[sc.pos+1:]); !isIDRune(r) {
+			sc.pos++
+			return run, c
+		}
+	}
+	return run, 0
+}
+
+func (sc *scanner) errorf(format string, args ...interface{}) error {
+	near := sc.rest()
+	if len(near) > 20 {
+		near = near[:20]
+	}
+	return sc.l.errorf(format+" near %q", append(args, near)...)
+}
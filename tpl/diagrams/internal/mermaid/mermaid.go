@@ -0,0 +1,117 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mermaid renders a subset of the Mermaid diagram syntax as SVG.
+//
+// Flowcharts (graph and flowchart) and sequence diagrams (sequenceDiagram) are supported.
+// Directives, comments, click handlers and link styles are ignored, and subgraphs are
+// flattened into the enclosing graph.
+package mermaid
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/svg"
+)
+
+const (
+	typeFlowchart = "flowchart"
+	typeSequence  = "sequence"
+)
+
+// Render parses the Mermaid diagram in src and renders it as SVG.
+func Render(src string) (svg.Diagram, error) {
+	lines := splitLines(src)
+	if len(lines) == 0 {
+		return svg.Diagram{}, fmt.Errorf("mermaid: empty diagram")
+	}
+
+	switch diagramType(lines) {
+	case typeFlowchart:
+		g, err := parseFlowchart(lines)
+		if err != nil {
+			return svg.Diagram{}, err
+		}
+		return g.Render(), nil
+	case typeSequence:
+		d, err := parseSequence(lines)
+		if err != nil {
+			return svg.Diagram{}, err
+		}
+		return d.render(), nil
+	default:
+		return svg.Diagram{}, fmt.Errorf("mermaid: line %d: unsupported diagram type %q", lines[0].num, strings.Fields(lines[0].text)[0])
+	}
+}
+
+// Supported reports whether the type of the Mermaid diagram in src is supported.
+func Supported(src string) bool {
+	lines := splitLines(src)
+	return len(lines) > 0 && diagramType(lines) != ""
+}
+
+func diagramType(lines []line) string {
+	switch strings.Fields(lines[0].text)[0] {
+	case "graph", "flowchart":
+		return typeFlowchart
+	case "sequenceDiagram":
+		return typeSequence
+	}
+	return ""
+}
+
+// line is a non-empty source line.
+type line struct {
+	num  int
+	text string
+}
+
+func (l line) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("mermaid: line %d: %s", l.num, fmt.Sprintf(format, args...))
+}
+
+// splitLines splits src into trimmed lines, skipping blank lines,
+// comments, directives and any front matter.
+func splitLines(src string) []line {
+	var lines []line
+	inFrontMatter := false
+	for i, s := range strings.Split(src, "\n") {
+		s = strings.TrimSpace(s)
+		if s == "---" && (inFrontMatter || len(lines) == 0) {
+			inFrontMatter = !inFrontMatter
+			continue
+		}
+		if inFrontMatter || s == "" || strings.HasPrefix(s, "%%") {
+			continue
+		}
+		lines = append(lines, line{num: i + 1, text: s})
+	}
+	return lines
+}
+
+var (
+	breakRe      = regexp.MustCompile(`(?i)<br\s*/?>`)
+	textReplacer = strings.NewReplacer("#quot;", `"`, "#59;", ";", "#35;", "#", "#lt;", "<", "#gt;", ">", "#amp;", "&")
+)
+
+// cleanText unquotes s and converts line breaks and entity codes.
+func cleanText(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	s = breakRe.ReplaceAllString(s, "\n")
+	return textReplacer.Replace(s)
+}
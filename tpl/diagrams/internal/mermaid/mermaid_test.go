@@ -0,0 +1,244 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mermaid
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/graph"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFlowchart(t *testing.T) {
+	c := qt.New(t)
+
+	g, err := parseFlowchart(splitLines(`
+%% A comment.
+flowchart LR
+  A[Hard edge] -->|Link text| B(Round edge)
+  B --> C{Decision}
+  C -- Two --> D([Result two]) & E[(Database)]
+  D -.-> F((Circle)); F ==> G{{Hex}}
+  G --x H>Flag] --o A
+  I["Quoted<br>text"]:::green <--> A
+  subgraph one
+    J[/Slanted/] --- K
+  end
+  style A fill:#f9f,stroke:#333,color:#fff
+  classDef green fill:#9f6
+`))
+	c.Assert(err, qt.IsNil)
+	c.Assert(g.Direction, qt.Equals, graph.LeftRight)
+
+	node := func(id string) *graph.Node {
+		n := g.Lookup(id)
+		c.Assert(n, qt.Not(qt.IsNil), qt.Commentf(id))
+		return n
+	}
+
+	c.Assert(node("A").Label, qt.Equals, "Hard edge")
+	c.Assert(node("A").Shape, qt.Equals, graph.Rect)
+	c.Assert(node("A").Fill, qt.Equals, "#f9f")
+	c.Assert(node("A").Stroke, qt.Equals, "#333")
+	c.Assert(node("A").FontColor, qt.Equals, "#fff")
+	c.Assert(node("B").Shape, qt.Equals, graph.RoundedRect)
+	c.Assert(node("C").Shape, qt.Equals, graph.Diamond)
+	c.Assert(node("D").Shape, qt.Equals, graph.Stadium)
+	c.Assert(node("E").Shape, qt.Equals, graph.Cylinder)
+	c.Assert(node("F").Shape, qt.Equals, graph.Circle)
+	c.Assert(node("G").Shape, qt.Equals, graph.Hexagon)
+	c.Assert(node("H").Shape, qt.Equals, graph.Flag)
+	c.Assert(node("I").Label, qt.Equals, "Quoted\ntext")
+	c.Assert(node("I").Fill, qt.Equals, "#9f6")
+	c.Assert(node("J").Shape, qt.Equals, graph.Parallelogram)
+	c.Assert(node("K").Label, qt.Equals, "K")
+
+	type edge struct {
+		From, To string
+		Label    string
+		Style    graph.LineStyle
+		Head     graph.Arrow
+		Tail     graph.Arrow
+	}
+	var edges []edge
+	for _, e := range g.Edges() {
+		edges = append(edges, edge{e.From.ID, e.To.ID, e.Label, e.Style, e.Head, e.Tail})
+	}
+	c.Assert(edges, qt.DeepEquals, []edge{
+		{"A", "B", "Link text", graph.Solid, graph.ArrowNormal, graph.ArrowNone},
+		{"B", "C", "", graph.Solid, graph.ArrowNormal, graph.ArrowNone},
+		{"C", "D", "Two", graph.Solid, graph.ArrowNormal, graph.ArrowNone},
+		{"C", "E", "Two", graph.Solid, graph.ArrowNormal, graph.ArrowNone},
+		{"D", "F", "", graph.Dotted, graph.ArrowNormal, graph.ArrowNone},
+		{"F", "G", "", graph.Thick, graph.ArrowNormal, graph.ArrowNone},
+		{"G", "H", "", graph.Solid, graph.ArrowCross, graph.ArrowNone},
+		{"H", "A", "", graph.Solid, graph.ArrowCircle, graph.ArrowNone},
+		{"I", "A", "", graph.Solid, graph.ArrowNormal, graph.ArrowNormal},
+		{"J", "K", "", graph.Solid, graph.ArrowNone, graph.ArrowNone},
+	})
+}
+
+func TestFlowchartErrors(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		src    string
+		expect string
+	}{
+		{"graph XY\nA-->B", "mermaid: line 1: unknown direction"},
+		{"graph TD\nA-->", "mermaid: line 2: expected node id"},
+		{"graph TD\nA[foo-->B", "mermaid: line 2: expected \"]\""},
+		{"graph TD\nA -- foo B", "unterminated link text"},
+	} {
+		_, err := Render(test.src)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(test.src))
+		c.Assert(err.Error(), qt.Contains, test.expect)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := parseSequence(splitLines(`
+sequenceDiagram
+    autonumber
+    participant A as Alice
+    actor J as John
+    A->>+J: Hello John, how are you?
+    loop Healthcheck
+        J->>J: Fight against hypochondria
+    end
+    Note right of J: Rational thoughts<br/>prevail!
+    alt is sick
+        J-->>A: Not so good :(
+    else is well
+        J-->>-A: Feeling fresh
+    end
+    Note over A,J: A typical interaction
+    A-)B: async
+    B--xA: lost
+`))
+	c.Assert(err, qt.IsNil)
+	c.Assert(d.autonumber, qt.IsTrue)
+	c.Assert(d.participants, qt.HasLen, 3)
+	c.Assert(d.participants[0].label, qt.Equals, "Alice")
+	c.Assert(d.participants[1].actor, qt.IsTrue)
+	c.Assert(d.participants[2].id, qt.Equals, "B")
+
+	var kinds []eventKind
+	for _, e := range d.events {
+		kinds = append(kinds, e.kind)
+	}
+	c.Assert(kinds, qt.DeepEquals, []eventKind{
+		eventMessage, eventFrameStart, eventMessage, eventFrameEnd, eventNote,
+		eventFrameStart, eventMessage, eventFrameSection, eventMessage, eventFrameEnd,
+		eventNote, eventMessage, eventMessage,
+	})
+
+	hello := d.events[0]
+	c.Assert(hello.text, qt.Equals, "Hello John, how are you?")
+	c.Assert(hello.arrow, qt.Equals, arrowFilled)
+	c.Assert(hello.activate, qt.IsTrue)
+	c.Assert(d.events[4].text, qt.Equals, "Rational thoughts\nprevail!")
+	c.Assert(d.events[6].text, qt.Equals, "Not so good :(")
+	c.Assert(d.events[6].dashed, qt.IsTrue)
+	c.Assert(d.events[8].deactivate, qt.IsTrue)
+	c.Assert(d.events[10].to.id, qt.Equals, "J")
+	c.Assert(d.events[11].arrow, qt.Equals, arrowOpen)
+	c.Assert(d.events[12].arrow, qt.Equals, arrowCross)
+
+	s := d.render().String()
+	c.Assert(s, qt.Contains, ">Alice</text>")
+	c.Assert(s, qt.Contains, ">[Healthcheck]</text>")
+	c.Assert(strings.Count(s, "class=\"message\""), qt.Equals, 6)
+	c.Assert(strings.Count(s, "class=\"participant\""), qt.Equals, 4)
+}
+
+func TestSequenceErrors(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		src    string
+		expect string
+	}{
+		{"sequenceDiagram\nA->>B: hi\nend", "mermaid: line 3: unexpected end"},
+		{"sequenceDiagram\nloop forever\nA->>B: hi", "missing end"},
+		{"sequenceDiagram\nA says hi", "invalid statement"},
+	} {
+		_, err := Render(test.src)
+		c.Assert(err, qt.Not(qt.IsNil), qt.Commentf(test.src))
+		c.Assert(err.Error(), qt.Contains, test.expect)
+	}
+}
+
+func TestSupported(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(Supported("graph TD\nA-->B"), qt.IsTrue)
+	c.Assert(Supported("---\ntitle: Foo\n---\nflowchart LR\nA-->B"), qt.IsTrue)
+	c.Assert(Supported("%%{init: {'theme':'dark'}}%%\nsequenceDiagram\nA->>B: hi"), qt.IsTrue)
+	c.Assert(Supported("classDiagram\nA <|-- B"), qt.IsFalse)
+	c.Assert(Supported(""), qt.IsFalse)
+
+	_, err := Render("pie\n\"a\": 1")
+	c.Assert(err, qt.ErrorMatches, `mermaid: line 1: unsupported diagram type "pie"`)
+}
+
+// TestRenderGolden compares the rendered SVG of the diagrams in testdata with
+// the golden files next to them.
+func TestRenderGolden(t *testing.T) {
+	c := qt.New(t)
+
+	// Set to true to write the golden files, then check them visually.
+	devMode := false
+
+	files, err := filepath.Glob(filepath.Join("testdata", "*.mmd"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(files, qt.Not(qt.HasLen), 0)
+
+	for _, filename := range files {
+		src, err := ioutil.ReadFile(filename)
+		c.Assert(err, qt.IsNil)
+
+		d, err := Render(string(src))
+		c.Assert(err, qt.IsNil, qt.Commentf(filename))
+		got := d.String()
+
+		// The SVG must be well formed.
+		dec := xml.NewDecoder(strings.NewReader(got))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			c.Assert(err, qt.IsNil, qt.Commentf(filename))
+		}
+
+		goldenFilename := strings.TrimSuffix(filename, ".mmd") + ".svg"
+		if devMode {
+			c.Assert(ioutil.WriteFile(goldenFilename, []byte(got), 0644), qt.IsNil)
+			continue
+		}
+
+		golden, err := ioutil.ReadFile(goldenFilename)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.Equals, string(golden), qt.Commentf(filename))
+	}
+}
@@ -0,0 +1,554 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mermaid
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gohugoio/hugo/tpl/diagrams/internal/svg"
+)
+
+const (
+	seqMargin       = 10.0
+	participantGap  = 40.0
+	participantMinW = 100.0
+	messageSpacing  = 15.0
+	selfLoopWidth   = 30.0
+	selfLoopHeight  = 20.0
+	noteMinWidth    = 80.0
+	framePadding    = 10.0
+	frameHeader     = 25.0
+	activationWidth = 10.0
+)
+
+type eventKind int
+
+const (
+	eventMessage eventKind = iota
+	eventNote
+	eventFrameStart
+	eventFrameSection
+	eventFrameEnd
+	eventActivate
+	eventDeactivate
+)
+
+type participant struct {
+	id    string
+	label string
+	actor bool
+	index int
+
+	x, w float64
+}
+
+type arrowKind int
+
+const (
+	arrowNone arrowKind = iota
+	arrowFilled
+	arrowOpen
+	arrowCross
+)
+
+type event struct {
+	kind eventKind
+
+	// Message and note fields.
+	from, to *participant
+	text     string
+	dashed   bool
+	arrow    arrowKind
+	activate bool
+	// deactivate deactivates the sender of the message.
+	deactivate bool
+
+	// Note placement: left of, right of or over.
+	placement string
+
+	// Frame fields, e.g. loop, alt or rect.
+	frame string
+}
+
+type sequenceDiagram struct {
+	participants []*participant
+	byID         map[string]*participant
+	events       []event
+	autonumber   bool
+}
+
+var (
+	participantRe = regexp.MustCompile(`^(participant|actor)\s+(.+?)(?:\s+as\s+(.+))?$`)
+	messageRe     = regexp.MustCompile(`^(.+?)\s*(-->>|->>|-->|->|--x|-x|--\)|-\))\s*([+-]?)\s*(.+?)\s*(?::(.*))?$`)
+	noteRe        = regexp.MustCompile(`(?i)^note\s+(left of|right of|over)\s+([^:]+?)\s*:(.*)$`)
+	frameRe       = regexp.MustCompile(`^(loop|alt|else|opt|par|and|critical|option|break|rect)\b\s*(.*)$`)
+)
+
+var messageArrows = map[string]struct {
+	dashed bool
+	arrow  arrowKind
+}{
+	"->":   {false, arrowNone},
+	"-->":  {true, arrowNone},
+	"->>":  {false, arrowFilled},
+	"-->>": {true, arrowFilled},
+	"-x":   {false, arrowCross},
+	"--x":  {true, arrowCross},
+	"-)":   {false, arrowOpen},
+	"--)":  {true, arrowOpen},
+}
+
+func parseSequence(lines []line) (*sequenceDiagram, error) {
+	d := &sequenceDiagram{byID: make(map[string]*participant)}
+	depth := 0
+
+	for _, l := range lines[1:] {
+		s := l.text
+		fields := strings.Fields(s)
+
+		switch fields[0] {
+		case "autonumber":
+			d.autonumber = true
+			continue
+		case "title", "links", "link", "properties", "details", "box":
+			continue
+		case "activate", "deactivate":
+			if len(fields) != 2 {
+				return nil, l.errorf("invalid %s statement", fields[0])
+			}
+			kind := eventActivate
+			if fields[0] == "deactivate" {
+				kind = eventDeactivate
+			}
+			d.events = append(d.events, event{kind: kind, from: d.participant(fields[1])})
+			continue
+		case "end":
+			if depth == 0 {
+				return nil, l.errorf("unexpected end")
+			}
+			depth--
+			d.events = append(d.events, event{kind: eventFrameEnd})
+			continue
+		}
+		if strings.HasPrefix(fields[0], "accTitle") || strings.HasPrefix(fields[0], "accDescr") {
+			continue
+		}
+
+		if m := participantRe.FindStringSubmatch(s); m != nil {
+			p := d.participant(m[2])
+			p.actor = m[1] == "actor"
+			if m[3] != "" {
+				p.label = cleanText(m[3])
+			}
+			continue
+		}
+
+		if m := noteRe.FindStringSubmatch(s); m != nil {
+			ids := strings.Split(m[2], ",")
+			e := event{kind: eventNote, placement: strings.ToLower(m[1]), text: cleanText(m[3])}
+			e.from = d.participant(strings.TrimSpace(ids[0]))
+			e.to = e.from
+			if len(ids) > 1 {
+				e.to = d.participant(strings.TrimSpace(ids[1]))
+			}
+			d.events = append(d.events, e)
+			continue
+		}
+
+		if m := frameRe.FindStringSubmatch(s); m != nil {
+			e := event{frame: m[1], text: cleanText(m[2])}
+			switch m[1] {
+			case "else", "and", "option":
+				if depth == 0 {
+					return nil, l.errorf("unexpected %s", m[1])
+				}
+				e.kind = eventFrameSection
+			default:
+				depth++
+				e.kind = eventFrameStart
+			}
+			d.events = append(d.events, e)
+			continue
+		}
+
+		if m := messageRe.FindStringSubmatch(s); m != nil {
+			a := messageArrows[m[2]]
+			e := event{
+				kind:   eventMessage,
+				from:   d.participant(m[1]),
+				to:     d.participant(m[4]),
+				text:   cleanText(m[5]),
+				dashed: a.dashed,
+				arrow:  a.arrow,
+			}
+			e.activate = m[3] == "+"
+			e.deactivate = m[3] == "-"
+			d.events = append(d.events, e)
+			continue
+		}
+
+		return nil, l.errorf("invalid statement %q", s)
+	}
+
+	if depth > 0 {
+		return nil, lines[len(lines)-1].errorf("missing end")
+	}
+
+	return d, nil
+}
+
+// participant returns the participant with the given id, creating it if not found.
+func (d *sequenceDiagram) participant(id string) *participant {
+	id = strings.TrimSpace(id)
+	if p, found := d.byID[id]; found {
+		return p
+	}
+	p := &participant{id: id, label: id, index: len(d.participants)}
+	d.participants = append(d.participants, p)
+	d.byID[id] = p
+	return p
+}
+
+// layoutParticipants sets the participant widths and positions, making room
+// for the messages and notes between them.
+func (d *sequenceDiagram) layoutParticipants() {
+	n := len(d.participants)
+	for _, p := range d.participants {
+		p.w = math.Max(svg.TextWidth(p.label)+30, participantMinW)
+	}
+
+	// gaps[i] is the distance between participant i and i+1.
+	gaps := make([]float64, n)
+	for i := 0; i < n-1; i++ {
+		gaps[i] = d.participants[i].w/2 + d.participants[i+1].w/2 + participantGap
+	}
+	require := func(i, j int, width float64) {
+		if i > j {
+			i, j = j, i
+		}
+		var have float64
+		for k := i; k < j; k++ {
+			have += gaps[k]
+		}
+		if have < width {
+			for k := i; k < j; k++ {
+				gaps[k] += (width - have) / float64(j-i)
+			}
+		}
+	}
+
+	for _, e := range d.events {
+		switch e.kind {
+		case eventMessage:
+			w := svg.TextWidth(e.text) + 30
+			if e.from == e.to {
+				w = math.Max(w, selfLoopWidth) + 10
+				if i := e.from.index; i < n-1 {
+					require(i, i+1, w+d.participants[i+1].w/2)
+				}
+				continue
+			}
+			require(e.from.index, e.to.index, w)
+		case eventNote:
+			w := noteWidth(e.text) + 20
+			switch e.placement {
+			case "left of":
+				if i := e.from.index; i > 0 {
+					require(i-1, i, w+d.participants[i-1].w/2)
+				}
+			case "right of":
+				if i := e.from.index; i < n-1 {
+					require(i, i+1, w+d.participants[i+1].w/2)
+				}
+			}
+		}
+	}
+
+	var x float64
+	for i, p := range d.participants {
+		p.x = x
+		x += gaps[i]
+	}
+}
+
+func noteWidth(s string) float64 {
+	return math.Max(svg.TextWidth(s)+20, noteMinWidth)
+}
+
+type frame struct {
+	kind, label string
+	y0          float64
+	sections    []frameSection
+	minX, maxX  float64
+	hasExtent   bool
+}
+
+type frameSection struct {
+	y     float64
+	label string
+}
+
+func (f *frame) extend(x0, x1 float64) {
+	if !f.hasExtent {
+		f.minX, f.maxX, f.hasExtent = x0, x1, true
+		return
+	}
+	f.minX, f.maxX = math.Min(f.minX, x0), math.Max(f.maxX, x1)
+}
+
+func (d *sequenceDiagram) render() svg.Diagram {
+	if len(d.participants) == 0 {
+		return svg.Diagram{}
+	}
+
+	d.layoutParticipants()
+
+	boxH := 40.0
+	for _, p := range d.participants {
+		h := svg.TextHeight(p.label) + 20
+		if p.actor {
+			// Make room for the stick figure.
+			h += 45
+		}
+		boxH = math.Max(boxH, h)
+	}
+
+	var (
+		background, activations, foreground svg.Builder
+
+		frames      []*frame
+		active      = make(map[*participant][]float64)
+		number      int
+		minX        = math.MaxFloat64
+		maxX        = -math.MaxFloat64
+		y           = seqMargin + boxH + 20
+		stroke      = svg.Stroke
+		noteFill    = "#fff5ad"
+		noteStroke  = "#aaaa33"
+		frameStroke = "#666"
+	)
+
+	extend := func(x0, x1 float64) {
+		minX, maxX = math.Min(minX, x0), math.Max(maxX, x1)
+		if len(frames) > 0 {
+			frames[len(frames)-1].extend(x0, x1)
+		}
+	}
+
+	activate := func(p *participant, y float64) {
+		active[p] = append(active[p], y)
+	}
+	deactivate := func(p *participant, y float64) {
+		stack := active[p]
+		if len(stack) == 0 {
+			return
+		}
+		y0 := stack[len(stack)-1]
+		active[p] = stack[:len(stack)-1]
+		x := p.x - activationWidth/2 + float64(len(stack)-1)*activationWidth/2
+		activations.Element("rect", "x", x, "y", y0, "width", activationWidth, "height", y-y0, "fill", "#f4f4f4", "stroke", stroke)
+	}
+
+	for _, e := range d.events {
+		switch e.kind {
+		case eventMessage:
+			if e.text != "" {
+				h := svg.TextHeight(e.text)
+				if e.from == e.to {
+					foreground.TextAnchor(e.from.x+10, y+h/2, e.text, "start", "", "class", "message-text")
+					extend(e.from.x, e.from.x+svg.TextWidth(e.text)+10)
+				} else {
+					foreground.Text((e.from.x+e.to.x)/2, y+h/2, e.text, "", "class", "message-text")
+				}
+				y += h + 5
+			}
+
+			var points []svg.Point
+			if e.from == e.to {
+				x := e.from.x
+				points = []svg.Point{{X: x, Y: y}, {X: x + selfLoopWidth, Y: y}, {X: x + selfLoopWidth, Y: y + selfLoopHeight}, {X: x, Y: y + selfLoopHeight}}
+				extend(x, x+selfLoopWidth)
+			} else {
+				points = []svg.Point{{X: e.from.x, Y: y}, {X: e.to.x, Y: y}}
+				extend(math.Min(e.from.x, e.to.x), math.Max(e.from.x, e.to.x))
+			}
+
+			attrs := []interface{}{"class", "message", "stroke-width", 1.5}
+			if e.dashed {
+				attrs = append(attrs, "stroke-dasharray", "4 3")
+			}
+			foreground.Polyline(points, stroke, attrs...)
+			from, to := points[len(points)-2], points[len(points)-1]
+			switch e.arrow {
+			case arrowFilled:
+				foreground.ArrowHead(from, to, stroke)
+			case arrowOpen:
+				foreground.OpenArrowHead(from, to, stroke)
+			case arrowCross:
+				foreground.Cross(svg.Point{X: to.X - math.Copysign(6, to.X-from.X), Y: to.Y}, stroke)
+			}
+
+			if d.autonumber {
+				number++
+				foreground.Element("circle", "cx", points[0].X, "cy", points[0].Y, "r", 8, "fill", stroke, "stroke", stroke)
+				foreground.Text(points[0].X, points[0].Y, strconv.Itoa(number), svg.Fill, "font-size", 10)
+			}
+
+			endY := points[len(points)-1].Y
+			if e.activate {
+				activate(e.to, endY)
+			}
+			if e.deactivate {
+				deactivate(e.from, endY)
+			}
+			y = endY + messageSpacing
+
+		case eventNote:
+			w := noteWidth(e.text)
+			h := svg.TextHeight(e.text) + 14
+			var x0 float64
+			switch e.placement {
+			case "left of":
+				x0 = e.from.x - 10 - w
+			case "right of":
+				x0 = e.from.x + 10
+			default:
+				a, b := math.Min(e.from.x, e.to.x), math.Max(e.from.x, e.to.x)
+				if a != b {
+					w = math.Max(w, b-a+40)
+				}
+				x0 = (a+b)/2 - w/2
+			}
+			foreground.Element("rect", "x", x0, "y", y, "width", w, "height", h, "fill", noteFill, "stroke", noteStroke, "class", "note")
+			foreground.Text(x0+w/2, y+h/2, e.text, "", "class", "note-text")
+			extend(x0, x0+w)
+			y += h + messageSpacing
+
+		case eventActivate:
+			activate(e.from, y)
+
+		case eventDeactivate:
+			deactivate(e.from, y)
+
+		case eventFrameStart:
+			frames = append(frames, &frame{kind: e.frame, label: e.text, y0: y})
+			if e.frame != "rect" {
+				y += frameHeader
+			} else {
+				y += framePadding
+			}
+
+		case eventFrameSection:
+			f := frames[len(frames)-1]
+			f.sections = append(f.sections, frameSection{y: y, label: e.text})
+			y += frameHeader
+
+		case eventFrameEnd:
+			f := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			if !f.hasExtent {
+				first, last := d.participants[0], d.participants[len(d.participants)-1]
+				f.extend(first.x, last.x)
+			}
+			x0, x1 := f.minX-framePadding, f.maxX+framePadding
+			if f.kind == "rect" {
+				y += framePadding
+				color := f.label
+				if color == "" {
+					color = "rgba(0,0,0,0.05)"
+				}
+				background.Element("rect", "x", x0, "y", f.y0, "width", x1-x0, "height", y-f.y0, "fill", color, "class", "rect")
+			} else {
+				tagW := svg.TextWidth(f.kind) + 16
+				if f.label != "" {
+					x1 = math.Max(x1, x0+tagW+svg.TextWidth("["+f.label+"]")+20)
+				}
+				y += 5
+				foreground.Element("rect", "x", x0, "y", f.y0, "width", x1-x0, "height", y-f.y0, "fill", "none", "stroke", frameStroke, "class", "frame")
+				foreground.Polygon([]svg.Point{
+					{X: x0, Y: f.y0}, {X: x0 + tagW, Y: f.y0}, {X: x0 + tagW, Y: f.y0 + 12},
+					{X: x0 + tagW - 6, Y: f.y0 + 18}, {X: x0, Y: f.y0 + 18},
+				}, "#eee", frameStroke)
+				foreground.Text(x0+tagW/2, f.y0+9, f.kind, "", "font-weight", "bold", "font-size", 12)
+				if f.label != "" {
+					foreground.Text((x0+tagW+x1)/2, f.y0+12, "["+f.label+"]", "", "class", "frame-text")
+				}
+				for _, s := range f.sections {
+					foreground.Element("line", "x1", x0, "y1", s.y, "x2", x1, "y2", s.y, "stroke", frameStroke, "stroke-dasharray", "4 3")
+					if s.label != "" {
+						foreground.Text((x0+x1)/2, s.y+12, "["+s.label+"]", "", "class", "frame-text")
+					}
+				}
+			}
+			extend(x0, x1)
+			y += framePadding
+		}
+	}
+
+	y += 10
+	for _, p := range d.participants {
+		for len(active[p]) > 0 {
+			deactivate(p, y)
+		}
+	}
+
+	var b svg.Builder
+	for _, p := range d.participants {
+		extend(p.x-p.w/2, p.x+p.w/2)
+		b.Element("line", "x1", p.x, "y1", seqMargin+boxH, "x2", p.x, "y2", y, "stroke", "#999", "stroke-width", 1, "class", "lifeline")
+	}
+	b.Raw(background.String())
+	b.Raw(activations.String())
+	for _, p := range d.participants {
+		renderParticipant(&b, p, seqMargin, boxH)
+		renderParticipant(&b, p, y, boxH)
+	}
+	b.Raw(foreground.String())
+
+	dx := seqMargin - minX
+	height := y + boxH + seqMargin
+
+	return svg.Diagram{
+		Inner:  fmt.Sprintf(`<g transform="translate(%s,0)">%s</g>`, svg.FormatFloat(dx), b.String()),
+		Width:  int(math.Ceil(maxX - minX + 2*seqMargin)),
+		Height: int(math.Ceil(height)),
+	}
+}
+
+func renderParticipant(b *svg.Builder, p *participant, y, h float64) {
+	if p.actor {
+		const headR = 7.0
+		top := y + 2
+		cx := p.x
+		labelH := svg.TextHeight(p.label)
+		figureBottom := y + h - labelH - 2
+		neckY := top + 2*headR
+		hipY := neckY + (figureBottom-neckY)*0.55
+		b.Open("g", "class", "actor", "fill", "none", "stroke", svg.Stroke, "stroke-width", 1.5)
+		b.Element("circle", "cx", cx, "cy", top+headR, "r", headR, "fill", svg.Fill)
+		b.Element("line", "x1", cx, "y1", neckY, "x2", cx, "y2", hipY)
+		b.Element("line", "x1", cx-10, "y1", neckY+5, "x2", cx+10, "y2", neckY+5)
+		b.Element("line", "x1", cx, "y1", hipY, "x2", cx-8, "y2", figureBottom)
+		b.Element("line", "x1", cx, "y1", hipY, "x2", cx+8, "y2", figureBottom)
+		b.Close("g")
+		b.Text(cx, y+h-labelH/2, p.label, "", "class", "actor-text")
+		return
+	}
+	b.Element("rect", "x", p.x-p.w/2, "y", y, "width", p.w, "height", h, "rx", 3, "fill", "#eaeaff", "stroke", "#666", "class", "participant")
+	b.Text(p.x, y+h/2, p.label, "", "class", "participant-text")
+}
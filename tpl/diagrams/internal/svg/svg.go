@@ -0,0 +1,281 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package svg provides a minimal SVG writer and text metrics shared by the
+// diagram engines.
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// FontSize is the font size in pixels used for all diagram text.
+	FontSize = 14
+	// LineHeight is the height in pixels of a line of text.
+	LineHeight = 18
+	// FontFamily is the font family used for all diagram text.
+	FontFamily = "sans-serif"
+
+	// Default colors.
+	Stroke    = "#333"
+	Fill      = "#fff"
+	TextColor = "#333"
+)
+
+// Diagram is a rendered diagram.
+type Diagram struct {
+	// Inner is the SVG markup without the <svg> container.
+	Inner  string
+	Width  int
+	Height int
+}
+
+// String returns the diagram wrapped in an <svg> container.
+func (d Diagram) String() string {
+	return fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" font-family="%s" font-size="%d">%s</svg>`,
+		d.Width, d.Height, d.Width, d.Height, FontFamily, FontSize, d.Inner,
+	)
+}
+
+// TextWidth returns the estimated rendered width in pixels of the widest line in s.
+func TextWidth(s string) float64 {
+	var max float64
+	for _, line := range Lines(s) {
+		var w float64
+		for _, r := range line {
+			w += runeWidth(r)
+		}
+		if w > max {
+			max = w
+		}
+	}
+	return max
+}
+
+// TextHeight returns the height in pixels of the lines in s.
+func TextHeight(s string) float64 {
+	return float64(len(Lines(s)) * LineHeight)
+}
+
+// Lines splits s into lines.
+func Lines(s string) []string {
+	return strings.Split(s, "\n")
+}
+
+// runeWidth returns an estimate of the width of r, relative to FontSize.
+func runeWidth(r rune) float64 {
+	var f float64
+	switch {
+	case r == ' ' || r == 'i' || r == 'l' || r == 'j' || r == '.' || r == ',' || r == '\'' || r == '|' || r == '!' || r == ':' || r == ';':
+		f = 0.3
+	case r == 'm' || r == 'w' || r == 'M' || r == 'W':
+		f = 0.85
+	case r >= 'A' && r <= 'Z':
+		f = 0.68
+	case r < utf8.RuneSelf:
+		f = 0.55
+	case isWide(r):
+		f = 1
+	default:
+		f = 0.6
+	}
+	return f * FontSize
+}
+
+// isWide reports whether r is a wide East Asian character.
+func isWide(r rune) bool {
+	return (r >= 0x1100 && r <= 0x115f) ||
+		(r >= 0x2e80 && r <= 0xa4cf) ||
+		(r >= 0xac00 && r <= 0xd7a3) ||
+		(r >= 0xf900 && r <= 0xfaff) ||
+		(r >= 0xfe30 && r <= 0xfe4f) ||
+		(r >= 0xff00 && r <= 0xff60)
+}
+
+// Builder builds the inner markup of an SVG.
+type Builder struct {
+	b strings.Builder
+}
+
+// String returns the markup written so far.
+func (b *Builder) String() string {
+	return b.b.String()
+}
+
+// Raw writes s unescaped.
+func (b *Builder) Raw(s string) {
+	b.b.WriteString(s)
+}
+
+// Element writes an empty element with the given attributes,
+// given as name-value pairs.
+func (b *Builder) Element(name string, attrs ...interface{}) {
+	b.b.WriteString("<")
+	b.b.WriteString(name)
+	b.attrs(attrs)
+	b.b.WriteString("/>")
+}
+
+// Open writes a start tag with the given attributes.
+func (b *Builder) Open(name string, attrs ...interface{}) {
+	b.b.WriteString("<")
+	b.b.WriteString(name)
+	b.attrs(attrs)
+	b.b.WriteString(">")
+}
+
+// Close writes an end tag.
+func (b *Builder) Close(name string) {
+	b.b.WriteString("</")
+	b.b.WriteString(name)
+	b.b.WriteString(">")
+}
+
+func (b *Builder) attrs(attrs []interface{}) {
+	for i := 0; i+1 < len(attrs); i += 2 {
+		v := attrs[i+1]
+		if s, ok := v.(string); ok && s == "" {
+			continue
+		}
+		fmt.Fprintf(&b.b, ` %s="%s"`, attrs[i], Escape(formatValue(v)))
+	}
+}
+
+// Text writes the possibly multi-line text s centered at x, y.
+func (b *Builder) Text(x, y float64, s, color string, attrs ...interface{}) {
+	b.TextAnchor(x, y, s, "middle", color, attrs...)
+}
+
+// TextAnchor writes the possibly multi-line text s vertically centered at y,
+// horizontally positioned at x according to anchor (start, middle or end).
+func (b *Builder) TextAnchor(x, y float64, s, anchor, color string, attrs ...interface{}) {
+	if color == "" {
+		color = TextColor
+	}
+	lines := Lines(s)
+	// Center the lines vertically around y.
+	y0 := y - float64(len(lines)-1)*LineHeight/2
+	for i, line := range lines {
+		a := append([]interface{}{"x", x, "y", y0 + float64(i)*LineHeight, "text-anchor", anchor, "dominant-baseline", "central", "fill", color}, attrs...)
+		b.Open("text", a...)
+		b.b.WriteString(Escape(line))
+		b.Close("text")
+	}
+}
+
+// Polyline writes a line through the given points.
+func (b *Builder) Polyline(points []Point, stroke string, attrs ...interface{}) {
+	var sb strings.Builder
+	for i, p := range points {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(FormatFloat(p.X))
+		sb.WriteString(",")
+		sb.WriteString(FormatFloat(p.Y))
+	}
+	a := append([]interface{}{"points", sb.String(), "fill", "none", "stroke", stroke}, attrs...)
+	b.Element("polyline", a...)
+}
+
+// Polygon writes a closed polygon through the given points.
+func (b *Builder) Polygon(points []Point, fill, stroke string, attrs ...interface{}) {
+	var sb strings.Builder
+	for i, p := range points {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(FormatFloat(p.X))
+		sb.WriteString(",")
+		sb.WriteString(FormatFloat(p.Y))
+	}
+	a := append([]interface{}{"points", sb.String(), "fill", fill, "stroke", stroke}, attrs...)
+	b.Element("polygon", a...)
+}
+
+// ArrowHead writes a filled arrow head pointing at to, coming from from.
+func (b *Builder) ArrowHead(from, to Point, color string) {
+	const length, width = 10.0, 4.0
+	dx, dy := to.X-from.X, to.Y-from.Y
+	d := math.Hypot(dx, dy)
+	if d == 0 {
+		return
+	}
+	ux, uy := dx/d, dy/d
+	base := Point{to.X - ux*length, to.Y - uy*length}
+	b.Polygon([]Point{
+		to,
+		{base.X - uy*width, base.Y + ux*width},
+		{base.X + uy*width, base.Y - ux*width},
+	}, color, color)
+}
+
+// OpenArrowHead writes an open (line only) arrow head pointing at to, coming from from.
+func (b *Builder) OpenArrowHead(from, to Point, color string) {
+	const length, width = 10.0, 5.0
+	dx, dy := to.X-from.X, to.Y-from.Y
+	d := math.Hypot(dx, dy)
+	if d == 0 {
+		return
+	}
+	ux, uy := dx/d, dy/d
+	base := Point{to.X - ux*length, to.Y - uy*length}
+	b.Polyline([]Point{
+		{base.X - uy*width, base.Y + ux*width},
+		to,
+		{base.X + uy*width, base.Y - ux*width},
+	}, color, "stroke-width", 1.5)
+}
+
+// Cross writes a cross centered at p.
+func (b *Builder) Cross(p Point, color string) {
+	const s = 5.0
+	b.Element("line", "x1", p.X-s, "y1", p.Y-s, "x2", p.X+s, "y2", p.Y+s, "stroke", color, "stroke-width", 2)
+	b.Element("line", "x1", p.X-s, "y1", p.Y+s, "x2", p.X+s, "y2", p.Y-s, "stroke", color, "stroke-width", 2)
+}
+
+// Point is a point in the diagram.
+type Point struct {
+	X, Y float64
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;")
+
+// Escape escapes s for use in SVG text and attribute values.
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatValue(v interface{}) string {
+	switch vv := v.(type) {
+	case float64:
+		return FormatFloat(vv)
+	case int:
+		return strconv.Itoa(vv)
+	case string:
+		return vv
+	default:
+		return fmt.Sprint(vv)
+	}
+}
+
+// FormatFloat formats f with at most two decimals.
+func FormatFloat(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
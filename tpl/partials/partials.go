@@ -30,6 +30,7 @@ import (
 	texttemplate "github.com/gohugoio/hugo/tpl/internal/go_templates/texttemplate"
 
 	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/identity"
 
 	"github.com/gohugoio/hugo/tpl"
 
@@ -57,17 +58,25 @@ func (k partialCacheKey) templateName() string {
 type partialCache struct {
 	sync.RWMutex
 	p map[partialCacheKey]interface{}
+
+	// The templates and pages used to create the cached partials, when
+	// these are tracked.
+	dependencies map[partialCacheKey]identity.Manager
 }
 
 func (p *partialCache) clear() {
 	p.Lock()
 	defer p.Unlock()
 	p.p = make(map[partialCacheKey]interface{})
+	p.dependencies = make(map[partialCacheKey]identity.Manager)
 }
 
 // New returns a new instance of the templates-namespaced template functions.
 func New(deps *deps.Deps) *Namespace {
-	cache := &partialCache{p: make(map[partialCacheKey]interface{})}
+	cache := &partialCache{
+		p:            make(map[partialCacheKey]interface{}),
+		dependencies: make(map[partialCacheKey]identity.Manager),
+	}
 	deps.BuildStartListeners.Add(
 		func() {
 			cache.clear()
@@ -235,9 +244,21 @@ func (ns *Namespace) getOrCreate(ctx context.Context, key partialCacheKey, conte
 
 	ns.cachedPartials.RLock()
 	p, ok := ns.cachedPartials.p[key]
+	dependencies := ns.cachedPartials.dependencies[key]
 	ns.cachedPartials.RUnlock()
 
+	// The cached result depends on what was used to create it.
+	dependencyManager := tpl.GetDependencyManagerFromContext(ctx)
+	addDependencies := func(m identity.Manager) {
+		if dependencyManager != nil && m != nil {
+			for _, id := range m.GetIdentities() {
+				dependencyManager.Add(id)
+			}
+		}
+	}
+
 	if ok {
+		addDependencies(dependencies)
 		if ns.deps.Metrics != nil {
 			ns.deps.Metrics.TrackValue(key.templateName(), p, true)
 			// The templates that gets executed is measured in Execute.
@@ -249,12 +270,18 @@ func (ns *Namespace) getOrCreate(ctx context.Context, key partialCacheKey, conte
 		return p, nil
 	}
 
+	if dependencyManager != nil {
+		dependencies = identity.NewManager(identity.KeyValueIdentity{Key: "partialCached", Value: key.templateName()})
+		ctx = tpl.SetDependencyManagerInContext(ctx, dependencies)
+	}
+
 	// This needs to be done outside the lock.
 	// See #9588
 	_, p, err = ns.include(ctx, key.name, context)
 	if err != nil {
 		return nil, err
 	}
+	addDependencies(dependencies)
 
 	ns.cachedPartials.Lock()
 	defer ns.cachedPartials.Unlock()
@@ -272,6 +299,9 @@ func (ns *Namespace) getOrCreate(ctx context.Context, key partialCacheKey, conte
 	}
 
 	ns.cachedPartials.p[key] = p
+	if dependencies != nil {
+		ns.cachedPartials.dependencies[key] = dependencies
+	}
 
 	return p, nil
 }
@@ -19,6 +19,7 @@ import (
 	"reflect"
 	"regexp"
 
+	"github.com/gohugoio/hugo/identity"
 	"github.com/gohugoio/hugo/output"
 
 	texttemplate "github.com/gohugoio/hugo/tpl/internal/go_templates/texttemplate"
@@ -163,3 +164,23 @@ func GetHasLockFromContext(ctx context.Context) bool {
 func SetHasLockInContext(ctx context.Context, hasLock bool) context.Context {
 	return context.WithValue(ctx, texttemplate.HasLockContextKey, hasLock)
 }
+
+type dependencyManagerContextKeyType string
+
+const dependencyManagerContextKey = dependencyManagerContextKeyType("dependencyManager")
+
+// GetDependencyManagerFromContext returns the identity.Manager that records
+// the templates and pages used in the current template execution, nil if
+// these are not tracked.
+func GetDependencyManagerFromContext(ctx context.Context) identity.Manager {
+	if v := ctx.Value(dependencyManagerContextKey); v != nil {
+		return v.(identity.Manager)
+	}
+	return nil
+}
+
+// SetDependencyManagerInContext sets the identity.Manager that records the
+// templates and pages used when executing templates with ctx.
+func SetDependencyManagerInContext(ctx context.Context, m identity.Manager) context.Context {
+	return context.WithValue(ctx, dependencyManagerContextKey, m)
+}
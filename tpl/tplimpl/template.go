@@ -254,6 +254,14 @@ func (t *templateExec) ExecuteWithContext(ctx context.Context, templ tpl.Templat
 		}
 	}
 
+	if m := tpl.GetDependencyManagerFromContext(ctx); m != nil {
+		if ts, ok := templ.(*templateState); ok {
+			// This also adds the base template and the partials found
+			// when parsing the template.
+			m.Add(ts)
+		}
+	}
+
 	execErr := t.executor.ExecuteWithContext(ctx, templ, wr, data)
 	if execErr != nil {
 		execErr = t.addFileContext(templ, execErr)
@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/gohugoio/hugo/identity"
 	"github.com/gohugoio/hugo/tpl"
 
 	"github.com/gohugoio/hugo/common/maps"
@@ -129,6 +130,16 @@ func (t *templateExecHelper) GetMethod(ctx context.Context, tmpl texttemplate.Pr
 		return zero, zero
 	}
 
+	if k := receiver.Kind(); (k == reflect.Ptr || k == reflect.Interface) && !receiver.IsNil() && receiver.CanInterface() {
+		// Record the pages used, e.g. to know what to render again in an
+		// incremental build.
+		if id, ok := receiver.Interface().(identity.Provider); ok {
+			if m := tpl.GetDependencyManagerFromContext(ctx); m != nil {
+				m.Add(id)
+			}
+		}
+	}
+
 	if fn.Type().NumIn() > 0 {
 		first := fn.Type().In(0)
 		if first.Implements(contextInterface) {
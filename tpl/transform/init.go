@@ -102,6 +102,14 @@ func init() {
 			},
 		)
 
+		ns.AddMethodMapping(ctx.ToMath,
+			nil,
+			[][2]string{
+				{`{{ transform.ToMath "x^2" }}`, `<math xmlns="http://www.w3.org/1998/Math/MathML"><semantics><msup><mi>x</mi><mn>2</mn></msup><annotation encoding="application/x-tex">x^2</annotation></semantics></math>`},
+				{`{{ transform.ToMath "\\frac{a}{b}" (dict "output" "html" "displayMode" true) }}`, `<span style="display:block;padding:0 0.1em;border-bottom:1px solid"><span style="font-style:italic">a</span></span>`},
+			},
+		)
+
 		ns.AddMethodMapping(ctx.Unmarshal,
 			[]string{"unmarshal"},
 			[][2]string{
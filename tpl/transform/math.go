@@ -0,0 +1,120 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+
+	"github.com/gohugoio/hugo/helpers"
+	"github.com/gohugoio/hugo/markup/mathml"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// mathOptions holds the options for ToMath.
+type mathOptions struct {
+	// Render in display (block) mode.
+	DisplayMode bool
+
+	// The output format, "mathml" or "html".
+	Output string
+
+	// Whether to return an error for invalid expressions. If false, the
+	// expression is rendered as-is in ErrorColor.
+	ThrowOnError bool
+
+	// The color used to render invalid expressions when ThrowOnError is false.
+	ErrorColor string
+}
+
+var defaultMathOptions = mathOptions{
+	Output:       "mathml",
+	ThrowOnError: true,
+	ErrorColor:   "#cc0000",
+}
+
+func decodeMathOptions(opts ...interface{}) (mathOptions, error) {
+	o := defaultMathOptions
+	if len(opts) == 0 || opts[0] == nil {
+		return o, nil
+	}
+	if len(opts) > 1 {
+		return o, errors.New("too many arguments")
+	}
+
+	m, err := cast.ToStringMapE(opts[0])
+	if err != nil {
+		return o, errors.New("options must be a map")
+	}
+
+	if err := mapstructure.WeakDecode(m, &o); err != nil {
+		return o, err
+	}
+
+	o.Output = strings.ToLower(o.Output)
+	if o.Output != "mathml" && o.Output != "html" {
+		return o, errors.Errorf("unsupported output %q, must be one of mathml or html", o.Output)
+	}
+
+	return o, nil
+}
+
+// mathConverterVersionNumber is part of the cache key.
+const mathConverterVersionNumber = 1 // Increment to invalidate the cache when markup/mathml changes
+
+// ToMath converts a LaTeX math expression to MathML or static HTML at build time.
+// You can optionally provide an options map with the keys displayMode,
+// output, throwOnError and errorColor.
+// The result is cached in the file cache.
+func (ns *Namespace) ToMath(expr interface{}, opts ...interface{}) (template.HTML, error) {
+	s, err := cast.ToStringE(expr)
+	if err != nil {
+		return "", err
+	}
+
+	o, err := decodeMathOptions(opts...)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to decode options")
+	}
+
+	key := helpers.MD5String(fmt.Sprintf("tomath_%d_%s_%+v", mathConverterVersionNumber, s, o))
+
+	convert := mathml.Convert
+	if o.Output == "html" {
+		convert = mathml.ConvertHTML
+	}
+
+	_, b, err := ns.deps.FileCaches.MiscCache().GetOrCreateBytes(key, func() ([]byte, error) {
+		result, err := convert(s, mathml.Options{DisplayMode: o.DisplayMode})
+		if err != nil {
+			if o.ThrowOnError {
+				return nil, err
+			}
+			result = fmt.Sprintf(
+				`<span class="math-error" title="%s" style="color:%s">%s</span>`,
+				html.EscapeString(err.Error()), html.EscapeString(o.ErrorColor), html.EscapeString(s),
+			)
+		}
+		return []byte(result), nil
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to convert %q to %s", s, o.Output)
+	}
+
+	return template.HTML(b), nil
+}
@@ -98,6 +98,47 @@ func TestHighlight(t *testing.T) {
 	}
 }
 
+func TestToMath(t *testing.T) {
+	t.Parallel()
+	b := hugolib.NewIntegrationTestBuilder(
+		hugolib.IntegrationTestConfig{T: t},
+	).Build()
+
+	ns := transform.New(b.H.Deps)
+
+	for _, test := range []struct {
+		s      interface{}
+		opts   interface{}
+		expect interface{}
+	}{
+		{`x^2`, nil, `<math xmlns="http://www.w3.org/1998/Math/MathML"><semantics><msup><mi>x</mi><mn>2</mn></msup>`},
+		{`\frac{a}{b}`, map[string]interface{}{"displayMode": true}, `display="block"><semantics><mfrac><mi>a</mi><mi>b</mi></mfrac>`},
+		{`x^2`, map[string]interface{}{"output": "html"}, `<span class="math" style="white-space:nowrap;`},
+		{`\frac{a}{b}`, map[string]interface{}{"output": "HTML", "displayMode": true}, `<span class="math math-display"`},
+		{`\foo`, nil, false},
+		{`\foo`, map[string]interface{}{"throwOnError": false}, `<span class="math-error" title="undefined control sequence \foo at position 0" style="color:#cc0000">\foo</span>`},
+		{`x`, map[string]interface{}{"output": "svg"}, false},
+		{tstNoStringer{}, nil, false},
+	} {
+
+		var result template.HTML
+		var err error
+		if test.opts == nil {
+			result, err = ns.ToMath(test.s)
+		} else {
+			result, err = ns.ToMath(test.s, test.opts)
+		}
+
+		if bb, ok := test.expect.(bool); ok && !bb {
+			b.Assert(err, qt.Not(qt.IsNil))
+			continue
+		}
+
+		b.Assert(err, qt.IsNil)
+		b.Assert(string(result), qt.Contains, test.expect.(string))
+	}
+}
+
 func TestCanHighlight(t *testing.T) {
 	t.Parallel()
 